@@ -0,0 +1,47 @@
+// Command migrate управляет версионированными миграциями из backend/migrations:
+//
+//	go run ./cmd/migrate up    — применить недостающие (то же делает сервер при старте)
+//	go run ./cmd/migrate down  — откатить последнюю применённую (для разработки)
+package main
+
+import (
+	"fmt"
+	"log"
+	"music-review-site/backend/config"
+	"music-review-site/backend/database"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	if len(os.Args) != 2 || (os.Args[1] != "up" && os.Args[1] != "down") {
+		fmt.Fprintln(os.Stderr, "usage: migrate up|down")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := database.Connect(cfg.DB)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	var version int
+	if os.Args[1] == "up" {
+		version, err = database.ApplyMigrations(db, cfg.DB.MigrationsBaseline)
+	} else {
+		version, err = database.MigrateDown(db)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Database schema at migration version %04d", version)
+}
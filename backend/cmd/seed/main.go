@@ -0,0 +1,34 @@
+// Command seed наполняет БД демо-данными: go run ./cmd/seed.
+// Подключение и миграции — те же, что у сервера (config.Load и InitDB с
+// переменными окружения из .env); сидеры идемпотентны, повторный запуск ничего
+// не дублирует.
+package main
+
+import (
+	"log"
+	"music-review-site/backend/config"
+	"music-review-site/backend/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := database.InitDB(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	// При SEED_DEMO_DATA=true InitDB уже засеял данные.
+	if !cfg.SeedDemoData {
+		database.RunSeeds(db, cfg.Scoring, cfg.Seed)
+	}
+}
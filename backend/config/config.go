@@ -0,0 +1,399 @@
+// Package config собирает настройки backend из переменных окружения один раз
+// при старте. Ошибки (нет обязательной переменной, число не парсится) main
+// показывает все сразу и не запускает сервер, а не падает на первом запросе.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"music-review-site/backend/models"
+)
+
+// devSessionSecret — секрет сессий из .env.example и он же встроенный секрет
+// dev без SESSION_SECRET; в prod с ним не стартуем.
+const devSessionSecret = "change-me-in-prod"
+
+// dbNamePattern — допустимые имена БД: латиница, цифры, _ и -, не длиннее 63
+// байт (лимит идентификатора PostgreSQL). Кавычки, пробелы и ; отсекаются ещё
+// до того, как имя попадёт в CREATE DATABASE.
+var dbNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_-]{0,62}$`)
+
+// Config holds the settings main passes to database, routes and controllers.
+type Config struct {
+	AppEnv         string        // APP_ENV: dev (по умолчанию) или prod
+	Port           string        // PORT
+	CORSOrigins    []string      // CORS_ALLOW_ORIGINS через запятую
+	TrustedProxies []string      // TRUSTED_PROXIES: IP и CIDR через запятую, чьему X-Forwarded-For верить
+	RequestTimeout time.Duration // REQUEST_TIMEOUT, 0 — без ограничения
+	UploadsDir     string        // UPLOADS_DIR
+	SessionSecret  string        // SESSION_SECRET
+	SeedDemoData   bool          // SEED_DEMO_DATA (устаревшее имя SEED_ENABLED)
+	OpenAPIEnabled bool          // OPENAPI_ENABLED: /api/openapi.json и /api/docs, по умолчанию только в dev
+	MaxPageSize    int           // PAGE_SIZE_MAX: верхняя граница page_size во всех списках
+	LogFormat      string        // LOG_FORMAT: text (по умолчанию в dev) или json
+	LogLevel       string        // LOG_LEVEL: debug, info, warn, error
+
+	SessionTTL        time.Duration // SESSION_TTL_HOURS
+	AllowUserIDHeader bool          // AUTH_ALLOW_USER_ID_HEADER: вход по X-User-ID, по умолчанию везде, кроме prod
+
+	PublicSiteURL          string        // PUBLIC_SITE_URL без завершающего /: ссылки в письмах, RSS и вебхуках
+	ReviewWebhookURL       string        // REVIEW_WEBHOOK_URL, пусто — вебхук не отправляется
+	HideEmailConflict      bool          // REGISTER_HIDE_EMAIL_CONFLICT: не говорить, что занят именно email
+	UsernameChangeCooldown time.Duration // USERNAME_CHANGE_COOLDOWN_DAYS, 0 отключает кулдаун
+	LegacyCoverUploadDir   string        // COVER_UPLOAD_DIR: старый каталог обложек, копируется в UPLOADS_DIR
+
+	// SCORE_BASE_WEIGHT и SCORE_ATMOSPHERE_MAX: формула итоговой оценки.
+	Scoring models.ScoringConfig
+
+	// EXPOSE_EMAIL_CONFIRM_TOKEN: вернуть токен подтверждения email в ответе
+	// PUT /users/:id. Только для dev без почты; в prod не допускается.
+	ExposeEmailConfirmToken bool
+
+	DB         DBConfig
+	SMTP       SMTPConfig
+	RateLimits RateLimits
+	Seed       SeedConfig
+}
+
+// SMTPConfig — почтовый сервер для писем подтверждения email. Без SMTP_HOST
+// письма не отправляются, и смена email доступна только в dev с
+// EXPOSE_EMAIL_CONFIRM_TOKEN.
+type SMTPConfig struct {
+	Host     string // SMTP_HOST
+	Port     int    // SMTP_PORT
+	Username string // SMTP_USERNAME, пусто — без авторизации
+	Password string // SMTP_PASSWORD
+	From     string // SMTP_FROM: адрес отправителя
+}
+
+// DBConfig — подключение к PostgreSQL, пул и режим миграций.
+type DBConfig struct {
+	Host     string // DB_HOST
+	Port     string // DB_PORT
+	User     string // DB_USER
+	Password string // DB_PASSWORD
+	Name     string // DB_NAME
+	SSLMode  string // DB_SSLMODE
+
+	CreateEnabled  bool   // DB_CREATE_ENABLED, по умолчанию только в dev
+	MigrationsMode string // MIGRATIONS_MODE: versioned или manual (auto — устаревший синоним versioned)
+	LogLevel       string // DB_LOG_LEVEL: silent, error, warn, info
+
+	// MIGRATIONS_BASELINE: до какой версии считать применённой схему без
+	// schema_migrations; 0 — версия по умолчанию из database.
+	MigrationsBaseline int
+
+	MaxOpenConns     int           // DB_MAX_OPEN_CONNS
+	MaxIdleConns     int           // DB_MAX_IDLE_CONNS
+	ConnMaxLifetime  time.Duration // DB_CONN_MAX_LIFETIME
+	StatementTimeout time.Duration // DB_STATEMENT_TIMEOUT, 0 — без ограничения
+	ConnectTimeout   time.Duration // DB_CONNECT_TIMEOUT: на установку соединения и ping
+	ConnectRetries   int           // DB_CONNECT_RETRIES: повторов после первой неудачи
+}
+
+// RateLimits — ограничения частоты действий пользователей.
+type RateLimits struct {
+	LoginMaxAttempts int           // LOGIN_MAX_ATTEMPTS
+	LoginLockout     time.Duration // LOGIN_LOCKOUT_MINUTES
+	ReviewsPerHour   int           // REVIEW_RATE_LIMIT_PER_HOUR, 0 или меньше отключает лимит
+}
+
+// SeedConfig — объём и распределение демо-лайков для RunSeeds.
+type SeedConfig struct {
+	LikesMin       int     // SEED_LIKES_MIN: лайков на альбом и трек
+	LikesMax       int     // SEED_LIKES_MAX
+	ReviewLikesMin int     // SEED_REVIEW_LIKES_MIN: лайков на рецензию
+	ReviewLikesMax int     // SEED_REVIEW_LIKES_MAX
+	RecentShare    float64 // SEED_LIKES_RECENT_SHARE: доля лайков за последние 24 часа
+	Force          bool    // FORCE_RESEED: заново разложить created_at у уже засеянных лайков
+}
+
+// IsProd reports whether APP_ENV=prod.
+func (cfg *Config) IsProd() bool {
+	return cfg.AppEnv == "prod"
+}
+
+// DSN builds the connection string for the given database name: ensureDatabaseExists
+// подключается к служебной БД postgres, остальные — к cfg.Name.
+func (cfg DBConfig) DSN(dbName string) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		cfg.Host, cfg.User, cfg.Password, dbName, cfg.Port, cfg.SSLMode,
+	)
+}
+
+// Load reads the configuration from the environment. Необязательные значения
+// получают значения по умолчанию; отсутствующие обязательные и нераспознанные
+// значения возвращаются одной ошибкой со всеми проблемами.
+func Load() (*Config, error) {
+	r := &reader{}
+
+	cfg := &Config{
+		AppEnv:         strings.ToLower(r.str("APP_ENV", "dev")),
+		Port:           r.str("PORT", "8080"),
+		CORSOrigins:    splitList(r.str("CORS_ALLOW_ORIGINS", "http://localhost:3000")),
+		TrustedProxies: splitList(r.str("TRUSTED_PROXIES", "")),
+		RequestTimeout: r.duration("REQUEST_TIMEOUT", 10*time.Second),
+		UploadsDir:     r.str("UPLOADS_DIR", "uploads"),
+		SessionSecret:  r.str("SESSION_SECRET", ""),
+		SeedDemoData:   r.boolean("SEED_DEMO_DATA", r.boolean("SEED_ENABLED", false)),
+		MaxPageSize:    r.integer("PAGE_SIZE_MAX", 100),
+	}
+	dev := cfg.AppEnv == "dev"
+	cfg.OpenAPIEnabled = r.boolean("OPENAPI_ENABLED", dev)
+	cfg.ExposeEmailConfirmToken = r.boolean("EXPOSE_EMAIL_CONFIRM_TOKEN", false)
+	cfg.AllowUserIDHeader = r.boolean("AUTH_ALLOW_USER_ID_HEADER", !cfg.IsProd())
+
+	logFormat := "json"
+	if dev {
+		logFormat = "text"
+	}
+	cfg.LogFormat = strings.ToLower(r.str("LOG_FORMAT", logFormat))
+	cfg.LogLevel = strings.ToLower(r.str("LOG_LEVEL", "info"))
+	cfg.SessionTTL = time.Duration(r.integer("SESSION_TTL_HOURS", 168)) * time.Hour
+
+	cfg.PublicSiteURL = strings.TrimRight(r.str("PUBLIC_SITE_URL", "http://localhost:3000"), "/")
+	cfg.ReviewWebhookURL = r.str("REVIEW_WEBHOOK_URL", "")
+	cfg.HideEmailConflict = r.boolean("REGISTER_HIDE_EMAIL_CONFLICT", false)
+	cfg.UsernameChangeCooldown = time.Duration(r.integer("USERNAME_CHANGE_COOLDOWN_DAYS", 30)) * 24 * time.Hour
+	cfg.LegacyCoverUploadDir = r.str("COVER_UPLOAD_DIR", "")
+
+	scoring := models.DefaultScoring()
+	cfg.Scoring = models.ScoringConfig{
+		BaseWeight:    r.float("SCORE_BASE_WEIGHT", scoring.BaseWeight),
+		AtmosphereMax: r.float("SCORE_ATMOSPHERE_MAX", scoring.AtmosphereMax),
+	}
+
+	logLevel := "warn"
+	if dev {
+		logLevel = "info"
+	}
+	cfg.DB = DBConfig{
+		Host:     r.required("DB_HOST"),
+		Port:     r.str("DB_PORT", "5432"),
+		User:     r.required("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+		Name:     r.required("DB_NAME"),
+		SSLMode:  r.str("DB_SSLMODE", "disable"),
+
+		CreateEnabled:  r.boolean("DB_CREATE_ENABLED", dev),
+		MigrationsMode: r.str("MIGRATIONS_MODE", "versioned"),
+		LogLevel:       strings.ToLower(r.str("DB_LOG_LEVEL", logLevel)),
+
+		MigrationsBaseline: r.integer("MIGRATIONS_BASELINE", 0),
+
+		MaxOpenConns:     r.integer("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:     r.integer("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime:  r.duration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		StatementTimeout: r.duration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+		ConnectTimeout:   r.duration("DB_CONNECT_TIMEOUT", 5*time.Second),
+		ConnectRetries:   r.integer("DB_CONNECT_RETRIES", 5),
+	}
+	cfg.SMTP = SMTPConfig{
+		Host:     r.str("SMTP_HOST", ""),
+		Port:     r.integer("SMTP_PORT", 587),
+		Username: r.str("SMTP_USERNAME", ""),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     r.str("SMTP_FROM", ""),
+	}
+	cfg.RateLimits = RateLimits{
+		LoginMaxAttempts: r.integer("LOGIN_MAX_ATTEMPTS", 5),
+		LoginLockout:     time.Duration(r.integer("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		ReviewsPerHour:   r.integer("REVIEW_RATE_LIMIT_PER_HOUR", 20),
+	}
+	cfg.Seed = SeedConfig{
+		LikesMin:       r.integer("SEED_LIKES_MIN", 5),
+		LikesMax:       r.integer("SEED_LIKES_MAX", 30),
+		ReviewLikesMin: r.integer("SEED_REVIEW_LIKES_MIN", 3),
+		ReviewLikesMax: r.integer("SEED_REVIEW_LIKES_MAX", 18),
+		RecentShare:    r.float("SEED_LIKES_RECENT_SHARE", 0.3),
+		Force:          r.boolean("FORCE_RESEED", false),
+	}
+
+	if cfg.DB.Name != "" && !dbNamePattern.MatchString(cfg.DB.Name) {
+		r.fail("DB_NAME: %q may contain only latin letters, digits, _ and - (up to 63 characters)", cfg.DB.Name)
+	}
+	if _, err := strconv.Atoi(cfg.DB.Port); err != nil {
+		r.fail("DB_PORT: %q is not a port number", cfg.DB.Port)
+	}
+	switch cfg.DB.MigrationsMode {
+	case "versioned", "auto", "manual":
+	default:
+		r.fail("MIGRATIONS_MODE: %q is not one of versioned, auto, manual", cfg.DB.MigrationsMode)
+	}
+	// database/sql молча урезает idle до open, а open<=0 снимает лимит совсем —
+	// обе ситуации в конфиге скорее опечатка, чем намерение.
+	if cfg.DB.MaxOpenConns <= 0 || cfg.DB.MaxIdleConns < 0 {
+		r.fail("DB_MAX_OPEN_CONNS must be positive and DB_MAX_IDLE_CONNS non-negative")
+	} else if cfg.DB.MaxIdleConns > cfg.DB.MaxOpenConns {
+		r.fail("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", cfg.DB.MaxIdleConns, cfg.DB.MaxOpenConns)
+	}
+	if cfg.DB.MigrationsBaseline < 0 {
+		r.fail("MIGRATIONS_BASELINE: must not be negative")
+	}
+	if cfg.MaxPageSize <= 0 {
+		r.fail("PAGE_SIZE_MAX: must be positive")
+	}
+	switch cfg.LogFormat {
+	case "text", "json":
+	default:
+		r.fail("LOG_FORMAT: %q is not one of text, json", cfg.LogFormat)
+	}
+	switch cfg.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		r.fail("LOG_LEVEL: %q is not one of debug, info, warn, error", cfg.LogLevel)
+	}
+	if cfg.SessionTTL <= 0 {
+		r.fail("SESSION_TTL_HOURS: must be positive")
+	}
+	if cfg.UsernameChangeCooldown < 0 {
+		r.fail("USERNAME_CHANGE_COOLDOWN_DAYS: must not be negative")
+	}
+	if cfg.Scoring.BaseWeight <= 0 || cfg.Scoring.AtmosphereMax <= 1 {
+		r.fail("SCORE_BASE_WEIGHT must be positive and SCORE_ATMOSPHERE_MAX greater than 1")
+	}
+	if len(cfg.CORSOrigins) == 0 {
+		r.fail("CORS_ALLOW_ORIGINS: at least one origin is required")
+	}
+	// Без доверенных прокси ClientIP — адрес TCP-соединения; X-Forwarded-For
+	// от клиента иначе позволял бы обходить и подставлять IP в лимитах входа.
+	for _, proxy := range cfg.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			r.fail("TRUSTED_PROXIES: %q is neither an IP nor a CIDR", proxy)
+		}
+	}
+	if cfg.RateLimits.LoginMaxAttempts <= 0 || cfg.RateLimits.LoginLockout <= 0 {
+		r.fail("LOGIN_MAX_ATTEMPTS and LOGIN_LOCKOUT_MINUTES must be positive")
+	}
+	if cfg.Seed.LikesMin < 0 || cfg.Seed.LikesMax < cfg.Seed.LikesMin {
+		r.fail("SEED_LIKES_MIN must be non-negative and not exceed SEED_LIKES_MAX")
+	}
+	if cfg.Seed.ReviewLikesMin < 0 || cfg.Seed.ReviewLikesMax < cfg.Seed.ReviewLikesMin {
+		r.fail("SEED_REVIEW_LIKES_MIN must be non-negative and not exceed SEED_REVIEW_LIKES_MAX")
+	}
+	if cfg.Seed.RecentShare < 0 || cfg.Seed.RecentShare > 1 {
+		r.fail("SEED_LIKES_RECENT_SHARE: must be between 0 and 1")
+	}
+	// В dev без SESSION_SECRET работает встроенный секрет; в prod подписывать
+	// сессии известным всем значением нельзя.
+	if !dev && (cfg.SessionSecret == "" || cfg.SessionSecret == devSessionSecret) {
+		r.fail("SESSION_SECRET: required when APP_ENV=%s and must differ from %q", cfg.AppEnv, devSessionSecret)
+	}
+	if dev && cfg.SessionSecret == "" {
+		cfg.SessionSecret = devSessionSecret
+	}
+
+	if cfg.SMTP.Host != "" {
+		if cfg.SMTP.Port <= 0 || cfg.SMTP.Port > 65535 {
+			r.fail("SMTP_PORT: %d is not a port number", cfg.SMTP.Port)
+		}
+		if cfg.SMTP.From == "" {
+			r.fail("SMTP_FROM: required when SMTP_HOST is set")
+		}
+	}
+
+	if cfg.ExposeEmailConfirmToken && !dev {
+		r.fail("EXPOSE_EMAIL_CONFIRM_TOKEN: allowed only when APP_ENV=dev")
+	}
+
+	if len(r.errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %w", errors.Join(r.errs...))
+	}
+	return cfg, nil
+}
+
+// reader накапливает ошибки разбора, чтобы Load сообщил обо всех сразу.
+type reader struct {
+	errs []error
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Errorf(format, args...))
+}
+
+func (r *reader) str(key, def string) string {
+	if val := strings.TrimSpace(os.Getenv(key)); val != "" {
+		return val
+	}
+	return def
+}
+
+func (r *reader) required(key string) string {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		r.fail("%s: required", key)
+	}
+	return val
+}
+
+func (r *reader) integer(key string, def int) int {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return def
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		r.fail("%s: %q is not an integer", key, val)
+		return def
+	}
+	return n
+}
+
+func (r *reader) float(key string, def float64) float64 {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		r.fail("%s: %q is not a number", key, val)
+		return def
+	}
+	return f
+}
+
+func (r *reader) duration(key string, def time.Duration) time.Duration {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d < 0 {
+		r.fail("%s: %q is not a non-negative duration (e.g. 30s, 5m)", key, val)
+		return def
+	}
+	return d
+}
+
+func (r *reader) boolean(key string, def bool) bool {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		return def
+	}
+	switch strings.ToLower(val) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	case "0", "false", "no", "n", "off":
+		return false
+	}
+	r.fail("%s: %q is not a boolean", key, val)
+	return def
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
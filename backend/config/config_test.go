@@ -0,0 +1,145 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"music-review-site/backend/models"
+)
+
+// configKeys — все переменные, которые читает Load; тест начинает с пустого
+// окружения, чтобы значения из CI или .env разработчика не влияли на результат.
+var configKeys = []string{
+	"APP_ENV", "PORT", "CORS_ALLOW_ORIGINS", "REQUEST_TIMEOUT", "UPLOADS_DIR",
+	"SESSION_SECRET", "SESSION_TTL_HOURS", "SEED_DEMO_DATA", "SEED_ENABLED",
+	"OPENAPI_ENABLED", "PAGE_SIZE_MAX", "LOG_FORMAT", "LOG_LEVEL",
+	"AUTH_ALLOW_USER_ID_HEADER", "EXPOSE_EMAIL_CONFIRM_TOKEN", "PUBLIC_SITE_URL",
+	"REVIEW_WEBHOOK_URL", "REGISTER_HIDE_EMAIL_CONFLICT", "USERNAME_CHANGE_COOLDOWN_DAYS",
+	"COVER_UPLOAD_DIR", "SCORE_BASE_WEIGHT", "SCORE_ATMOSPHERE_MAX",
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSLMODE",
+	"DB_CREATE_ENABLED", "MIGRATIONS_MODE", "DB_LOG_LEVEL", "DB_MAX_OPEN_CONNS",
+	"DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_STATEMENT_TIMEOUT",
+	"DB_CONNECT_TIMEOUT", "DB_CONNECT_RETRIES", "MIGRATIONS_BASELINE",
+	"LOGIN_MAX_ATTEMPTS", "LOGIN_LOCKOUT_MINUTES", "REVIEW_RATE_LIMIT_PER_HOUR",
+	"SEED_LIKES_MIN", "SEED_LIKES_MAX", "SEED_REVIEW_LIKES_MIN", "SEED_REVIEW_LIKES_MAX",
+	"SEED_LIKES_RECENT_SHARE", "FORCE_RESEED",
+	"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "SMTP_FROM",
+}
+
+// setEnv очищает configKeys и выставляет переданные значения на время теста.
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, key := range configKeys {
+		t.Setenv(key, "")
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+}
+
+var requiredDB = map[string]string{"DB_HOST": "localhost", "DB_USER": "app", "DB_NAME": "music_review"}
+
+func TestLoadDefaults(t *testing.T) {
+	setEnv(t, requiredDB)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"AppEnv", cfg.AppEnv, "dev"},
+		{"Port", cfg.Port, "8080"},
+		{"RequestTimeout", cfg.RequestTimeout, 10 * time.Second},
+		{"SessionSecret", cfg.SessionSecret, devSessionSecret},
+		{"SessionTTL", cfg.SessionTTL, 168 * time.Hour},
+		{"LogFormat", cfg.LogFormat, "text"},
+		{"LogLevel", cfg.LogLevel, "info"},
+		{"AllowUserIDHeader", cfg.AllowUserIDHeader, true},
+		{"OpenAPIEnabled", cfg.OpenAPIEnabled, true},
+		{"ExposeEmailConfirmToken", cfg.ExposeEmailConfirmToken, false},
+		{"MaxPageSize", cfg.MaxPageSize, 100},
+		{"PublicSiteURL", cfg.PublicSiteURL, "http://localhost:3000"},
+		{"UsernameChangeCooldown", cfg.UsernameChangeCooldown, 30 * 24 * time.Hour},
+		{"Scoring", cfg.Scoring, models.DefaultScoring()},
+		{"DB.Port", cfg.DB.Port, "5432"},
+		{"DB.CreateEnabled", cfg.DB.CreateEnabled, true},
+		{"DB.MigrationsMode", cfg.DB.MigrationsMode, "versioned"},
+		{"DB.LogLevel", cfg.DB.LogLevel, "info"},
+		{"DB.MigrationsBaseline", cfg.DB.MigrationsBaseline, 0},
+		{"RateLimits.LoginLockout", cfg.RateLimits.LoginLockout, 15 * time.Minute},
+		{"Seed", cfg.Seed, SeedConfig{LikesMin: 5, LikesMax: 30, ReviewLikesMin: 3, ReviewLikesMax: 18, RecentShare: 0.3}},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestLoadProdDefaults(t *testing.T) {
+	env := map[string]string{"APP_ENV": "prod", "SESSION_SECRET": "s3cret", "PUBLIC_SITE_URL": "https://example.test/"}
+	for key, value := range requiredDB {
+		env[key] = value
+	}
+	setEnv(t, env)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.LogFormat != "json" || cfg.AllowUserIDHeader || cfg.OpenAPIEnabled || cfg.DB.CreateEnabled {
+		t.Errorf("prod defaults: format=%s user_id_header=%v openapi=%v db_create=%v",
+			cfg.LogFormat, cfg.AllowUserIDHeader, cfg.OpenAPIEnabled, cfg.DB.CreateEnabled)
+	}
+	if cfg.PublicSiteURL != "https://example.test" {
+		t.Errorf("PublicSiteURL = %q, want trailing slash trimmed", cfg.PublicSiteURL)
+	}
+}
+
+func TestLoadReportsAllMissingRequired(t *testing.T) {
+	setEnv(t, map[string]string{"APP_ENV": "prod"})
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("want error")
+	}
+	for _, key := range []string{"DB_HOST", "DB_USER", "DB_NAME", "SESSION_SECRET"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error does not mention %s: %v", key, err)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	cases := map[string]string{
+		"SCORE_ATMOSPHERE_MAX":          "1",
+		"SCORE_BASE_WEIGHT":             "heavy",
+		"LOG_FORMAT":                    "xml",
+		"SESSION_TTL_HOURS":             "0",
+		"USERNAME_CHANGE_COOLDOWN_DAYS": "-1",
+		"REQUEST_TIMEOUT":               "soon",
+		"MIGRATIONS_BASELINE":           "-1",
+		"SEED_LIKES_MIN":                "-5",
+		"SEED_REVIEW_LIKES_MAX":         "2",
+		"SEED_LIKES_RECENT_SHARE":       "1.5",
+		"FORCE_RESEED":                  "maybe",
+		"TRUSTED_PROXIES":               "10.0.0.1,nginx",
+		"SMTP_HOST":                     "mail.example.test", // без SMTP_FROM
+	}
+	for key, value := range cases {
+		t.Run(key, func(t *testing.T) {
+			env := map[string]string{key: value}
+			for k, v := range requiredDB {
+				env[k] = v
+			}
+			setEnv(t, env)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("%s=%q: want error mentioning %s, got %v", key, value, key, err)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"database/sql"
+	"encoding/csv"
 	"fmt"
-	"log"
+	"math"
 	"music-review-site/backend/middleware"
 	"music-review-site/backend/models"
 	"music-review-site/backend/utils"
@@ -19,7 +21,9 @@ import (
 )
 
 type AlbumController struct {
-	DB *gorm.DB
+	DB         *gorm.DB
+	UploadsDir string               // UPLOADS_DIR: обложки лежат в <UploadsDir>/covers
+	Scoring    models.ScoringConfig // SCORE_*: перевод множителя атмосферы в шкалу 1–10
 }
 
 // albumSortColumns — белый список колонок для ORDER BY по альбомам
@@ -51,6 +55,12 @@ type UpdateAlbumRequest struct {
 	ReleaseDate    string `json:"release_date"`
 }
 
+// MergeAlbumsRequest is the body of POST /admin/albums/merge.
+type MergeAlbumsRequest struct {
+	SourceID uint `json:"source_id" binding:"required"`
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
 func parseAlbumReleaseDate(value string) (*time.Time, error) {
 	if strings.TrimSpace(value) == "" {
 		return nil, nil
@@ -62,29 +72,77 @@ func parseAlbumReleaseDate(value string) (*time.Time, error) {
 	return &parsed, nil
 }
 
-func albumCoverUploadDir() string {
-	if value := strings.TrimSpace(os.Getenv("COVER_UPLOAD_DIR")); value != "" {
-		return value
+// approvedAlbums — публичные выборки показывают только прошедшие модерацию альбомы.
+func approvedAlbums(db *gorm.DB) *gorm.DB {
+	return db.Where("albums.status = ?", models.AlbumStatusApproved)
+}
+
+// albumVisibleTo reports whether a pending/rejected album may be shown to the viewer:
+// approved albums are public, остальные видят только админ и автор заявки.
+func albumVisibleTo(album *models.Album, c *gin.Context) bool {
+	if album.Status == models.AlbumStatusApproved {
+		return true
 	}
-	if _, err := os.Stat("/frontend/public/preview"); err == nil {
-		return "/frontend/public/preview/uploads"
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return false
+	}
+	return user.IsAdmin || (album.SubmittedBy != nil && *album.SubmittedBy == user.ID)
+}
+
+// visibleTrackAlbums ограничивает выборку треков альбомами, которые видит зритель, —
+// правило то же, что у albumVisibleTo: одобренные всем, остальные — админу и автору заявки.
+func visibleTrackAlbums(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		user, ok := middleware.GetUserFromContext(c)
+		if ok && user.IsAdmin {
+			return db
+		}
+		if ok {
+			return db.Where("EXISTS (SELECT 1 FROM albums WHERE albums.id = tracks.album_id AND albums.deleted_at IS NULL AND (albums.status = ? OR albums.submitted_by = ?))", models.AlbumStatusApproved, user.ID)
+		}
+		return db.Where("EXISTS (SELECT 1 FROM albums WHERE albums.id = tracks.album_id AND albums.deleted_at IS NULL AND albums.status = ?)", models.AlbumStatusApproved)
 	}
-	return filepath.Clean("../frontend/public/preview/uploads")
 }
 
 // GetAlbums retrieves list of albums with filters
 func (ac *AlbumController) GetAlbums(c *gin.Context) {
+	ac.listAlbums(c, c.Query("genre_id"))
+}
+
+// listAlbums отдаёт страницу альбомов с фильтрами, сортировкой и пагинацией из
+// query; непустой genreID ограничивает выборку жанром (GET /genres/:id/albums).
+func (ac *AlbumController) listAlbums(c *gin.Context, genreID string) {
 	var albums []models.Album
-	query := ac.DB.Model(&models.Album{}).Preload("Genre").Preload("Likes")
+	query := requestDB(c, ac.DB).Model(&models.Album{}).Preload("Genre").Preload("Likes")
+	countQuery := requestDB(c, ac.DB).Model(&models.Album{})
+
+	// Filter by moderation status: админ может запросить очередь (status=pending|rejected|all),
+	// всем остальным отдаются только одобренные альбомы.
+	status := c.Query("status")
+	if user, ok := middleware.GetUserFromContext(c); !ok || !user.IsAdmin {
+		status = ""
+	}
+	switch status {
+	case "all":
+	case string(models.AlbumStatusPending), string(models.AlbumStatusRejected), string(models.AlbumStatusApproved):
+		query = query.Where("albums.status = ?", status)
+		countQuery = countQuery.Where("albums.status = ?", status)
+	default:
+		query = query.Scopes(approvedAlbums)
+		countQuery = countQuery.Scopes(approvedAlbums)
+	}
 
 	// Filter by genre
-	if genreID := c.Query("genre_id"); genreID != "" {
+	if genreID != "" {
 		query = query.Where("genre_id = ?", genreID)
+		countQuery = countQuery.Where("genre_id = ?", genreID)
 	}
 
 	// Search by title or artist
 	if search := c.Query("search"); search != "" {
 		query = query.Where("title ILIKE ? OR artist ILIKE ?", "%"+search+"%", "%"+search+"%")
+		countQuery = countQuery.Where("title ILIKE ? OR artist ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
 	// Sort. release_date требует особой обработки NULL'ов; остальные колонки
@@ -102,22 +160,13 @@ func (ac *AlbumController) GetAlbums(c *gin.Context) {
 	}
 
 	// Pagination
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
+	page := utils.ParsePage(c)
 
 	// Count total with same filters (before pagination)
 	var total int64
-	countQuery := ac.DB.Model(&models.Album{})
-	if genreID := c.Query("genre_id"); genreID != "" {
-		countQuery = countQuery.Where("genre_id = ?", genreID)
-	}
-	if search := c.Query("search"); search != "" {
-		countQuery = countQuery.Where("title ILIKE ? OR artist ILIKE ?", "%"+search+"%", "%"+search+"%")
-	}
 	countQuery.Count(&total)
 
-	if err := query.Offset(offset).Limit(pageSize).Find(&albums).Error; err != nil {
+	if err := query.Offset(page.Offset()).Limit(page.Size).Find(&albums).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch albums",
@@ -126,12 +175,7 @@ func (ac *AlbumController) GetAlbums(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"albums":    albums,
-		"total":     total,
-		"page":      page,
-		"page_size": pageSize,
-	})
+	c.JSON(http.StatusOK, utils.NewPaginated("albums", albums, total, page))
 }
 
 // GetAlbumsByArtist retrieves all albums by artist name
@@ -144,7 +188,7 @@ func (ac *AlbumController) GetAlbumsByArtist(c *gin.Context) {
 	}
 
 	var albums []models.Album
-	query := ac.DB.Model(&models.Album{}).Preload("Genre").Preload("Likes").Where("artist = ?", decodedName)
+	query := requestDB(c, ac.DB).Model(&models.Album{}).Preload("Genre").Preload("Likes").Scopes(approvedAlbums).Where("artist = ?", decodedName)
 
 	// Sort by release_date if available, otherwise by created_at
 	query = query.Order("release_date DESC NULLS LAST, created_at DESC")
@@ -163,8 +207,8 @@ func (ac *AlbumController) GetAlbumsByArtist(c *gin.Context) {
 	var ratedAlbums int
 	for i := range albums {
 		albumIDs = append(albumIDs, albums[i].ID)
-		if err := ac.AttachAverageScoreBreakdown(&albums[i]); err != nil {
-			log.Printf("Warning: failed to attach artist album score for %d: %v", albums[i].ID, err)
+		if err := ac.withRequest(c).AttachAverageScoreBreakdown(&albums[i]); err != nil {
+			middleware.Logger(c).Warn("failed to attach artist album score", "album_id", albums[i].ID, "error", err)
 		}
 		if albums[i].AverageRating > 0 {
 			ratingSum += albums[i].AverageRating
@@ -174,23 +218,23 @@ func (ac *AlbumController) GetAlbumsByArtist(c *gin.Context) {
 
 	var totalTracks, approvedReviews int64
 	if len(albumIDs) > 0 {
-		ac.DB.Model(&models.Track{}).Where("album_id IN ?", albumIDs).Count(&totalTracks)
-		ac.DB.Model(&models.Review{}).
+		requestDB(c, ac.DB).Model(&models.Track{}).Where("album_id IN ?", albumIDs).Count(&totalTracks)
+		requestDB(c, ac.DB).Model(&models.Review{}).
 			Where("album_id IN ? AND status = ?", albumIDs, models.ReviewStatusApproved).
 			Count(&approvedReviews)
 	}
 
 	var verifiedAccount interface{}
 	var artistUser models.User
-	if err := ac.DB.Where("is_verified_artist = ? AND LOWER(artist_name) = LOWER(?)", true, decodedName).First(&artistUser).Error; err == nil {
+	if err := requestDB(c, ac.DB).Where("is_verified_artist = ? AND LOWER(artist_name) = LOWER(?)", true, decodedName).First(&artistUser).Error; err == nil {
 		var followersCount int64
-		ac.DB.Model(&models.UserFollow{}).Where("following_id = ?", artistUser.ID).Count(&followersCount)
+		requestDB(c, ac.DB).Model(&models.UserFollow{}).Where("following_id = ?", artistUser.ID).Count(&followersCount)
 		verifiedAccount = gin.H{
 			"id":                 artistUser.ID,
 			"username":           artistUser.Username,
 			"avatar_path":        artistUser.AvatarPath,
 			"bio":                artistUser.Bio,
-			"social_links":       artistUser.SocialLinks,
+			"social_links":       utils.ParseSocialLinks(artistUser.SocialLinks),
 			"is_verified_artist": true,
 			"artist_name":        artistUser.ArtistName,
 			"followers_count":    followersCount,
@@ -218,7 +262,7 @@ func (ac *AlbumController) GetAlbum(c *gin.Context) {
 	id := c.Param("id")
 	var album models.Album
 
-	if err := ac.DB.Preload("Genre").Preload("Tracks").Preload("Likes").First(&album, id).Error; err != nil {
+	if err := requestDB(c, ac.DB).Preload("Genre").Preload("Tracks", func(tx *gorm.DB) *gorm.DB { return tx.Order(trackListOrder) }).Preload("Likes").First(&album, id).Error; err != nil || !albumVisibleTo(&album, c) {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Album not found",
@@ -226,28 +270,292 @@ func (ac *AlbumController) GetAlbum(c *gin.Context) {
 		})
 		return
 	}
-	if err := ac.AttachAverageScoreBreakdown(&album); err != nil {
-		log.Printf("Warning: failed to attach average score breakdown for album %d: %v", album.ID, err)
+	if err := ac.withRequest(c).AttachAverageScoreBreakdown(&album); err != nil {
+		middleware.Logger(c).Warn("failed to attach average score breakdown", "album_id", album.ID, "error", err)
 	}
+	if err := ac.withRequest(c).attachRank(&album); err != nil {
+		middleware.Logger(c).Warn("failed to attach album rank", "album_id", album.ID, "error", err)
+	}
+	album.SummarizeTracks()
+	album.ReviewedByMe, album.MyReviewID = currentUserReview(c, requestDB(c, ac.DB), "album_id", album.ID)
 
 	c.JSON(http.StatusOK, album)
 }
 
+// attachRank fills album.Rank with one window-function query over approved
+// albums that have a rating; альбом без оценки или не одобренный места не получает.
+func (ac *AlbumController) attachRank(album *models.Album) error {
+	if album.Status != models.AlbumStatusApproved || album.AverageRating <= 0 {
+		return nil
+	}
+	var ranks []models.AlbumRank
+	err := ac.DB.Raw(`
+		SELECT overall, overall_total, in_genre, genre_total FROM (
+			SELECT id,
+				RANK() OVER (ORDER BY average_rating DESC) AS overall,
+				COUNT(*) OVER () AS overall_total,
+				RANK() OVER (PARTITION BY genre_id ORDER BY average_rating DESC) AS in_genre,
+				COUNT(*) OVER (PARTITION BY genre_id) AS genre_total
+			FROM albums
+			WHERE deleted_at IS NULL AND status = ? AND average_rating > 0
+		) ranked
+		WHERE id = ?`, models.AlbumStatusApproved, album.ID).Scan(&ranks).Error
+	if err != nil {
+		return err
+	}
+	if len(ranks) > 0 {
+		album.Rank = &ranks[0]
+	}
+	return nil
+}
+
+// GetAlbumsBatch retrieves albums by a list of IDs preserving the requested order
+func (ac *AlbumController) GetAlbumsBatch(c *gin.Context) {
+	ids, ok := batchIDsFromQuery(c)
+	if !ok {
+		return
+	}
+
+	// Неодобренные альбомы в batch не попадают и считаются отсутствующими.
+	var albums []models.Album
+	if err := requestDB(c, ac.DB).Preload("Genre").Preload("Likes").Scopes(approvedAlbums).
+		Where("albums.id IN ?", ids).Find(&albums).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch albums",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	for i := range albums {
+		if err := ac.withRequest(c).AttachAverageScoreBreakdown(&albums[i]); err != nil {
+			middleware.Logger(c).Warn("failed to attach average score breakdown", "album_id", albums[i].ID, "error", err)
+		}
+	}
+
+	ordered, missing := orderByIDs(ids, albums, func(a *models.Album) uint { return a.ID })
+	c.JSON(http.StatusOK, gin.H{
+		"albums":  ordered,
+		"missing": missing,
+	})
+}
+
+// reviewScoreBuckets — интервалы гистограммы итоговых оценок (шкала до 90).
+var reviewScoreBuckets = []struct {
+	Min int
+	Max int
+}{
+	{0, 20}, {21, 40}, {41, 60}, {61, 80}, {81, 90},
+}
+
+// ReviewScoreBucket is one bar of the album rating distribution
+type ReviewScoreBucket struct {
+	Label string `json:"label"`
+	Min   int    `json:"min"`
+	Max   int    `json:"max"`
+	Count int64  `json:"count"`
+}
+
+// GetAlbumReviewStats returns distribution of approved review scores for an album
+func (ac *AlbumController) GetAlbumReviewStats(c *gin.Context) {
+	id := c.Param("id")
+	var album models.Album
+
+	if err := requestDB(c, ac.DB).Select("id").First(&album, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Album not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// Один агрегат с GROUP BY по номеру интервала; пустые интервалы добиваем нулями ниже.
+	var rows []struct {
+		Bucket int
+		Count  int64
+		Total  float64
+	}
+	if err := requestDB(c, ac.DB).Model(&models.Review{}).
+		Select(`
+			CASE
+				WHEN final_score <= 20 THEN 0
+				WHEN final_score <= 40 THEN 1
+				WHEN final_score <= 60 THEN 2
+				WHEN final_score <= 80 THEN 3
+				ELSE 4
+			END AS bucket,
+			COUNT(*) AS count,
+			COALESCE(SUM(final_score), 0) AS total
+		`).
+		Where("album_id = ? AND status = ?", album.ID, models.ReviewStatusApproved).
+		Group("bucket").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to calculate review stats",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	buckets := make([]ReviewScoreBucket, len(reviewScoreBuckets))
+	for i, bounds := range reviewScoreBuckets {
+		buckets[i] = ReviewScoreBucket{
+			Label: fmt.Sprintf("%d-%d", bounds.Min, bounds.Max),
+			Min:   bounds.Min,
+			Max:   bounds.Max,
+		}
+	}
+
+	var reviewsCount int64
+	var scoreSum float64
+	for _, row := range rows {
+		if row.Bucket >= 0 && row.Bucket < len(buckets) {
+			buckets[row.Bucket].Count = row.Count
+		}
+		reviewsCount += row.Count
+		scoreSum += row.Total
+	}
+
+	averageScore := 0.0
+	if reviewsCount > 0 {
+		averageScore = math.Round(scoreSum/float64(reviewsCount)*10) / 10
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"album_id":      album.ID,
+		"reviews_count": reviewsCount,
+		"average_score": averageScore,
+		"buckets":       buckets,
+	})
+}
+
+// TrackReviewSummary is the per-track aggregate returned by GetAlbumTrackReviews
+type TrackReviewSummary struct {
+	ReviewsCount int64   `json:"reviews_count"`
+	AverageScore float64 `json:"average_score"`
+}
+
+// GetAlbumTrackReviews returns review count and average score of approved reviews
+// for every track of the album, keyed by track_id
+func (ac *AlbumController) GetAlbumTrackReviews(c *gin.Context) {
+	id := c.Param("id")
+	var album models.Album
+	if err := requestDB(c, ac.DB).First(&album, id).Error; err != nil || !albumVisibleTo(&album, c) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Album not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// Один запрос: LEFT JOIN, чтобы треки без рецензий тоже попали в ответ с нулями.
+	var rows []struct {
+		TrackID uint
+		Count   int64
+		Average float64
+	}
+	if err := requestDB(c, ac.DB).Model(&models.Track{}).
+		Select("tracks.id AS track_id, COUNT(reviews.id) AS count, COALESCE(AVG(reviews.final_score), 0) AS average").
+		Joins("LEFT JOIN reviews ON reviews.track_id = tracks.id AND reviews.status = ? AND reviews.deleted_at IS NULL", models.ReviewStatusApproved).
+		Where("tracks.album_id = ?", album.ID).
+		Group("tracks.id").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to calculate track review stats",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	tracks := make(map[uint]TrackReviewSummary, len(rows))
+	for _, row := range rows {
+		tracks[row.TrackID] = TrackReviewSummary{
+			ReviewsCount: row.Count,
+			AverageScore: math.Round(row.Average*10) / 10,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"album_id": album.ID,
+		"tracks":   tracks,
+	})
+}
+
+// GetAlbumReviewsCSV exports approved reviews of an album as a CSV file
+func (ac *AlbumController) GetAlbumReviewsCSV(c *gin.Context) {
+	id := c.Param("id")
+	var album models.Album
+	if err := requestDB(c, ac.DB).First(&album, id).Error; err != nil || !albumVisibleTo(&album, c) {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Album not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var reviews []models.Review
+	if err := requestDB(c, ac.DB).Preload("User", withDeletedAuthor).
+		Where("album_id = ? AND status = ?", album.ID, models.ReviewStatusApproved).
+		Order("created_at ASC").
+		Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch reviews",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("album-%d-reviews.csv", album.ID)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	// BOM, чтобы Excel открывал кириллицу в UTF-8 без ручного выбора кодировки.
+	c.Writer.WriteString("\ufeff")
+	// encoding/csv сам экранирует кавычки, запятые и переводы строк в тексте.
+	writer := csv.NewWriter(c.Writer)
+	rows := [][]string{{
+		"username", "rating_rhymes", "rating_structure", "rating_implementation",
+		"rating_individuality", "atmosphere", "final_score", "created_at", "text",
+	}}
+	for _, review := range reviews {
+		// Атмосфера хранится множителем; в выгрузку — в исходной шкале 1–10.
+		atmosphere := math.Round(ac.Scoring.AtmosphereRating(review.AtmosphereMultiplier))
+		rows = append(rows, []string{
+			review.User.Username,
+			strconv.Itoa(review.RatingRhymes),
+			strconv.Itoa(review.RatingStructure),
+			strconv.Itoa(review.RatingImplementation),
+			strconv.Itoa(review.RatingIndividuality),
+			strconv.Itoa(int(atmosphere)),
+			strconv.FormatFloat(review.FinalScore, 'f', -1, 64),
+			review.CreatedAt.UTC().Format(time.RFC3339),
+			review.Text,
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		middleware.Logger(c).Error("failed to write reviews csv", "album_id", album.ID, "error", err)
+	}
+}
+
 // CreateAlbum creates a new album
 func (ac *AlbumController) CreateAlbum(c *gin.Context) {
 	var req CreateAlbumRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
 	// Check if genre exists
 	var genre models.Genre
-	if err := ac.DB.First(&genre, req.GenreID).Error; err != nil {
+	if err := requestDB(c, ac.DB).First(&genre, req.GenreID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 			Error:   "Bad Request",
 			Message: "Genre not found",
@@ -256,6 +564,16 @@ func (ac *AlbumController) CreateAlbum(c *gin.Context) {
 		return
 	}
 
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
 	album := models.Album{
 		Title:          req.Title,
 		Artist:         req.Artist,
@@ -263,6 +581,16 @@ func (ac *AlbumController) CreateAlbum(c *gin.Context) {
 		CoverImagePath: req.CoverImagePath,
 		Description:    req.Description,
 		AverageRating:  0,
+		SubmittedBy:    &user.ID,
+	}
+	// Альбом от админа публикуется сразу, от пользователя — уходит в очередь модерации.
+	if user.IsAdmin {
+		now := time.Now()
+		album.Status = models.AlbumStatusApproved
+		album.ModeratedBy = &user.ID
+		album.ModeratedAt = &now
+	} else {
+		album.Status = models.AlbumStatusPending
 	}
 
 	releaseDate, err := parseAlbumReleaseDate(req.ReleaseDate)
@@ -276,7 +604,7 @@ func (ac *AlbumController) CreateAlbum(c *gin.Context) {
 	}
 	album.ReleaseDate = releaseDate
 
-	if err := ac.DB.Create(&album).Error; err != nil {
+	if err := requestDB(c, ac.DB).Create(&album).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to create album",
@@ -285,16 +613,67 @@ func (ac *AlbumController) CreateAlbum(c *gin.Context) {
 		return
 	}
 
-	ac.DB.Preload("Genre").First(&album, album.ID)
+	requestDB(c, ac.DB).Preload("Genre").First(&album, album.ID)
 	c.JSON(http.StatusCreated, album)
 }
 
+// ApproveAlbum publishes a submitted album (admin only)
+func (ac *AlbumController) ApproveAlbum(c *gin.Context) {
+	ac.moderateAlbum(c, models.AlbumStatusApproved)
+}
+
+// RejectAlbum rejects a submitted album (admin only)
+func (ac *AlbumController) RejectAlbum(c *gin.Context) {
+	ac.moderateAlbum(c, models.AlbumStatusRejected)
+}
+
+func (ac *AlbumController) moderateAlbum(c *gin.Context, status models.AlbumStatus) {
+	id := c.Param("id")
+	var album models.Album
+
+	if err := requestDB(c, ac.DB).First(&album, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Album not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	now := time.Now()
+	if err := requestDB(c, ac.DB).Model(&album).Updates(map[string]interface{}{
+		"status":       status,
+		"moderated_by": userID,
+		"moderated_at": now,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to moderate album",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	requestDB(c, ac.DB).Preload("Genre").First(&album, album.ID)
+	c.JSON(http.StatusOK, album)
+}
+
 // UpdateAlbum updates an album
 func (ac *AlbumController) UpdateAlbum(c *gin.Context) {
 	id := c.Param("id")
 	var album models.Album
 
-	if err := ac.DB.First(&album, id).Error; err != nil {
+	if err := requestDB(c, ac.DB).First(&album, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Album not found",
@@ -305,11 +684,7 @@ func (ac *AlbumController) UpdateAlbum(c *gin.Context) {
 
 	var req UpdateAlbumRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
@@ -323,7 +698,7 @@ func (ac *AlbumController) UpdateAlbum(c *gin.Context) {
 	if req.GenreID != 0 {
 		// Check if genre exists
 		var genre models.Genre
-		if err := ac.DB.First(&genre, req.GenreID).Error; err != nil {
+		if err := requestDB(c, ac.DB).First(&genre, req.GenreID).Error; err != nil {
 			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 				Error:   "Bad Request",
 				Message: "Genre not found",
@@ -352,7 +727,7 @@ func (ac *AlbumController) UpdateAlbum(c *gin.Context) {
 		album.ReleaseDate = releaseDate
 	}
 
-	if err := ac.DB.Save(&album).Error; err != nil {
+	if err := requestDB(c, ac.DB).Save(&album).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to update album",
@@ -361,11 +736,11 @@ func (ac *AlbumController) UpdateAlbum(c *gin.Context) {
 		return
 	}
 
-	ac.DB.Preload("Genre").First(&album, album.ID)
+	requestDB(c, ac.DB).Preload("Genre").First(&album, album.ID)
 	c.JSON(http.StatusOK, album)
 }
 
-// UploadCover uploads an album cover into the public preview storage.
+// UploadCover uploads an album cover into UPLOADS_DIR/covers.
 func (ac *AlbumController) UploadCover(c *gin.Context) {
 	file, err := c.FormFile("cover")
 	if err != nil {
@@ -397,7 +772,7 @@ func (ac *AlbumController) UploadCover(c *gin.Context) {
 		return
 	}
 
-	uploadDir := albumCoverUploadDir()
+	uploadDir := filepath.Join(ac.UploadsDir, utils.UploadsCoversDir)
 	if err := os.MkdirAll(uploadDir, 0o755); err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -431,7 +806,7 @@ func (ac *AlbumController) UploadCover(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"cover_image_path": "/preview/uploads/" + filename,
+		"cover_image_path": utils.UploadPublicPath(utils.UploadsCoversDir, filename),
 	})
 }
 
@@ -440,7 +815,7 @@ func (ac *AlbumController) DeleteAlbum(c *gin.Context) {
 	id := c.Param("id")
 	var album models.Album
 
-	if err := ac.DB.First(&album, id).Error; err != nil {
+	if err := requestDB(c, ac.DB).First(&album, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Album not found",
@@ -449,7 +824,27 @@ func (ac *AlbumController) DeleteAlbum(c *gin.Context) {
 		return
 	}
 
-	if err := ac.DB.Delete(&album).Error; err != nil {
+	// Вместе с альбомом мягко удаляются его треки (со всем, что на них висит),
+	// рецензии и лайки альбома — иначе они продолжают всплывать в списках и поиске.
+	if err := requestDB(c, ac.DB).Transaction(func(tx *gorm.DB) error {
+		var trackIDs []uint
+		if err := tx.Model(&models.Track{}).Where("album_id = ?", album.ID).Pluck("id", &trackIDs).Error; err != nil {
+			return err
+		}
+		if err := deleteTrackDependents(tx, trackIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("album_id = ?", album.ID).Delete(&models.Track{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("album_id = ?", album.ID).Delete(&models.Review{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("album_id = ?", album.ID).Delete(&models.AlbumLike{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&album).Error
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to delete album",
@@ -463,6 +858,147 @@ func (ac *AlbumController) DeleteAlbum(c *gin.Context) {
 	})
 }
 
+// MergeAlbums moves tracks, reviews and likes of a duplicate album into target
+// and soft-deletes the duplicate (admin only): POST /admin/albums/merge.
+func (ac *AlbumController) MergeAlbums(c *gin.Context) {
+	var req MergeAlbumsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondBindingError(c, err)
+		return
+	}
+	if req.SourceID == req.TargetID {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Cannot merge an album into itself",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var source, target models.Album
+	if err := requestDB(c, ac.DB).First(&source, req.SourceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Album not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err := requestDB(c, ac.DB).First(&target, req.TargetID).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Target album not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var moved albumMoveResult
+	err := requestDB(c, ac.DB).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if moved, err = moveAlbumReferences(tx, source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&source).Error; err != nil {
+			return err
+		}
+		return (&AlbumController{DB: tx}).CalculateAverageRating(target.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to merge albums",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(c)
+	middleware.Logger(c).Info("audit: album merged", "admin_id", adminID,
+		"source_id", source.ID, "source_title", source.Title, "target_id", target.ID, "target_title", target.Title,
+		"tracks", moved.Tracks, "reviews", moved.Reviews, "reviews_merged", moved.ReviewsMerged,
+		"likes", moved.Likes, "likes_merged", moved.LikesMerged)
+
+	requestDB(c, ac.DB).First(&target, target.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Albums merged successfully",
+		"source_id":      source.ID,
+		"target_id":      target.ID,
+		"average_rating": target.AverageRating,
+		"tracks_moved":   moved.Tracks,
+		"reviews_moved":  moved.Reviews,
+		"reviews_merged": moved.ReviewsMerged,
+		"likes_moved":    moved.Likes,
+		"likes_merged":   moved.LikesMerged,
+	})
+}
+
+// albumMoveResult — сколько строк перенесено на другой альбом.
+type albumMoveResult struct {
+	Tracks        int64
+	Reviews       int64
+	ReviewsMerged int64 // Рецензии на дубль, удалённые, потому что у автора уже есть рецензия на target
+	Likes         int64
+	LikesMerged   int64 // Лайки дубля, удалённые, потому что пользователь уже лайкнул target
+}
+
+// moveAlbumReferences repoints tracks, reviews and likes from sourceID to targetID
+// inside tx. Сам исходный альбом не удаляет и рейтинг не пересчитывает.
+func moveAlbumReferences(tx *gorm.DB, sourceID, targetID uint) (albumMoveResult, error) {
+	var moved albumMoveResult
+
+	// Номера треков уникальны в альбоме (ux_tracks_album_number), поэтому
+	// перенесённые треки встают после последнего трека target в прежнем порядке.
+	result := tx.Exec(`
+		UPDATE tracks SET album_id = @target, track_number = renumbered.number
+		FROM (
+			SELECT id, (SELECT COALESCE(MAX(track_number), 0) FROM tracks
+					WHERE album_id = @target AND deleted_at IS NULL)
+				+ ROW_NUMBER() OVER (ORDER BY `+trackListOrder+`) AS number
+			FROM tracks
+			WHERE album_id = @source AND deleted_at IS NULL
+		) AS renumbered
+		WHERE tracks.id = renumbered.id`,
+		sql.Named("source", sourceID), sql.Named("target", targetID))
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Tracks = result.RowsAffected
+
+	// Вторая рецензия того же автора на target нарушила бы ux_reviews_user_album —
+	// остаётся рецензия на target, рецензия на дубль мягко удаляется.
+	result = tx.Where("album_id = ? AND user_id IN (?)", sourceID,
+		tx.Model(&models.Review{}).Select("user_id").Where("album_id = ?", targetID)).
+		Delete(&models.Review{})
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.ReviewsMerged = result.RowsAffected
+
+	result = tx.Model(&models.Review{}).Where("album_id = ?", sourceID).Update("album_id", targetID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Reviews = result.RowsAffected
+
+	// ux_album_like_pair не учитывает deleted_at, поэтому лайки сравниваются и
+	// переносятся вместе с мягко удалёнными, а дубли удаляются жёстко.
+	result = tx.Unscoped().Where("album_id = ? AND user_id IN (?)", sourceID,
+		tx.Unscoped().Model(&models.AlbumLike{}).Select("user_id").Where("album_id = ?", targetID)).
+		Delete(&models.AlbumLike{})
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.LikesMerged = result.RowsAffected
+
+	result = tx.Unscoped().Model(&models.AlbumLike{}).Where("album_id = ?", sourceID).Update("album_id", targetID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Likes = result.RowsAffected
+	return moved, nil
+}
+
 // CalculateAverageRating calculates and updates average rating for an album
 func (ac *AlbumController) CalculateAverageRating(albumID uint) error {
 	var reviews []models.Review
@@ -522,7 +1058,7 @@ func (ac *AlbumController) AttachAverageScoreBreakdown(album *models.Album) erro
 	album.AverageRatingStructure = avg.Structure
 	album.AverageRatingImplementation = avg.Implementation
 	album.AverageRatingIndividuality = avg.Individuality
-	album.AverageAtmosphereRating = 1 + (avg.AtmosphereMult-1.0)/(0.6072/9.0)
+	album.AverageAtmosphereRating = ac.Scoring.AtmosphereRating(avg.AtmosphereMult)
 	return nil
 }
 
@@ -541,7 +1077,7 @@ func (ac *AlbumController) LikeAlbum(c *gin.Context) {
 
 	// Check if album exists
 	var album models.Album
-	if err := ac.DB.First(&album, albumID).Error; err != nil {
+	if err := requestDB(c, ac.DB).First(&album, albumID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Album not found",
@@ -552,8 +1088,8 @@ func (ac *AlbumController) LikeAlbum(c *gin.Context) {
 
 	// Check if like already exists
 	var existingLike models.AlbumLike
-	if err := ac.DB.Where("user_id = ? AND album_id = ?", userID, albumID).First(&existingLike).Error; err == nil {
-		c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true})
+	if err := requestDB(c, ac.DB).Where("user_id = ? AND album_id = ?", userID, albumID).First(&existingLike).Error; err == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true, "likes_count": countAlbumLikes(requestDB(c, ac.DB), album.ID)})
 		return
 	}
 
@@ -563,7 +1099,12 @@ func (ac *AlbumController) LikeAlbum(c *gin.Context) {
 		AlbumID: album.ID,
 	}
 
-	if err := ac.DB.Create(&like).Error; err != nil {
+	if err := requestDB(c, ac.DB).Create(&like).Error; err != nil {
+		// Параллельный запрос успел поставить тот же лайк — это не ошибка.
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true, "likes_count": countAlbumLikes(requestDB(c, ac.DB), album.ID)})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to like album",
@@ -572,7 +1113,16 @@ func (ac *AlbumController) LikeAlbum(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Album liked", "liked": true})
+	c.JSON(http.StatusCreated, gin.H{"message": "Album liked", "liked": true, "likes_count": countAlbumLikes(requestDB(c, ac.DB), album.ID)})
+}
+
+// countAlbumLikes returns how many users liked the album. Лайк и снятие лайка
+// отвечают этим числом, чтобы счётчик на карточке альбома обновлялся без
+// перезагрузки карточки (0 при ошибке).
+func countAlbumLikes(db *gorm.DB, albumID uint) int64 {
+	var count int64
+	db.Model(&models.AlbumLike{}).Where("album_id = ?", albumID).Count(&count)
+	return count
 }
 
 // UnlikeAlbum removes a like from an album
@@ -590,7 +1140,7 @@ func (ac *AlbumController) UnlikeAlbum(c *gin.Context) {
 
 	// Check if album exists
 	var album models.Album
-	if err := ac.DB.First(&album, albumID).Error; err != nil {
+	if err := requestDB(c, ac.DB).First(&album, albumID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Album not found",
@@ -600,7 +1150,7 @@ func (ac *AlbumController) UnlikeAlbum(c *gin.Context) {
 	}
 
 	// Жёсткое удаление (см. уникальный индекс ux_album_like_pair).
-	if err := ac.DB.Unscoped().Where("user_id = ? AND album_id = ?", userID, albumID).Delete(&models.AlbumLike{}).Error; err != nil {
+	if err := requestDB(c, ac.DB).Unscoped().Where("user_id = ? AND album_id = ?", userID, albumID).Delete(&models.AlbumLike{}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to unlike album",
@@ -609,5 +1159,5 @@ func (ac *AlbumController) UnlikeAlbum(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Album unliked", "liked": false})
+	c.JSON(http.StatusOK, gin.H{"message": "Album unliked", "liked": false, "likes_count": countAlbumLikes(requestDB(c, ac.DB), album.ID)})
 }
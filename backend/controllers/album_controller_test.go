@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"music-review-site/backend/models"
+)
+
+func TestMergeAlbumsMovesTracksAndReviews(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+
+	source := seedAlbum(t, db, "duplicate", models.AlbumStatusApproved)
+	target := seedAlbum(t, db, "original", models.AlbumStatusApproved)
+	for number := 1; number <= 3; number++ {
+		seedTrack(t, db, target.ID, fmt.Sprintf("Original %d", number), number)
+	}
+	first := seedTrack(t, db, source.ID, "Duplicate 1", 1)
+	second := seedTrack(t, db, source.ID, "Duplicate 2", 2)
+
+	onlySource := seedUser(t, db, "merge-only-source", false)
+	onlyTarget := seedUser(t, db, "merge-only-target", false)
+	both := seedUser(t, db, "merge-both", false)
+	admin := seedUser(t, db, "merge-admin", true)
+	moved := seedAlbumReview(t, db, onlySource.ID, source.ID, 40)
+	seedAlbumReview(t, db, onlyTarget.ID, target.ID, 20)
+	dropped := seedAlbumReview(t, db, both.ID, source.ID, 10)
+	seedAlbumReview(t, db, both.ID, target.ID, 30)
+
+	body := fmt.Sprintf(`{"source_id": %d, "target_id": %d}`, source.ID, target.ID)
+	w := serve(ac.MergeAlbums, http.MethodPost, "/admin/albums/merge", "/admin/albums/merge", body, &admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("merge: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		AverageRating float64 `json:"average_rating"`
+		TracksMoved   int64   `json:"tracks_moved"`
+		ReviewsMoved  int64   `json:"reviews_moved"`
+		ReviewsMerged int64   `json:"reviews_merged"`
+	}
+	decode(t, w, &resp)
+	if resp.TracksMoved != 2 || resp.ReviewsMoved != 1 || resp.ReviewsMerged != 1 {
+		t.Errorf("counters: got %+v", resp)
+	}
+	// Остались рецензии 40, 20 и 30: среднее 30.
+	if resp.AverageRating != 30 {
+		t.Errorf("average_rating: want 30, got %v", resp.AverageRating)
+	}
+
+	// Перенесённые треки встают после трёх своих, в прежнем порядке.
+	for track, want := range map[uint]int{first.ID: 4, second.ID: 5} {
+		var got models.Track
+		if err := db.First(&got, track).Error; err != nil {
+			t.Fatalf("load track %d: %v", track, err)
+		}
+		if got.AlbumID != target.ID || got.TrackNumber == nil || *got.TrackNumber != want {
+			t.Errorf("track %d: want album %d number %d, got album %d number %v", track, target.ID, want, got.AlbumID, got.TrackNumber)
+		}
+	}
+
+	var review models.Review
+	if err := db.First(&review, moved.ID).Error; err != nil || review.AlbumID == nil || *review.AlbumID != target.ID {
+		t.Errorf("review %d should move to album %d: %+v, %v", moved.ID, target.ID, review.AlbumID, err)
+	}
+	if err := db.First(&models.Review{}, dropped.ID).Error; err == nil {
+		t.Errorf("review %d duplicates the author's target review and should be deleted", dropped.ID)
+	}
+	if err := db.First(&models.Album{}, source.ID).Error; err == nil {
+		t.Errorf("source album %d should be soft-deleted", source.ID)
+	}
+}
+
+// Удалённый альбом уносит свои треки и рецензии: в GET /tracks их больше нет
+// даже для администратора.
+func TestDeleteAlbumHidesTracks(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "delete-album-admin", true)
+	album := seedAlbum(t, db, "deleted-album", models.AlbumStatusApproved)
+	kept := seedTrack(t, db, seedAlbum(t, db, "kept-album", models.AlbumStatusApproved).ID, "Kept", 1)
+	first := seedTrack(t, db, album.ID, "Gone 1", 1)
+	second := seedTrack(t, db, album.ID, "Gone 2", 2)
+	review := seedAlbumReview(t, db, admin.ID, album.ID, 40)
+
+	before := listedTrackIDs(t, tc, "", &admin)
+	if !before[first.ID] || !before[second.ID] {
+		t.Fatalf("tracks not listed before delete: %v", before)
+	}
+
+	target := fmt.Sprintf("/albums/%d", album.ID)
+	if w := serve(ac.DeleteAlbum, http.MethodDelete, "/albums/:id", target, "", &admin); w.Code != http.StatusOK {
+		t.Fatalf("DELETE %s: %d %s", target, w.Code, w.Body.String())
+	}
+	for _, user := range []*models.User{nil, &admin} {
+		after := listedTrackIDs(t, tc, "", user)
+		if after[first.ID] || after[second.ID] || !after[kept.ID] {
+			t.Errorf("after delete: listed %v, want %d without %d and %d", after, kept.ID, first.ID, second.ID)
+		}
+	}
+	if err := db.First(&models.Review{}, review.ID).Error; err == nil {
+		t.Error("album review still visible")
+	}
+}
+
+// Альбом от обычного пользователя ждёт модерации и не виден в GET /albums
+// никому, кроме админа (даже автору и с ?status=pending); после одобрения —
+// виден всем.
+func TestPendingAlbumsHiddenFromListing(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "album-list-admin", true)
+	member := seedUser(t, db, "album-list-member", false)
+	approved := seedAlbum(t, db, "album-list-approved", models.AlbumStatusApproved)
+
+	body := fmt.Sprintf(`{"title": "Submitted", "artist": "Member", "genre_id": %d}`, approved.GenreID)
+	w := serve(ac.CreateAlbum, http.MethodPost, "/albums", "/albums", body, &member)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var submitted models.Album
+	decode(t, w, &submitted)
+	if submitted.Status != models.AlbumStatusPending {
+		t.Fatalf("album by a member has status %q, want pending", submitted.Status)
+	}
+
+	listed := func(query string, user *models.User) map[uint]bool {
+		target := fmt.Sprintf("/albums?page_size=100&genre_id=%d%s", approved.GenreID, query)
+		w := serve(ac.GetAlbums, http.MethodGet, "/albums", target, "", user)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", target, w.Code, w.Body.String())
+		}
+		var body struct {
+			Albums []models.Album `json:"albums"`
+			Total  int64          `json:"total"`
+		}
+		decode(t, w, &body)
+		ids := make(map[uint]bool, len(body.Albums))
+		for _, album := range body.Albums {
+			ids[album.ID] = true
+		}
+		if int64(len(ids)) != body.Total {
+			t.Errorf("GET %s: total %d for %d albums", target, body.Total, len(ids))
+		}
+		return ids
+	}
+	onlyApproved := map[string]map[uint]bool{
+		"guest":               listed("", nil),
+		"submitter":           listed("", &member),
+		"member with pending": listed("&status=pending", &member),
+		"admin by default":    listed("", &admin),
+	}
+	for name, ids := range onlyApproved {
+		if len(ids) != 1 || !ids[approved.ID] {
+			t.Errorf("%s: listed %v, want only %d", name, ids, approved.ID)
+		}
+	}
+	if ids := listed("&status=pending", &admin); len(ids) != 1 || !ids[submitted.ID] {
+		t.Errorf("admin, status=pending: listed %v, want only %d", ids, submitted.ID)
+	}
+
+	target := fmt.Sprintf("/albums/%d/approve", submitted.ID)
+	if w := serve(ac.ApproveAlbum, http.MethodPost, "/albums/:id/approve", target, "", &admin); w.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", w.Code, w.Body.String())
+	}
+	if ids := listed("", nil); len(ids) != 2 || !ids[submitted.ID] {
+		t.Errorf("after approval: listed %v, want both albums", ids)
+	}
+}
+
+func TestLikeAlbumStatuses(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	user := seedUser(t, db, "album-liker", false)
+	album := seedAlbum(t, db, "liked-album", models.AlbumStatusApproved)
+	checkLikeCycle(t, ac.LikeAlbum, ac.UnlikeAlbum, "/albums/:id/like", fmt.Sprintf("/albums/%d/like", album.ID), &user)
+}
+
+// CSV-выгрузка: заголовки ответа, строка заголовков и одна одобренная рецензия
+// с экранированным текстом; рецензия на модерации в файл не попадает.
+func TestGetAlbumReviewsCSV(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	album := seedAlbum(t, db, "csv", models.AlbumStatusApproved)
+	author := seedUser(t, db, "csv-author", false)
+	review := seedAlbumReview(t, db, author.ID, album.ID, 20)
+	text := "Сильно, \"по-честному\",\nс переносом строки"
+	db.Model(&review).Update("text", text)
+	pending := seedAlbumReview(t, db, seedUser(t, db, "csv-pending", false).ID, album.ID, 30)
+	db.Model(&pending).Update("status", models.ReviewStatusPending)
+
+	target := fmt.Sprintf("/albums/%d/reviews.csv", album.ID)
+	w := serve(ac.GetAlbumReviewsCSV, http.MethodGet, "/albums/:id/reviews.csv", target, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("csv: %d %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got, want := w.Header().Get("Content-Disposition"), fmt.Sprintf(`attachment; filename="album-%d-reviews.csv"`, album.ID); got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(w.Body.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v\n%s", err, w.Body.String())
+	}
+	header := "username,rating_rhymes,rating_structure,rating_implementation,rating_individuality,atmosphere,final_score,created_at,text"
+	if len(rows) != 2 || strings.Join(rows[0], ",") != header {
+		t.Fatalf("rows = %q", rows)
+	}
+	want := []string{author.Username, "5", "5", "5", "5", "1", "20", review.CreatedAt.UTC().Format(time.RFC3339), text}
+	if fmt.Sprintf("%q", rows[1]) != fmt.Sprintf("%q", want) {
+		t.Errorf("data row = %q, want %q", rows[1], want)
+	}
+}
+
+// Сводка рецензий на треки альбома: у каждого трека своё число и средняя
+// одобренных рецензий, трек без рецензий — с нулями, pending не считается.
+func TestGetAlbumTrackReviews(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	album := seedAlbum(t, db, "track-reviews", models.AlbumStatusApproved)
+	busy := seedTrack(t, db, album.ID, "Busy", 1)
+	single := seedTrack(t, db, album.ID, "Single", 2)
+	silent := seedTrack(t, db, album.ID, "Silent", 3)
+
+	authors := 0
+	review := func(trackID uint, score float64, status models.ReviewStatus) {
+		authors++
+		author := seedUser(t, db, fmt.Sprintf("track-reviewer-%d", authors), false)
+		mustCreate(t, db, &models.Review{UserID: author.ID, TrackID: &trackID, RatingRhymes: 5, RatingStructure: 5,
+			RatingImplementation: 5, RatingIndividuality: 5, AtmosphereMultiplier: 1, FinalScore: score, Status: status})
+	}
+	review(busy.ID, 30, models.ReviewStatusApproved)
+	review(busy.ID, 41, models.ReviewStatusApproved)
+	review(busy.ID, 10, models.ReviewStatusPending)
+	review(single.ID, 25, models.ReviewStatusApproved)
+
+	target := fmt.Sprintf("/albums/%d/track-reviews", album.ID)
+	w := serve(ac.GetAlbumTrackReviews, http.MethodGet, "/albums/:id/track-reviews", target, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("track reviews: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		AlbumID uint                          `json:"album_id"`
+		Tracks  map[string]TrackReviewSummary `json:"tracks"`
+	}
+	decode(t, w, &resp)
+	want := map[string]TrackReviewSummary{
+		fmt.Sprint(busy.ID):   {ReviewsCount: 2, AverageScore: 35.5},
+		fmt.Sprint(single.ID): {ReviewsCount: 1, AverageScore: 25},
+		fmt.Sprint(silent.ID): {ReviewsCount: 0, AverageScore: 0},
+	}
+	if resp.AlbumID != album.ID || fmt.Sprint(resp.Tracks) != fmt.Sprint(want) {
+		t.Errorf("album %d tracks %v, want album %d tracks %v", resp.AlbumID, resp.Tracks, album.ID, want)
+	}
+}
+
+// Место альбома в карточке сдвигается, когда в его жанре появляется альбом
+// с более высокой средней оценкой.
+func TestGetAlbumRankShiftsAfterHigherRatedAlbum(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	album := seedAlbum(t, db, "rank", models.AlbumStatusApproved)
+	seedAlbumReview(t, db, seedUser(t, db, "rank-first", false).ID, album.ID, 40)
+	if err := ac.CalculateAverageRating(album.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	target := fmt.Sprintf("/albums/%d", album.ID)
+	rank := func() models.AlbumRank {
+		t.Helper()
+		w := serve(ac.GetAlbum, http.MethodGet, "/albums/:id", target, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("get album: %d %s", w.Code, w.Body.String())
+		}
+		var got models.Album
+		decode(t, w, &got)
+		if got.Rank == nil {
+			t.Fatalf("no rank in %s", w.Body.String())
+		}
+		return *got.Rank
+	}
+	before := rank()
+	if before.InGenre != 1 || before.GenreTotal != 1 {
+		t.Fatalf("alone in genre: rank %+v", before)
+	}
+
+	better := models.Album{Title: "Better", Artist: "Better", GenreID: album.GenreID, Status: models.AlbumStatusApproved}
+	mustCreate(t, db, &better)
+	seedAlbumReview(t, db, seedUser(t, db, "rank-better", false).ID, better.ID, 50)
+	if err := ac.CalculateAverageRating(better.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	after := rank()
+	want := models.AlbumRank{
+		Overall:      before.Overall + 1,
+		OverallTotal: before.OverallTotal + 1,
+		InGenre:      2,
+		GenreTotal:   2,
+	}
+	if after != want {
+		t.Errorf("after a higher-rated album: rank %+v, want %+v", after, want)
+	}
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"encoding/json"
+
+	"music-review-site/backend/middleware"
+	"music-review-site/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recordAudit пишет запись журнала аудита от имени текущего администратора.
+// Вызывается внутри транзакции операции: ошибка записи откатывает и саму операцию.
+func recordAudit(tx *gorm.DB, c *gin.Context, action, entityType string, entityID uint, details gin.H) error {
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	entry := models.AuditLog{Action: action, EntityType: entityType, EntityID: entityID, Details: string(payload)}
+	if actorID, ok := middleware.GetUserIDFromContext(c); ok {
+		entry.ActorID = &actorID
+	}
+	return tx.Create(&entry).Error
+}
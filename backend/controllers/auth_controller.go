@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"math"
+	"music-review-site/backend/middleware"
 	"music-review-site/backend/models"
 	"music-review-site/backend/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -11,30 +14,47 @@ import (
 
 type AuthController struct {
 	DB *gorm.DB
+	// LoginLimiter блокирует вход после серии неудачных попыток; nil — без ограничений.
+	LoginLimiter *utils.LoginLimiter
+	Sessions     *utils.Sessions
+	// HideEmailConflict — REGISTER_HIDE_EMAIL_CONFLICT: не раскрывать, что занят email.
+	HideEmailConflict bool
 }
 
 // RegisterRequest represents registration request
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
+	Email    string `json:"email" binding:"required"` // Формат проверяется после NormalizeEmail: binding:"email" отверг бы адрес с пробелами по краям
 	Password string `json:"password" binding:"required,min=6"`
 }
 
 // LoginRequest represents login request
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
+	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
+// Сообщения о конфликте при регистрации.
+const (
+	registerConflictUsername = "Username is already taken"
+	registerConflictEmail    = "Email is already registered"
+	registerConflictGeneric  = "User with this email or username already exists"
+)
+
+// registerEmailConflictMessage hides which field collided when
+// HideEmailConflict is on (по умолчанию выключено).
+func (ac *AuthController) registerEmailConflictMessage() string {
+	if ac.HideEmailConflict {
+		return registerConflictGeneric
+	}
+	return registerConflictEmail
+}
+
 // Register handles user registration
 func (ac *AuthController) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
@@ -58,12 +78,45 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
-	// Check if user already exists
-	var existingUser models.User
-	if err := ac.DB.Where("email = ? OR username = ?", req.Email, req.Username).First(&existingUser).Error; err == nil {
+	// Email храним в нижнем регистре; username сравниваем без учёта регистра,
+	// чтобы "Admin" и "admin" не считались разными пользователями.
+	req.Email = utils.NormalizeEmail(req.Email)
+	if !utils.ValidateEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Validation Error",
+			Message: "Invalid email format",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Username публичен, поэтому его занятость сообщаем всегда; занятость email
+	// по REGISTER_HIDE_EMAIL_CONFLICT можно скрыть, чтобы нельзя было перебором
+	// узнать, зарегистрирован ли адрес.
+	var usernameTaken, emailTaken int64
+	requestDB(c, ac.DB).Unscoped().Model(&models.User{}).Where("LOWER(username) = LOWER(?)", req.Username).Count(&usernameTaken)
+	if usernameTaken == 0 {
+		reserved, err := usernameReserved(requestDB(c, ac.DB), req.Username, 0)
+		if err != nil {
+			middleware.Logger(c).Error("failed to check username reservation", "error", err)
+		}
+		if err != nil || reserved {
+			usernameTaken = 1
+		}
+	}
+	if usernameTaken > 0 {
+		c.JSON(http.StatusConflict, utils.ErrorResponse{
+			Error:   "Conflict",
+			Message: registerConflictUsername,
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	requestDB(c, ac.DB).Unscoped().Model(&models.User{}).Where("LOWER(email) = ?", req.Email).Count(&emailTaken)
+	if emailTaken > 0 {
 		c.JSON(http.StatusConflict, utils.ErrorResponse{
 			Error:   "Conflict",
-			Message: "User with this email or username already exists",
+			Message: ac.registerEmailConflictMessage(),
 			Code:    http.StatusConflict,
 		})
 		return
@@ -89,7 +142,17 @@ func (ac *AuthController) Register(c *gin.Context) {
 		IsAdmin:     false,
 	}
 
-	if err := ac.DB.Create(&user).Error; err != nil {
+	if err := requestDB(c, ac.DB).Create(&user).Error; err != nil {
+		// Параллельная регистрация могла пройти проверку выше раньше нас —
+		// уникальный индекс отвечает за итог, клиенту отдаём тот же 409.
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: registerConflictGeneric,
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to create user",
@@ -100,7 +163,8 @@ func (ac *AuthController) Register(c *gin.Context) {
 
 	// Return user (without password)
 	user.Password = ""
-	token, err := utils.GenerateSessionToken(user.ID)
+	user.ShowEmail = true // ответ самому пользователю
+	token, err := ac.Sessions.Generate(user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -121,17 +185,34 @@ func (ac *AuthController) Register(c *gin.Context) {
 func (ac *AuthController) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
+	// Счётчики неудач ведём и по email, и по IP: перебор паролей к одному
+	// аккаунту и перебор аккаунтов с одного адреса блокируются одинаково.
+	limiterKeys := []string{
+		"email:" + utils.NormalizeEmail(req.Email),
+		"ip:" + c.ClientIP(),
+	}
+	if ac.LoginLimiter != nil {
+		if retryAfter := ac.LoginLimiter.RetryAfter(limiterKeys...); retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, utils.ErrorResponse{
+				Error:   "Too Many Requests",
+				Message: "Too many failed login attempts, try again later",
+				Code:    http.StatusTooManyRequests,
+			})
+			return
+		}
+	}
+
 	// Find user by email
 	var user models.User
-	if err := ac.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	if err := requestDB(c, ac.DB).Where("LOWER(email) = ?", utils.NormalizeEmail(req.Email)).First(&user).Error; err != nil {
+		if ac.LoginLimiter != nil {
+			ac.LoginLimiter.Fail(limiterKeys...)
+		}
 		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Invalid email or password",
@@ -142,6 +223,9 @@ func (ac *AuthController) Login(c *gin.Context) {
 
 	// Check password
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		if ac.LoginLimiter != nil {
+			ac.LoginLimiter.Fail(limiterKeys...)
+		}
 		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Invalid email or password",
@@ -150,9 +234,14 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	if ac.LoginLimiter != nil {
+		ac.LoginLimiter.Reset(limiterKeys...)
+	}
+
 	// Return user (without password) and user ID for header
 	user.Password = ""
-	token, err := utils.GenerateSessionToken(user.ID)
+	user.ShowEmail = true // ответ самому пользователю
+	token, err := ac.Sessions.Generate(user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -182,7 +271,7 @@ func (ac *AuthController) GetMe(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := ac.DB.First(&user, userID).Error; err != nil {
+	if err := requestDB(c, ac.DB).First(&user, userID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "User not found",
@@ -192,5 +281,6 @@ func (ac *AuthController) GetMe(c *gin.Context) {
 	}
 
 	user.Password = ""
+	user.ShowEmail = true // ответ самому пользователю
 	c.JSON(http.StatusOK, user)
 }
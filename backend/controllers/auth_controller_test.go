@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"music-review-site/backend/utils"
+)
+
+// TestRegisterConflicts проверяет, что 409 при регистрации называет занятое
+// поле, а с HideEmailConflict не выдаёт, что email уже зарегистрирован.
+func TestRegisterConflicts(t *testing.T) {
+	db := testDB(t)
+	seedUser(t, db, "taken", false)
+	ac := &AuthController{DB: db, Sessions: utils.NewSessions("test-secret", time.Hour)}
+
+	register := func(body string) utils.ErrorResponse {
+		t.Helper()
+		w := serve(ac.Register, http.MethodPost, "/auth/register", "/auth/register", body, nil)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("register %s: status = %d, want 409: %s", body, w.Code, w.Body.String())
+		}
+		var resp utils.ErrorResponse
+		decode(t, w, &resp)
+		return resp
+	}
+
+	if resp := register(`{"username":"fresh","email":"Taken@Example.test","password":"secret1"}`); resp.Message != registerConflictEmail {
+		t.Errorf("email taken: message = %q, want %q", resp.Message, registerConflictEmail)
+	}
+	if resp := register(`{"username":"TAKEN","email":"fresh@example.test","password":"secret1"}`); resp.Message != registerConflictUsername {
+		t.Errorf("username taken: message = %q, want %q", resp.Message, registerConflictUsername)
+	}
+
+	ac.HideEmailConflict = true
+	if resp := register(`{"username":"fresh","email":"taken@example.test","password":"secret1"}`); resp.Message != registerConflictGeneric {
+		t.Errorf("email taken, hidden: message = %q, want %q", resp.Message, registerConflictGeneric)
+	}
+}
+
+// TestLoginNormalizesEmail проверяет, что вход находит аккаунт по email,
+// набранному в другом регистре и с пробелами по краям.
+func TestLoginNormalizesEmail(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "mixedcase", false)
+	hash, err := utils.HashPassword("secret1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&user).Update("password", hash).Error; err != nil {
+		t.Fatal(err)
+	}
+	ac := &AuthController{DB: db, Sessions: utils.NewSessions("test-secret", time.Hour)}
+
+	w := serve(ac.Login, http.MethodPost, "/auth/login", "/auth/login",
+		`{"email":"  MixedCase@Example.TEST ","password":"secret1"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		UserID uint `json:"user_id"`
+	}
+	decode(t, w, &resp)
+	if resp.UserID != user.ID {
+		t.Errorf("user_id = %d, want %d", resp.UserID, user.ID)
+	}
+
+	w = serve(ac.Login, http.MethodPost, "/auth/login", "/auth/login",
+		`{"email":"MIXEDCASE@example.test","password":"wrong12"}`, nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password: status = %d, want 401", w.Code)
+	}
+}
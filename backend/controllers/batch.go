@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"music-review-site/backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchMaxIDs — сколько сущностей можно запросить одним batch-запросом.
+const batchMaxIDs = 100
+
+// batchIDsFromQuery reads ?ids=1,2,3 and answers 400 itself when the list is invalid.
+func batchIDsFromQuery(c *gin.Context) ([]uint, bool) {
+	ids, err := utils.ParseIDList(c.Query("ids"), batchMaxIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return nil, false
+	}
+	return ids, true
+}
+
+// orderByIDs раскладывает загруженные записи в порядке запрошенных ids и
+// возвращает ids, которых не нашлось (удалены или не существуют).
+func orderByIDs[T any](ids []uint, items []T, idOf func(*T) uint) ([]T, []uint) {
+	byID := make(map[uint]int, len(items))
+	for i := range items {
+		byID[idOf(&items[i])] = i
+	}
+
+	ordered := make([]T, 0, len(items))
+	missing := make([]uint, 0)
+	for _, id := range ids {
+		if i, ok := byID[id]; ok {
+			ordered = append(ordered, items[i])
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return ordered, missing
+}
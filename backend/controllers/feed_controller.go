@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"music-review-site/backend/middleware"
+	"music-review-site/backend/models"
+	"music-review-site/backend/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FeedController struct {
+	DB            *gorm.DB
+	PublicSiteURL string // PUBLIC_SITE_URL: адрес фронтенда для ссылок в ленте
+}
+
+// feedItemsLimit и feedDescriptionLimit ограничивают размер RSS-ленты.
+const (
+	feedItemsLimit       = 50
+	feedDescriptionLimit = 500
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// GetReviewsRSS returns the latest approved reviews as an RSS 2.0 feed
+func (fc *FeedController) GetReviewsRSS(c *gin.Context) {
+	siteURL := fc.PublicSiteURL
+	channel := rssChannel{
+		Title:       "Свежие рецензии",
+		Link:        siteURL + "/feed",
+		Description: "Последние одобренные рецензии на альбомы и треки",
+		Language:    "ru",
+		Items:       []rssItem{},
+	}
+
+	query := requestDB(c, fc.DB).Preload("User", withDeletedAuthor).Preload("Album").Preload("Track").Preload("Track.Album").
+		Where("status = ?", models.ReviewStatusApproved)
+
+	// Лента одного альбома: рецензии на сам альбом и на его треки.
+	if albumIDParam := c.Query("album_id"); albumIDParam != "" {
+		albumID, err := strconv.ParseUint(albumIDParam, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "Invalid album_id",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		var album models.Album
+		if err := requestDB(c, fc.DB).Scopes(approvedAlbums).First(&album, albumID).Error; err != nil {
+			c.JSON(http.StatusNotFound, utils.ErrorResponse{
+				Error:   "Not Found",
+				Message: "Album not found",
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		query = query.Where("album_id = ? OR track_id IN (?)", album.ID,
+			requestDB(c, fc.DB).Model(&models.Track{}).Select("id").Where("album_id = ?", album.ID))
+		channel.Title = fmt.Sprintf("Рецензии: %s — %s", album.Artist, album.Title)
+		channel.Link = fmt.Sprintf("%s/albums/%d", siteURL, album.ID)
+	}
+
+	var reviews []models.Review
+	if err := query.Order("created_at DESC").Limit(feedItemsLimit).Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch reviews",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	for _, review := range reviews {
+		var subject, link string
+		switch {
+		case review.Album != nil:
+			subject = review.Album.Artist + " — " + review.Album.Title
+			link = fmt.Sprintf("%s/albums/%d", siteURL, review.Album.ID)
+		case review.Track != nil:
+			subject = review.Track.Album.Artist + " — " + review.Track.Title
+			link = fmt.Sprintf("%s/tracks/%d", siteURL, review.Track.ID)
+		default:
+			continue
+		}
+		channel.Items = append(channel.Items, rssItem{
+			Title:       fmt.Sprintf("%s: рецензия %s", subject, review.User.Username),
+			Link:        link,
+			Description: utils.TruncateRunes(review.Text, feedDescriptionLimit),
+			PubDate:     review.CreatedAt.UTC().Format(time.RFC1123Z),
+			GUID:        rssGUID{Value: fmt.Sprintf("review-%d", review.ID)},
+		})
+	}
+	if len(reviews) > 0 {
+		channel.LastBuildDate = reviews[0].CreatedAt.UTC().Format(time.RFC1123Z)
+	}
+
+	c.Header("Content-Type", "application/rss+xml; charset=utf-8")
+	c.Status(http.StatusOK)
+	c.Writer.WriteString(xml.Header)
+	// encoding/xml экранирует спецсимволы в тексте рецензий.
+	if err := xml.NewEncoder(c.Writer).Encode(rssFeed{Version: "2.0", Channel: channel}); err != nil {
+		middleware.Logger(c).Error("failed to write reviews feed", "error", err)
+	}
+}
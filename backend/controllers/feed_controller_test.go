@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"music-review-site/backend/models"
+)
+
+// Лента альбома — корректный RSS 2.0: канал альбома и по элементу на
+// одобренную рецензию альбома и его треков, без рецензий на модерации.
+func TestGetReviewsRSS(t *testing.T) {
+	db := testDB(t)
+	fc := &FeedController{DB: db, PublicSiteURL: "https://example.test"}
+	album := seedAlbum(t, db, "rss", models.AlbumStatusApproved)
+	track := seedTrack(t, db, album.ID, "RSS Track", 1)
+	author := seedUser(t, db, "rss-author", false)
+
+	albumReview := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	db.Model(&albumReview).Update("text", "Текст <с разметкой> & амперсандом")
+	trackID := track.ID
+	trackReview := models.Review{
+		UserID: author.ID, TrackID: &trackID,
+		RatingRhymes: 5, RatingStructure: 5, RatingImplementation: 5, RatingIndividuality: 5,
+		AtmosphereMultiplier: 1, FinalScore: 40, Status: models.ReviewStatusApproved,
+	}
+	mustCreate(t, db, &trackReview)
+	pending := seedAlbumReview(t, db, seedUser(t, db, "rss-pending", false).ID, album.ID, 40)
+	db.Model(&pending).Update("status", models.ReviewStatusPending)
+	seedAlbumReview(t, db, author.ID, seedAlbum(t, db, "rss-other", models.AlbumStatusApproved).ID, 40)
+
+	w := serve(fc.GetReviewsRSS, http.MethodGet, "/feed/reviews.rss", fmt.Sprintf("/feed/reviews.rss?album_id=%d", album.ID), "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rss: %d %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/rss+xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if !strings.HasPrefix(w.Body.String(), xml.Header) {
+		t.Errorf("no XML declaration: %.80s", w.Body.String())
+	}
+	var feed rssFeed
+	if err := xml.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("parse rss: %v\n%s", err, w.Body.String())
+	}
+	if feed.Version != "2.0" || feed.Channel.Link != fmt.Sprintf("https://example.test/albums/%d", album.ID) {
+		t.Errorf("feed version %q, channel link %q", feed.Version, feed.Channel.Link)
+	}
+
+	items := make(map[string]rssItem, len(feed.Channel.Items))
+	for _, item := range feed.Channel.Items {
+		if _, err := time.Parse(time.RFC1123Z, item.PubDate); err != nil {
+			t.Errorf("item %s: pubDate %q: %v", item.GUID.Value, item.PubDate, err)
+		}
+		items[item.GUID.Value] = item
+	}
+	if len(items) != 2 {
+		t.Fatalf("want 2 items, got %+v", feed.Channel.Items)
+	}
+	albumItem := items[fmt.Sprintf("review-%d", albumReview.ID)]
+	if albumItem.Title != fmt.Sprintf("%s — %s: рецензия %s", album.Artist, album.Title, author.Username) ||
+		albumItem.Link != fmt.Sprintf("https://example.test/albums/%d", album.ID) ||
+		albumItem.Description != "Текст <с разметкой> & амперсандом" {
+		t.Errorf("album item = %+v", albumItem)
+	}
+	if trackItem := items[fmt.Sprintf("review-%d", trackReview.ID)]; trackItem.Link != fmt.Sprintf("https://example.test/tracks/%d", track.ID) {
+		t.Errorf("track item = %+v", trackItem)
+	}
+
+	if code := serve(fc.GetReviewsRSS, http.MethodGet, "/feed/reviews.rss", "/feed/reviews.rss?album_id=abc", "", nil).Code; code != http.StatusBadRequest {
+		t.Errorf("album_id=abc: want 400, got %d", code)
+	}
+}
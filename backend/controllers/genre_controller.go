@@ -1,16 +1,22 @@
 package controllers
 
 import (
+	"fmt"
+	"music-review-site/backend/middleware"
 	"music-review-site/backend/models"
 	"music-review-site/backend/utils"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type GenreController struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Scoring models.ScoringConfig // SCORE_*: для средних оценок в списке альбомов жанра
 }
 
 // CreateGenreRequest represents genre creation request
@@ -25,11 +31,28 @@ type UpdateGenreRequest struct {
 	Description string `json:"description"`
 }
 
+// genreNameCollation — ICU-сортировка по-русски: без неё Postgres с C-локалью
+// упорядочивает кириллицу по кодам символов.
+const genreNameCollation = `name COLLATE "ru-x-icu"`
+
+// genreCountRow — число неудалённых альбомов и треков жанра.
+type genreCountRow struct {
+	GenreID    uint
+	AlbumCount int64
+	TrackCount int64
+}
+
 // GetGenres retrieves list of all genres
 func (gc *GenreController) GetGenres(c *gin.Context) {
 	var genres []models.Genre
 
-	if err := gc.DB.Find(&genres).Error; err != nil {
+	err := requestDB(c, gc.DB).Order(genreNameCollation).Find(&genres).Error
+	if err != nil {
+		// Postgres без ICU не знает коллацию — сортируем как есть, но не падаем.
+		middleware.Logger(c).Warn("genre collation order failed, falling back", "error", err)
+		err = requestDB(c, gc.DB).Order("name").Find(&genres).Error
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch genres",
@@ -38,7 +61,55 @@ func (gc *GenreController) GetGenres(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, genres)
+	if withCounts := c.Query("with_counts"); withCounts != "false" && withCounts != "0" {
+		if err := gc.withRequest(c).attachGenreCounts(genres); err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to count genre releases",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.NewList("genres", genres, len(genres)))
+}
+
+// attachGenreCounts fills AlbumCount/TrackCount with one grouped query.
+func (gc *GenreController) attachGenreCounts(genres []models.Genre) error {
+	if len(genres) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(genres))
+	for i := range genres {
+		ids[i] = genres[i].ID
+	}
+
+	var rows []genreCountRow
+	if err := gc.DB.Raw(`
+		SELECT genres.id AS genre_id,
+			COUNT(DISTINCT albums.id) AS album_count,
+			COUNT(DISTINCT tracks.id) AS track_count
+		FROM genres
+		LEFT JOIN albums ON albums.genre_id = genres.id AND albums.deleted_at IS NULL
+		LEFT JOIN track_genres ON track_genres.genre_id = genres.id
+		LEFT JOIN tracks ON tracks.id = track_genres.track_id AND tracks.deleted_at IS NULL
+		WHERE genres.deleted_at IS NULL AND genres.id IN ?
+		GROUP BY genres.id`, ids).Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[uint]genreCountRow, len(rows))
+	for _, row := range rows {
+		counts[row.GenreID] = row
+	}
+	for i := range genres {
+		row := counts[genres[i].ID]
+		albumCount, trackCount := row.AlbumCount, row.TrackCount
+		genres[i].AlbumCount = &albumCount
+		genres[i].TrackCount = &trackCount
+	}
+	return nil
 }
 
 // GetGenre retrieves genre by ID
@@ -46,7 +117,7 @@ func (gc *GenreController) GetGenre(c *gin.Context) {
 	id := c.Param("id")
 	var genre models.Genre
 
-	if err := gc.DB.First(&genre, id).Error; err != nil {
+	if err := requestDB(c, gc.DB).First(&genre, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Genre not found",
@@ -62,20 +133,34 @@ func (gc *GenreController) GetGenre(c *gin.Context) {
 func (gc *GenreController) CreateGenre(c *gin.Context) {
 	var req CreateGenreRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondBindingError(c, err)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 			Error:   "Bad Request",
-			Message: err.Error(),
+			Message: "Genre name is required",
 			Code:    http.StatusBadRequest,
 		})
 		return
 	}
+	if existing := gc.withRequest(c).findGenreByNameFold(req.Name, 0); existing != nil {
+		respondGenreNameConflict(c, existing)
+		return
+	}
 
 	genre := models.Genre{
 		Name:        req.Name,
-		Description: req.Description,
+		Description: strings.TrimSpace(req.Description),
 	}
 
-	if err := gc.DB.Create(&genre).Error; err != nil {
+	if err := requestDB(c, gc.DB).Create(&genre).Error; err != nil {
+		if utils.IsUniqueViolation(err) {
+			respondGenreNameConflict(c, gc.withRequest(c).findGenreByNameFold(req.Name, 0))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to create genre",
@@ -92,7 +177,7 @@ func (gc *GenreController) UpdateGenre(c *gin.Context) {
 	id := c.Param("id")
 	var genre models.Genre
 
-	if err := gc.DB.First(&genre, id).Error; err != nil {
+	if err := requestDB(c, gc.DB).First(&genre, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Genre not found",
@@ -103,23 +188,27 @@ func (gc *GenreController) UpdateGenre(c *gin.Context) {
 
 	var req UpdateGenreRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
 	// Update fields
-	if req.Name != "" {
-		genre.Name = req.Name
+	if name := strings.TrimSpace(req.Name); name != "" {
+		if existing := gc.withRequest(c).findGenreByNameFold(name, genre.ID); existing != nil {
+			respondGenreNameConflict(c, existing)
+			return
+		}
+		genre.Name = name
 	}
-	if req.Description != "" {
-		genre.Description = req.Description
+	if description := strings.TrimSpace(req.Description); description != "" {
+		genre.Description = description
 	}
 
-	if err := gc.DB.Save(&genre).Error; err != nil {
+	if err := requestDB(c, gc.DB).Save(&genre).Error; err != nil {
+		if utils.IsUniqueViolation(err) {
+			respondGenreNameConflict(c, gc.withRequest(c).findGenreByNameFold(genre.Name, genre.ID))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to update genre",
@@ -131,12 +220,40 @@ func (gc *GenreController) UpdateGenre(c *gin.Context) {
 	c.JSON(http.StatusOK, genre)
 }
 
+// findGenreByNameFold returns another genre with the same name up to case, or nil.
+// Удалённые жанры тоже учитываются: уникальные индексы по name и LOWER(name)
+// их не исключают.
+func (gc *GenreController) findGenreByNameFold(name string, exceptID uint) *models.Genre {
+	var existing models.Genre
+	result := gc.DB.Unscoped().Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Limit(1).Find(&existing)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+	return &existing
+}
+
+// respondGenreNameConflict answers 409, naming the existing genre when it is known.
+func respondGenreNameConflict(c *gin.Context, existing *models.Genre) {
+	message := "Genre with this name already exists"
+	if existing != nil {
+		message = fmt.Sprintf("Genre %q already exists (id %d)", existing.Name, existing.ID)
+		if existing.DeletedAt.Valid {
+			message = fmt.Sprintf("Genre %q already exists among deleted genres (id %d)", existing.Name, existing.ID)
+		}
+	}
+	c.JSON(http.StatusConflict, utils.ErrorResponse{
+		Error:   "Conflict",
+		Message: message,
+		Code:    http.StatusConflict,
+	})
+}
+
 // DeleteGenre deletes a genre
 func (gc *GenreController) DeleteGenre(c *gin.Context) {
 	id := c.Param("id")
 	var genre models.Genre
 
-	if err := gc.DB.First(&genre, id).Error; err != nil {
+	if err := requestDB(c, gc.DB).First(&genre, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Genre not found",
@@ -145,7 +262,56 @@ func (gc *GenreController) DeleteGenre(c *gin.Context) {
 		return
 	}
 
-	if err := gc.DB.Delete(&genre).Error; err != nil {
+	var albumCount, trackLinkCount int64
+	requestDB(c, gc.DB).Model(&models.Album{}).Where("genre_id = ?", genre.ID).Count(&albumCount)
+	requestDB(c, gc.DB).Model(&models.TrackGenre{}).Where("genre_id = ?", genre.ID).Count(&trackLinkCount)
+
+	// Без reassign_to жанр с привязанными релизами не удаляем: у альбомов
+	// genre_id NOT NULL, и они остались бы с пустым жанром и выпали из фильтра.
+	reassignParam := strings.TrimSpace(c.Query("reassign_to"))
+	if reassignParam == "" {
+		if albumCount > 0 || trackLinkCount > 0 {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: fmt.Sprintf("Genre is used by %d albums and %d tracks; pass reassign_to to move them", albumCount, trackLinkCount),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		if err := requestDB(c, gc.DB).Delete(&genre).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to delete genre",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Genre deleted successfully",
+		})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(reassignParam, 10, 32)
+	var target models.Genre
+	if err != nil || uint(targetID) == genre.ID || requestDB(c, gc.DB).First(&target, targetID).Error != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "reassign_to must be the ID of another existing genre",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var moved genreMoveResult
+	err = requestDB(c, gc.DB).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if moved, err = moveGenreReferences(tx, genre.ID, target.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&genre).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to delete genre",
@@ -155,7 +321,377 @@ func (gc *GenreController) DeleteGenre(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Genre deleted successfully",
+		"message":                "Genre deleted successfully",
+		"reassigned_to":          target.ID,
+		"albums_reassigned":      moved.Albums,
+		"track_links_reassigned": moved.TrackLinks,
+		"track_links_merged":     moved.TrackLinksMerged,
+	})
+}
+
+// MergeGenre moves everything from a duplicate genre into target and deletes
+// the duplicate (admin only): POST /admin/genres/:id/merge-into/:target.
+func (gc *GenreController) MergeGenre(c *gin.Context) {
+	var source, target models.Genre
+	if err := requestDB(c, gc.DB).First(&source, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Genre not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if err := requestDB(c, gc.DB).First(&target, c.Param("target")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Target genre not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	if source.ID == target.ID {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Cannot merge a genre into itself",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var moved genreMoveResult
+	err := requestDB(c, gc.DB).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if moved, err = moveGenreReferences(tx, source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := tx.Delete(&source).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, "genre.merge", "genres", source.ID, gin.H{
+			"source_name":        source.Name,
+			"target_id":          target.ID,
+			"target_name":        target.Name,
+			"albums_moved":       moved.Albums,
+			"track_links_moved":  moved.TrackLinks,
+			"track_links_merged": moved.TrackLinksMerged,
+		})
+	})
+	if err != nil {
+		middleware.Logger(c).Error("failed to merge genres", "source_id", source.ID, "target_id", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to merge genres",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Genres merged successfully",
+		"source_id":          source.ID,
+		"target_id":          target.ID,
+		"albums_moved":       moved.Albums,
+		"track_links_moved":  moved.TrackLinks,
+		"track_links_merged": moved.TrackLinksMerged,
+	})
+}
+
+// genreMoveResult — сколько строк перенесено на другой жанр.
+type genreMoveResult struct {
+	Albums           int64
+	TrackLinks       int64
+	TrackLinksMerged int64 // Связи, удалённые, потому что у трека целевой жанр уже был
+}
+
+// moveGenreReferences repoints albums and track_genres from sourceID to targetID
+// inside tx. Сам исходный жанр не удаляет — это решает вызывающий.
+func moveGenreReferences(tx *gorm.DB, sourceID, targetID uint) (genreMoveResult, error) {
+	var moved genreMoveResult
+
+	result := tx.Model(&models.Album{}).Where("genre_id = ?", sourceID).Update("genre_id", targetID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.Albums = result.RowsAffected
+
+	// Треки, у которых целевой жанр уже есть, просто теряют старую связь —
+	// иначе сработал бы уникальный индекс idx_track_genre_pair.
+	result = tx.Where("genre_id = ? AND track_id IN (?)", sourceID,
+		tx.Model(&models.TrackGenre{}).Select("track_id").Where("genre_id = ?", targetID)).
+		Delete(&models.TrackGenre{})
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.TrackLinksMerged = result.RowsAffected
+
+	result = tx.Model(&models.TrackGenre{}).Where("genre_id = ?", sourceID).Update("genre_id", targetID)
+	if result.Error != nil {
+		return moved, result.Error
+	}
+	moved.TrackLinks = result.RowsAffected
+	return moved, nil
+}
+
+// Размер секций обзора жанра: по умолчанию и максимум для limit.
+const (
+	genreOverviewLimitDefault = 5
+	genreOverviewLimitMax     = 20
+)
+
+// GetGenreOverview returns data for a genre landing page: the genre with album
+// and track counts, top albums by average rating and top tracks by likes over
+// the last 7 days. Каждая секция — один ограниченный limit запрос.
+func (gc *GenreController) GetGenreOverview(c *gin.Context) {
+	var genre models.Genre
+	if err := requestDB(c, gc.DB).First(&genre, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Genre not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(genreOverviewLimitDefault)))
+	if err != nil || limit < 1 || limit > genreOverviewLimitMax {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: fmt.Sprintf("limit must be between 1 and %d", genreOverviewLimitMax),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	genres := []models.Genre{genre}
+	if err := gc.withRequest(c).attachGenreCounts(genres); err != nil {
+		gc.respondOverviewError(c)
+		return
+	}
+
+	topAlbums := []models.Album{}
+	if err := requestDB(c, gc.DB).Model(&models.Album{}).Preload("Genre").
+		Scopes(approvedAlbums).
+		Where("albums.genre_id = ?", genre.ID).
+		Order("albums.average_rating DESC, albums.id ASC").
+		Limit(limit).Find(&topAlbums).Error; err != nil {
+		gc.respondOverviewError(c)
+		return
+	}
+
+	var likeRows []struct {
+		TrackID uint
+		Likes   int64
+	}
+	if err := requestDB(c, gc.DB).Model(&models.TrackLike{}).
+		Select("track_likes.track_id, COUNT(*) AS likes").
+		Joins("JOIN track_genres ON track_genres.track_id = track_likes.track_id AND track_genres.genre_id = ?", genre.ID).
+		Joins("JOIN tracks ON tracks.id = track_likes.track_id AND tracks.deleted_at IS NULL").
+		Joins("JOIN albums ON albums.id = tracks.album_id AND albums.deleted_at IS NULL AND albums.status = ?", models.AlbumStatusApproved).
+		Where("track_likes.created_at >= ?", time.Now().Add(-7*24*time.Hour)).
+		Group("track_likes.track_id").
+		Order("likes DESC, track_likes.track_id ASC").
+		Limit(limit).Scan(&likeRows).Error; err != nil {
+		gc.respondOverviewError(c)
+		return
+	}
+
+	ids := make([]uint, len(likeRows))
+	likes := make(map[uint]int64, len(likeRows))
+	for i, row := range likeRows {
+		ids[i] = row.TrackID
+		likes[row.TrackID] = row.Likes
+	}
+	var tracks []models.Track
+	if len(ids) > 0 {
+		if err := requestDB(c, gc.DB).Preload("Album").Preload("Genres").Where("id IN ?", ids).Find(&tracks).Error; err != nil {
+			gc.respondOverviewError(c)
+			return
+		}
+	}
+	topTracks, _ := orderByIDs(ids, tracks, func(t *models.Track) uint { return t.ID })
+	for i := range topTracks {
+		count := likes[topTracks[i].ID]
+		topTracks[i].RecentLikes = &count
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"genre":       genres[0],
+		"top_albums":  topAlbums,
+		"top_tracks":  topTracks,
+		"album_count": genres[0].AlbumCount,
+		"track_count": genres[0].TrackCount,
+		"limit":       limit,
+	})
+}
+
+func (gc *GenreController) respondOverviewError(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+		Error:   "Internal Server Error",
+		Message: "Failed to fetch genre overview",
+		Code:    http.StatusInternalServerError,
 	})
 }
 
+// genreTopMinReviewsDefault — сколько одобренных рецензий нужно, чтобы попасть
+// в топ жанра: одна восторженная рецензия не должна выводить релиз на первое место.
+const genreTopMinReviewsDefault = 3
+
+// genreTopRow — id релиза и число его одобренных рецензий.
+type genreTopRow struct {
+	ID          uint
+	ReviewCount int64
+}
+
+// GetGenreAlbums lists albums of one genre with the same sorting, search and
+// pagination as GET /albums.
+func (gc *GenreController) GetGenreAlbums(c *gin.Context) {
+	var genre models.Genre
+	if err := requestDB(c, gc.DB).First(&genre, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Genre not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	(&AlbumController{DB: gc.DB, Scoring: gc.Scoring}).listAlbums(c, strconv.FormatUint(uint64(genre.ID), 10))
+}
+
+// GetGenreTop returns the highest-rated albums or tracks of a genre
+func (gc *GenreController) GetGenreTop(c *gin.Context) {
+	var genre models.Genre
+	if err := requestDB(c, gc.DB).First(&genre, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Genre not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	itemType := c.DefaultQuery("type", "albums")
+	if itemType != "albums" && itemType != "tracks" {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "type must be albums or tracks",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	minReviews, err := strconv.Atoi(c.DefaultQuery("min_reviews", strconv.Itoa(genreTopMinReviewsDefault)))
+	if err != nil || minReviews < 1 {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "min_reviews must be a positive integer",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	page := utils.ParsePage(c)
+
+	var (
+		total int64
+		rows  []genreTopRow
+		body  gin.H
+	)
+	if itemType == "albums" {
+		// Альбомы относятся к жанру через albums.genre_id.
+		ranked := func() *gorm.DB {
+			reviewCounts := requestDB(c, gc.DB).Model(&models.Review{}).
+				Select("album_id, COUNT(*) AS review_count").
+				Where("status = ? AND album_id IS NOT NULL", models.ReviewStatusApproved).
+				Group("album_id")
+			return requestDB(c, gc.DB).Model(&models.Album{}).
+				Joins("JOIN (?) AS rc ON rc.album_id = albums.id", reviewCounts).
+				Scopes(approvedAlbums).
+				Where("albums.genre_id = ? AND rc.review_count >= ?", genre.ID, minReviews)
+		}
+		ranked().Count(&total)
+		err = ranked().Select("albums.id, rc.review_count").
+			Order("albums.average_rating DESC, rc.review_count DESC, albums.id ASC").
+			Offset(page.Offset()).Limit(page.Size).Scan(&rows).Error
+		var albums []models.Album
+		if err == nil {
+			albums, err = gc.withRequest(c).loadTopAlbums(rows)
+		}
+		body = utils.NewPaginated("items", albums, total, page).Fields()
+	} else {
+		// Треки относятся к жанру через track_genres; трек альбома на модерации не показываем.
+		ranked := func() *gorm.DB {
+			reviewCounts := requestDB(c, gc.DB).Model(&models.Review{}).
+				Select("track_id, COUNT(*) AS review_count").
+				Where("status = ? AND track_id IS NOT NULL", models.ReviewStatusApproved).
+				Group("track_id")
+			return requestDB(c, gc.DB).Model(&models.Track{}).
+				Joins("JOIN (?) AS rc ON rc.track_id = tracks.id", reviewCounts).
+				Joins("JOIN track_genres ON track_genres.track_id = tracks.id AND track_genres.genre_id = ?", genre.ID).
+				Joins("JOIN albums ON albums.id = tracks.album_id AND albums.deleted_at IS NULL AND albums.status = ?", models.AlbumStatusApproved).
+				Where("rc.review_count >= ?", minReviews)
+		}
+		ranked().Count(&total)
+		err = ranked().Select("tracks.id, rc.review_count").
+			Order("tracks.average_rating DESC, rc.review_count DESC, tracks.id ASC").
+			Offset(page.Offset()).Limit(page.Size).Scan(&rows).Error
+		var tracks []models.Track
+		if err == nil {
+			tracks, err = gc.withRequest(c).loadTopTracks(rows)
+		}
+		body = utils.NewPaginated("items", tracks, total, page).Fields()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch genre top",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	body["genre"] = genre
+	body["type"] = itemType
+	body["min_reviews"] = minReviews
+	c.JSON(http.StatusOK, body)
+}
+
+func (gc *GenreController) loadTopAlbums(rows []genreTopRow) ([]models.Album, error) {
+	ids := make([]uint, len(rows))
+	counts := make(map[uint]int64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+		counts[row.ID] = row.ReviewCount
+	}
+	var albums []models.Album
+	if len(ids) > 0 {
+		if err := gc.DB.Preload("Genre").Where("id IN ?", ids).Find(&albums).Error; err != nil {
+			return nil, err
+		}
+	}
+	ordered, _ := orderByIDs(ids, albums, func(a *models.Album) uint { return a.ID })
+	for i := range ordered {
+		ordered[i].ApprovedReviewsCount = counts[ordered[i].ID]
+	}
+	return ordered, nil
+}
+
+func (gc *GenreController) loadTopTracks(rows []genreTopRow) ([]models.Track, error) {
+	ids := make([]uint, len(rows))
+	counts := make(map[uint]int64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+		counts[row.ID] = row.ReviewCount
+	}
+	var tracks []models.Track
+	if len(ids) > 0 {
+		if err := gc.DB.Preload("Album").Preload("Genres").Where("id IN ?", ids).Find(&tracks).Error; err != nil {
+			return nil, err
+		}
+	}
+	ordered, _ := orderByIDs(ids, tracks, func(t *models.Track) uint { return t.ID })
+	for i := range ordered {
+		count := counts[ordered[i].ID]
+		ordered[i].ReviewCount = &count
+	}
+	return ordered, nil
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"music-review-site/backend/models"
+)
+
+// Контроллер собирается на своей тестовой БД, без глобального database.DB
+// и без routes: всё, что ему нужно, — поле DB.
+func TestGenreControllerOnOwnDB(t *testing.T) {
+	gc := &GenreController{DB: testDB(t)}
+
+	w := serve(gc.CreateGenre, http.MethodPost, "/genres", "/genres", `{"name": " Synthwave ", "description": "ретро"}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var created models.Genre
+	decode(t, w, &created)
+	if created.ID == 0 || created.Name != "Synthwave" {
+		t.Fatalf("created genre: %+v", created)
+	}
+
+	w = serve(gc.GetGenre, http.MethodGet, "/genres/:id", fmt.Sprintf("/genres/%d", created.ID), "", nil)
+	var loaded models.Genre
+	decode(t, w, &loaded)
+	if w.Code != http.StatusOK || loaded.Name != "Synthwave" || loaded.Description != "ретро" {
+		t.Errorf("get: %d %+v", w.Code, loaded)
+	}
+
+	w = serve(gc.CreateGenre, http.MethodPost, "/genres", "/genres", `{"name": "SYNTHWAVE"}`, nil)
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate name up to case: want 409, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// В топ жанра попадают только альбомы и треки, у которых одобренных рецензий
+// не меньше min_reviews; рецензии на модерации не засчитываются.
+func TestGetGenreTopMinReviews(t *testing.T) {
+	db := testDB(t)
+	gc := &GenreController{DB: db, Scoring: models.DefaultScoring()}
+	popular := seedAlbum(t, db, "top-popular", models.AlbumStatusApproved)
+	fluke := models.Album{Title: "top-fluke", Artist: "Fluke", GenreID: popular.GenreID, Status: models.AlbumStatusApproved}
+	mustCreate(t, db, &fluke)
+	popularTrack := seedTrack(t, db, popular.ID, "Popular", 1)
+	flukeTrack := seedTrack(t, db, fluke.ID, "Fluke", 1)
+	for _, track := range []models.Track{popularTrack, flukeTrack} {
+		mustCreate(t, db, &models.TrackGenre{TrackID: track.ID, GenreID: popular.GenreID})
+	}
+
+	review := func(i int, albumID, trackID *uint, score float64, status models.ReviewStatus) {
+		author := seedUser(t, db, fmt.Sprintf("top-author-%d", i), false)
+		mustCreate(t, db, &models.Review{
+			UserID: author.ID, AlbumID: albumID, TrackID: trackID,
+			RatingRhymes: 5, RatingStructure: 5, RatingImplementation: 5, RatingIndividuality: 5,
+			AtmosphereMultiplier: 1, FinalScore: score, Status: status,
+		})
+	}
+	review(1, &popular.ID, nil, 40, models.ReviewStatusApproved)
+	review(2, &popular.ID, nil, 50, models.ReviewStatusApproved)
+	review(3, &fluke.ID, nil, 90, models.ReviewStatusApproved)
+	review(4, &fluke.ID, nil, 90, models.ReviewStatusPending)
+	review(5, nil, &popularTrack.ID, 40, models.ReviewStatusApproved)
+	review(6, nil, &popularTrack.ID, 50, models.ReviewStatusApproved)
+	review(7, nil, &flukeTrack.ID, 90, models.ReviewStatusApproved)
+	review(8, nil, &flukeTrack.ID, 90, models.ReviewStatusPending)
+	db.Model(&fluke).Update("average_rating", 90)
+	db.Model(&flukeTrack).Update("average_rating", 90)
+
+	for itemType, want := range map[string]uint{"albums": popular.ID, "tracks": popularTrack.ID} {
+		target := fmt.Sprintf("/genres/%d/top?type=%s&min_reviews=2", popular.GenreID, itemType)
+		w := serve(gc.GetGenreTop, http.MethodGet, "/genres/:id/top", target, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", target, w.Code, w.Body.String())
+		}
+		var body struct {
+			Items []struct {
+				ID uint `json:"id"`
+			} `json:"items"`
+			Total int64 `json:"total"`
+		}
+		decode(t, w, &body)
+		if body.Total != 1 || len(body.Items) != 1 || body.Items[0].ID != want {
+			t.Errorf("%s: items %+v (total %d), want only %d", itemType, body.Items, body.Total, want)
+		}
+	}
+}
+
+// Слияние жанров переносит альбомы и связи треков, связь-дубль у трека, где
+// целевой жанр уже есть, удаляет, а запись аудита появляется в той же транзакции.
+func TestMergeGenre(t *testing.T) {
+	db := testDB(t)
+	gc := &GenreController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "merge-genre-admin", true)
+	sourceAlbum := seedAlbum(t, db, "merge-source", models.AlbumStatusApproved)
+	targetAlbum := seedAlbum(t, db, "merge-target", models.AlbumStatusApproved)
+	sourceID, targetID := sourceAlbum.GenreID, targetAlbum.GenreID
+
+	onlySource := seedTrack(t, db, targetAlbum.ID, "Only source", 1)
+	both := seedTrack(t, db, targetAlbum.ID, "Both genres", 2)
+	mustCreate(t, db, &models.TrackGenre{TrackID: onlySource.ID, GenreID: sourceID})
+	mustCreate(t, db, &models.TrackGenre{TrackID: both.ID, GenreID: sourceID})
+	mustCreate(t, db, &models.TrackGenre{TrackID: both.ID, GenreID: targetID})
+
+	target := fmt.Sprintf("/admin/genres/%d/merge-into/%d", sourceID, targetID)
+	w := serve(gc.MergeGenre, http.MethodPost, "/admin/genres/:id/merge-into/:target", target, "", &admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST %s: %d %s", target, w.Code, w.Body.String())
+	}
+	var body struct {
+		AlbumsMoved      int64 `json:"albums_moved"`
+		TrackLinksMoved  int64 `json:"track_links_moved"`
+		TrackLinksMerged int64 `json:"track_links_merged"`
+	}
+	decode(t, w, &body)
+	if body.AlbumsMoved != 1 || body.TrackLinksMoved != 1 || body.TrackLinksMerged != 1 {
+		t.Errorf("response %+v, want 1 album, 1 link moved, 1 merged", body)
+	}
+
+	var stored models.Album
+	db.First(&stored, sourceAlbum.ID)
+	if stored.GenreID != targetID {
+		t.Errorf("album genre %d, want %d", stored.GenreID, targetID)
+	}
+	for _, track := range []models.Track{onlySource, both} {
+		var links []models.TrackGenre
+		db.Where("track_id = ?", track.ID).Find(&links)
+		if len(links) != 1 || links[0].GenreID != targetID {
+			t.Errorf("track %q links %+v, want only genre %d", track.Title, links, targetID)
+		}
+	}
+	if err := db.First(&models.Genre{}, sourceID).Error; err == nil {
+		t.Error("source genre not deleted")
+	}
+
+	var entry models.AuditLog
+	if err := db.Where("action = ? AND entity_id = ?", "genre.merge", sourceID).First(&entry).Error; err != nil {
+		t.Fatalf("audit entry: %v", err)
+	}
+	var details map[string]interface{}
+	if err := json.Unmarshal([]byte(entry.Details), &details); err != nil {
+		t.Fatal(err)
+	}
+	if entry.ActorID == nil || *entry.ActorID != admin.ID || details["target_id"] != float64(targetID) || details["albums_moved"] != float64(1) {
+		t.Errorf("audit entry %+v, details %v", entry, details)
+	}
+}
+
+// GET /genres/:id/albums отдаёт только одобренные альбомы этого жанра и
+// считает total по ним же; несуществующий жанр — 404.
+func TestGetGenreAlbums(t *testing.T) {
+	db := testDB(t)
+	gc := &GenreController{DB: db, Scoring: models.DefaultScoring()}
+	first := seedAlbum(t, db, "genre-albums-first", models.AlbumStatusApproved)
+	second := models.Album{Title: "Second", Artist: "Second", GenreID: first.GenreID, Status: models.AlbumStatusApproved}
+	mustCreate(t, db, &second)
+	pending := models.Album{Title: "Pending", Artist: "Pending", GenreID: first.GenreID, Status: models.AlbumStatusPending}
+	mustCreate(t, db, &pending)
+	other := seedAlbum(t, db, "genre-albums-other", models.AlbumStatusApproved)
+
+	target := fmt.Sprintf("/genres/%d/albums?page_size=100", first.GenreID)
+	w := serve(gc.GetGenreAlbums, http.MethodGet, "/genres/:id/albums", target, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("genre albums: %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Albums []models.Album `json:"albums"`
+		Total  int64          `json:"total"`
+	}
+	decode(t, w, &body)
+	ids := make(map[uint]bool, len(body.Albums))
+	for _, album := range body.Albums {
+		ids[album.ID] = true
+		if album.GenreID != first.GenreID {
+			t.Errorf("album %d of genre %d listed", album.ID, album.GenreID)
+		}
+	}
+	if len(ids) != 2 || !ids[first.ID] || !ids[second.ID] || body.Total != 2 {
+		t.Errorf("listed %v (total %d), want %d and %d; other genre %d, pending %d",
+			ids, body.Total, first.ID, second.ID, other.ID, pending.ID)
+	}
+
+	missing := fmt.Sprintf("/genres/%d/albums", other.GenreID+1000)
+	if w := serve(gc.GetGenreAlbums, http.MethodGet, "/genres/:id/albums", missing, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing genre: want 404, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"music-review-site/backend/database"
+	"music-review-site/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB открывает БД из TEST_DATABASE_DSN, накатывает миграции и возвращает
+// транзакцию, которая откатывается после теста. Без DSN тест пропускается:
+// Postgres есть только в CI и в docker-compose.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if _, err := database.ApplyMigrations(db, 0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// serve прогоняет один запрос через handler, зарегистрированный на pattern.
+// Непустой user кладётся в контекст так же, как это делает AuthMiddleware.
+func serve(handler gin.HandlerFunc, method, pattern, target, body string, user *models.User) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Handle(method, pattern, func(c *gin.Context) {
+		if user != nil {
+			c.Set("user", *user)
+			c.Set("user_id", user.ID)
+		}
+		c.Next()
+	}, handler)
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// decode разбирает JSON-ответ в dest и валит тест, если тело не разбирается.
+func decode(t *testing.T, w *httptest.ResponseRecorder, dest interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), dest); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+// countQueries возвращает, сколько SQL-запросов db выполнила внутри fn:
+// чтения (Find, Count, Scan, Raw) и Exec.
+func countQueries(t *testing.T, db *gorm.DB, fn func()) int {
+	t.Helper()
+	const name = "test:count_queries"
+	count := 0
+	inc := func(*gorm.DB) { count++ }
+	callbacks := db.Callback()
+	if err := callbacks.Query().After("gorm:query").Register(name, inc); err != nil {
+		t.Fatal(err)
+	}
+	if err := callbacks.Row().After("gorm:row").Register(name, inc); err != nil {
+		t.Fatal(err)
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register(name, inc); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		callbacks.Query().Remove(name)
+		callbacks.Row().Remove(name)
+		callbacks.Raw().Remove(name)
+	}()
+	fn()
+	return count
+}
+
+// mustCreate сохраняет фикстуру и валит тест при ошибке.
+func mustCreate(t *testing.T, db *gorm.DB, value interface{}) {
+	t.Helper()
+	if err := db.Create(value).Error; err != nil {
+		t.Fatalf("create %T: %v", value, err)
+	}
+}
+
+// seedAlbum создаёт жанр и альбом в заданном статусе модерации.
+func seedAlbum(t *testing.T, db *gorm.DB, title string, status models.AlbumStatus) models.Album {
+	t.Helper()
+	genre := models.Genre{Name: "test-genre-" + title}
+	mustCreate(t, db, &genre)
+	album := models.Album{Title: title, Artist: "Artist " + title, GenreID: genre.ID, Status: status}
+	mustCreate(t, db, &album)
+	return album
+}
+
+// seedTrack создаёт трек альбома с заданным номером.
+func seedTrack(t *testing.T, db *gorm.DB, albumID uint, title string, number int) models.Track {
+	t.Helper()
+	track := models.Track{AlbumID: albumID, Title: title, TrackNumber: &number}
+	mustCreate(t, db, &track)
+	return track
+}
+
+// seedUser создаёт пользователя; admin — с правами администратора.
+func seedUser(t *testing.T, db *gorm.DB, username string, admin bool) models.User {
+	t.Helper()
+	user := models.User{Username: username, Email: username + "@example.test", Password: "x", IsAdmin: admin}
+	mustCreate(t, db, &user)
+	return user
+}
+
+// seedAlbumReview создаёт одобренную рецензию на альбом с заданной итоговой оценкой.
+func seedAlbumReview(t *testing.T, db *gorm.DB, userID, albumID uint, finalScore float64) models.Review {
+	t.Helper()
+	review := models.Review{
+		UserID:               userID,
+		AlbumID:              &albumID,
+		RatingRhymes:         5,
+		RatingStructure:      5,
+		RatingImplementation: 5,
+		RatingIndividuality:  5,
+		AtmosphereMultiplier: 1,
+		FinalScore:           finalScore,
+		Status:               models.ReviewStatusApproved,
+	}
+	mustCreate(t, db, &review)
+	return review
+}
+
+// checkLikeCycle проверяет ответы лайка на pattern (…/:id/like): новый лайк —
+// 201, повторный — 200 «Already liked», снятие — 200; везде liked и likes_count.
+func checkLikeCycle(t *testing.T, like, unlike gin.HandlerFunc, pattern, target string, user *models.User) {
+	t.Helper()
+	type likeResponse struct {
+		Message    string `json:"message"`
+		Liked      bool   `json:"liked"`
+		LikesCount int64  `json:"likes_count"`
+	}
+	steps := []struct {
+		name    string
+		handler gin.HandlerFunc
+		method  string
+		code    int
+		want    likeResponse
+	}{
+		{"like", like, http.MethodPost, http.StatusCreated, likeResponse{Liked: true, LikesCount: 1}},
+		{"like again", like, http.MethodPost, http.StatusOK, likeResponse{Message: "Already liked", Liked: true, LikesCount: 1}},
+		{"unlike", unlike, http.MethodDelete, http.StatusOK, likeResponse{Liked: false, LikesCount: 0}},
+	}
+	for _, step := range steps {
+		w := serve(step.handler, step.method, pattern, target, "", user)
+		if w.Code != step.code {
+			t.Fatalf("%s %s: want %d, got %d %s", step.name, target, step.code, w.Code, w.Body.String())
+		}
+		var got likeResponse
+		decode(t, w, &got)
+		if step.want.Message != "" && got.Message != step.want.Message {
+			t.Errorf("%s: message %q, want %q", step.name, got.Message, step.want.Message)
+		}
+		if got.Liked != step.want.Liked || got.LikesCount != step.want.LikesCount {
+			t.Errorf("%s: liked %v, likes_count %d; want %v, %d", step.name, got.Liked, got.LikesCount, step.want.Liked, step.want.LikesCount)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// requestDB привязывает запросы к контексту HTTP-запроса: при отключении клиента
+// или истечении таймаута (middleware.RequestTimeout) PostgreSQL прерывает запрос,
+// а не дорабатывает его впустую. Пересчёт средних после записи идёт через
+// исходный DB — он должен завершиться, даже если клиент уже ушёл.
+func requestDB(c *gin.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(c.Request.Context())
+}
+
+// withRequest возвращают копию контроллера с DB, привязанной к запросу, чтобы
+// вспомогательные методы (attach*, search*, Calculate*) тоже прерывались.
+
+func (ac *AlbumController) withRequest(c *gin.Context) *AlbumController {
+	scoped := *ac
+	scoped.DB = requestDB(c, ac.DB)
+	return &scoped
+}
+
+func (gc *GenreController) withRequest(c *gin.Context) *GenreController {
+	scoped := *gc
+	scoped.DB = requestDB(c, gc.DB)
+	return &scoped
+}
+
+func (rc *ReviewController) withRequest(c *gin.Context) *ReviewController {
+	scoped := *rc
+	scoped.DB = requestDB(c, rc.DB)
+	return &scoped
+}
+
+func (sc *SearchController) withRequest(c *gin.Context) *SearchController {
+	scoped := *sc
+	scoped.DB = requestDB(c, sc.DB)
+	return &scoped
+}
+
+func (tc *TrackController) withRequest(c *gin.Context) *TrackController {
+	scoped := *tc
+	scoped.DB = requestDB(c, tc.DB)
+	return &scoped
+}
+
+func (uc *UserController) withRequest(c *gin.Context) *UserController {
+	scoped := *uc
+	scoped.DB = requestDB(c, uc.DB)
+	return &scoped
+}
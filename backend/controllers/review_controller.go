@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"math"
 	"music-review-site/backend/middleware"
 	"music-review-site/backend/models"
 	"music-review-site/backend/utils"
@@ -16,16 +19,12 @@ import (
 	"gorm.io/gorm"
 )
 
-// convertAtmosphereToMultiplier converts atmosphere rating (1-10) to multiplier (1.0000-1.6072)
-// Formula: multiplier = 1.0000 + (rating - 1) * 0.0674666...
-// This ensures max score of 90 when all ratings are 10
-func convertAtmosphereToMultiplier(rating int) float64 {
-	step := 0.6072 / 9.0
-	return 1.0000 + float64(rating-1)*step
-}
-
 type ReviewController struct {
-	DB *gorm.DB
+	DB             *gorm.DB
+	ReviewsPerHour int                  // REVIEW_RATE_LIMIT_PER_HOUR; 0 или меньше отключает лимит
+	Scoring        models.ScoringConfig // SCORE_*: формула итоговой оценки
+	WebhookURL     string               // REVIEW_WEBHOOK_URL; пусто — вебхук не отправляется
+	PublicSiteURL  string               // PUBLIC_SITE_URL: ссылка на рецензию в вебхуке
 }
 
 // reviewSortColumns — белый список колонок для ORDER BY по рецензиям.
@@ -41,42 +40,179 @@ var reviewSortColumns = map[string]string{
 func (rc *ReviewController) recalcReviewTargets(albumID, trackID *uint) {
 	if albumID != nil {
 		if err := (&AlbumController{DB: rc.DB}).CalculateAverageRating(*albumID); err != nil {
-			log.Printf("Warning: failed to recalc album %d average: %v", *albumID, err)
+			slog.Warn("failed to recalc album average", "album_id", *albumID, "error", err)
 		}
 	}
 	if trackID != nil {
 		if err := (&TrackController{DB: rc.DB}).CalculateAverageRating(*trackID); err != nil {
-			log.Printf("Warning: failed to recalc track %d average: %v", *trackID, err)
+			slog.Warn("failed to recalc track average", "track_id", *trackID, "error", err)
 		}
 	}
 }
 
+// withDeletedAuthor подгружает автора рецензии даже после удаления аккаунта:
+// одобренные рецензии анонимизированных пользователей показываются от deleted_user_<id>.
+func withDeletedAuthor(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// withModeratorName подгружает модератора рецензии только ради username
+// (moderator_username в ответе); удалённый модератор тоже находится.
+func withModeratorName(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Select("id", "username")
+}
+
+// hideModerator убирает из ответа, кто проверил рецензию (moderated_by и
+// moderator_username): их видят администратор и автор рецензии, остальным
+// остаётся только moderated_at.
+func hideModerator(c *gin.Context, review *models.Review) {
+	if viewer, ok := middleware.GetUserFromContext(c); ok && (viewer.IsAdmin || viewer.ID == review.UserID) {
+		return
+	}
+	review.ModeratedBy = nil
+	review.Moderator = nil
+}
+
+// currentUserReview ищет рецензию текущего пользователя на альбом или трек
+// (column — album_id или track_id) одним запросом: по ux_reviews_user_album/track
+// она у пользователя максимум одна, статус не важен — свою pending тоже можно править.
+// Без авторизации возвращает nil, nil, и поля reviewed_by_me в ответе нет.
+func currentUserReview(c *gin.Context, db *gorm.DB, column string, targetID uint) (reviewed *bool, reviewID *uint) {
+	user, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		return nil, nil
+	}
+	var ids []uint
+	if err := db.Model(&models.Review{}).
+		Where("user_id = ? AND "+column+" = ?", user.ID, targetID).
+		Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		middleware.Logger(c).Warn("failed to check current user review", column, targetID, "error", err)
+		return nil, nil
+	}
+	found := len(ids) > 0
+	if found {
+		reviewID = &ids[0]
+	}
+	return &found, reviewID
+}
+
+// reviewWebhookPayload — то, что уходит во внешний вебхук. Отдельная структура,
+// а не models.Review: в JSON пользователя есть email, наружу его отдавать нельзя.
+type reviewWebhookPayload struct {
+	Event  string `json:"event"`
+	Review struct {
+		ID          uint      `json:"id"`
+		Text        string    `json:"text"`
+		FinalScore  float64   `json:"final_score"`
+		HasSpoilers bool      `json:"has_spoilers"`
+		CreatedAt   time.Time `json:"created_at"`
+		URL         string    `json:"url"`
+	} `json:"review"`
+	User struct {
+		ID       uint   `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Album *reviewWebhookTarget `json:"album,omitempty"`
+	Track *reviewWebhookTarget `json:"track,omitempty"`
+}
+
+type reviewWebhookTarget struct {
+	ID     uint   `json:"id"`
+	Title  string `json:"title"`
+	Artist string `json:"artist"`
+}
+
+// notifyReviewApproved отправляет вебхук WebhookURL о только что одобренной
+// рецензии. Review должен быть загружен с User, Album и Track.Album.
+func (rc *ReviewController) notifyReviewApproved(review *models.Review) {
+	url := rc.WebhookURL
+	if url == "" {
+		return
+	}
+
+	payload := reviewWebhookPayload{Event: "review.approved"}
+	payload.Review.ID = review.ID
+	payload.Review.Text = review.Text
+	payload.Review.FinalScore = review.FinalScore
+	payload.Review.HasSpoilers = review.HasSpoilers
+	payload.Review.CreatedAt = review.CreatedAt
+	payload.User.ID = review.User.ID
+	payload.User.Username = review.User.Username
+	if review.Album != nil {
+		payload.Album = &reviewWebhookTarget{ID: review.Album.ID, Title: review.Album.Title, Artist: review.Album.Artist}
+		payload.Review.URL = fmt.Sprintf("%s/albums/%d", rc.PublicSiteURL, review.Album.ID)
+	}
+	if review.Track != nil {
+		payload.Track = &reviewWebhookTarget{ID: review.Track.ID, Title: review.Track.Title, Artist: review.Track.Album.Artist}
+		payload.Review.URL = fmt.Sprintf("%s/tracks/%d", rc.PublicSiteURL, review.Track.ID)
+	}
+
+	utils.PostWebhookAsync(url, payload)
+}
+
+// reviewRateLimitRetryAfter возвращает, через сколько пользователь снова сможет
+// создать рецензию, или 0, если лимит не исчерпан. Считаем и удалённые рецензии
+// (Unscoped), иначе лимит обходится циклом "создал — удалил".
+func (rc *ReviewController) reviewRateLimitRetryAfter(userID uint) (time.Duration, error) {
+	limit := rc.ReviewsPerHour
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	windowStart := time.Now().Add(-time.Hour)
+	var recent []time.Time
+	if err := rc.DB.Unscoped().Model(&models.Review{}).
+		Where("user_id = ? AND created_at >= ?", userID, windowStart).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("created_at", &recent).Error; err != nil {
+		return 0, err
+	}
+	if len(recent) < limit {
+		return 0, nil
+	}
+
+	// Слот освободится, когда самая ранняя из последних limit рецензий выйдет из окна.
+	retryAfter := time.Until(recent[len(recent)-1].Add(time.Hour))
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	return retryAfter, nil
+}
+
 // CreateReviewRequest represents review creation request
 type CreateReviewRequest struct {
 	AlbumID              *uint  `json:"album_id"` // Optional - either album_id or track_id must be provided
 	TrackID              *uint  `json:"track_id"` // Optional - either album_id or track_id must be provided
 	Text                 string `json:"text"`
+	HasSpoilers          bool   `json:"has_spoilers"`
+	ContentWarning       string `json:"content_warning" binding:"max=200"`
 	RatingRhymes         int    `json:"rating_rhymes" binding:"required,min=1,max=10"`
 	RatingStructure      int    `json:"rating_structure" binding:"required,min=1,max=10"`
 	RatingImplementation int    `json:"rating_implementation" binding:"required,min=1,max=10"`
 	RatingIndividuality  int    `json:"rating_individuality" binding:"required,min=1,max=10"`
-	AtmosphereRating     int    `json:"atmosphere_rating" binding:"required,min=1,max=10"` // 1-10, will be converted to multiplier
+	AtmosphereRating     *int   `json:"atmosphere_rating" binding:"required"` // 1-10, will be converted to multiplier; шкалу проверяет CreateReview
 }
 
-// UpdateReviewRequest represents review update request
+// UpdateReviewRequest represents review update request. Все поля необязательны:
+// nil — значение не меняется, поэтому {"text": ""} проходит без повторной
+// отправки оценок.
 type UpdateReviewRequest struct {
 	Text                 *string `json:"text"` // Pointer to detect if field was provided
-	RatingRhymes         int     `json:"rating_rhymes" binding:"min=1,max=10"`
-	RatingStructure      int     `json:"rating_structure" binding:"min=1,max=10"`
-	RatingImplementation int     `json:"rating_implementation" binding:"min=1,max=10"`
-	RatingIndividuality  int     `json:"rating_individuality" binding:"min=1,max=10"`
-	AtmosphereRating     int     `json:"atmosphere_rating" binding:"min=1,max=10"` // 1-10, will be converted to multiplier
+	HasSpoilers          *bool   `json:"has_spoilers"`
+	ContentWarning       *string `json:"content_warning" binding:"omitempty,max=200"`
+	RatingRhymes         *int    `json:"rating_rhymes" binding:"omitempty,min=1,max=10"`
+	RatingStructure      *int    `json:"rating_structure" binding:"omitempty,min=1,max=10"`
+	RatingImplementation *int    `json:"rating_implementation" binding:"omitempty,min=1,max=10"`
+	RatingIndividuality  *int    `json:"rating_individuality" binding:"omitempty,min=1,max=10"`
+	AtmosphereRating     *int    `json:"atmosphere_rating"` // 1-10, will be converted to multiplier; шкалу проверяет UpdateReview
 }
 
 // GetReviews retrieves list of reviews with filters
 func (rc *ReviewController) GetReviews(c *gin.Context) {
 	var reviews []models.Review
-	query := rc.DB.Preload("User").Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Album").Preload("Likes").Preload("Likes.User")
+	query := requestDB(c, rc.DB).Preload("User", withDeletedAuthor).Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Album").Preload("Likes").Preload("Likes.User").Preload("Moderator", withModeratorName)
 
 	// Filter by album
 	if albumID := c.Query("album_id"); albumID != "" {
@@ -88,6 +224,18 @@ func (rc *ReviewController) GetReviews(c *gin.Context) {
 		query = query.Where("track_id = ?", trackID)
 	}
 
+	// Только рецензии на альбомы или только на треки (вкладки на фронте)
+	switch target := c.Query("target"); target {
+	case "":
+	case "album":
+		query = query.Where("reviews.album_id IS NOT NULL")
+	case "track":
+		query = query.Where("reviews.track_id IS NOT NULL")
+	default:
+		utils.RespondError(c, http.StatusBadRequest, "target must be album or track")
+		return
+	}
+
 	// Filter by user
 	if userID := c.Query("user_id"); userID != "" {
 		query = query.Where("user_id = ?", userID)
@@ -104,37 +252,80 @@ func (rc *ReviewController) GetReviews(c *gin.Context) {
 			})
 			return
 		}
-		sub := rc.DB.Model(&models.UserFollow{}).Select("following_id").Where("follower_id = ?", viewerID)
+		sub := requestDB(c, rc.DB).Model(&models.UserFollow{}).Select("following_id").Where("follower_id = ?", viewerID)
 		query = query.Where("user_id IN (?)", sub)
 	}
 
-	// Filter by status
-	if status := c.Query("status"); status != "" {
+	// Filter by status. По умолчанию и для посторонних — только approved:
+	// pending/rejected (с rejection_reason) видят администратор и сам автор
+	// при ?user_id=<свой id>, как в GetUserReviews.
+	if status := c.Query("status"); status != "" && canSeeAllReviewStatuses(c, c.Query("user_id")) {
 		query = query.Where("status = ?", status)
 	} else {
-		// By default, show only approved reviews
 		query = query.Where("status = ?", models.ReviewStatusApproved)
 	}
 
 	if artistMark := c.Query("artist_mark"); artistMark == "true" || artistMark == "1" {
-		markedReviewIDs := rc.DB.Model(&models.ReviewLike{}).
+		markedReviewIDs := requestDB(c, rc.DB).Model(&models.ReviewLike{}).
 			Select("review_likes.review_id").
 			Joins("JOIN users ON users.id = review_likes.user_id").
 			Where("users.is_verified_artist = ?", true)
 		query = query.Where("reviews.id IN (?)", markedReviewIDs)
 	}
+	// Курсорная пагинация: ?cursor= (пустой — первая страница). Порядок всегда
+	// created_at DESC, id DESC, sort_by и page игнорируются.
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		rc.getReviewsByCursor(c, query, cursor)
+		return
+	}
+
 	// Sort (только из белого списка — защита от SQL-инъекции через ORDER BY)
 	query = query.Order(utils.SafeOrderClause(c.Query("sort_by"), c.Query("sort_order"), reviewSortColumns, "created_at"))
 
 	// Pagination
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
+	page := utils.ParsePage(c)
 
 	var total int64
 	query.Model(&models.Review{}).Count(&total)
 
-	if err := query.Offset(offset).Limit(pageSize).Find(&reviews).Error; err != nil {
+	if err := query.Offset(page.Offset()).Limit(page.Size).Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch reviews",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	annotateArtistMarks(requestDB(c, rc.DB), reviews)
+	for i := range reviews {
+		hideModerator(c, &reviews[i])
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("reviews", reviews, total, page))
+}
+
+// getReviewsByCursor serves GetReviews in cursor mode: keyset по (created_at, id)
+// не читает пропущенные строки, поэтому глубокие страницы не медленнее первой.
+// total не считается — COUNT по всей выборке свёл бы выигрыш на нет.
+func (rc *ReviewController) getReviewsByCursor(c *gin.Context, query *gorm.DB, cursor string) {
+	pageSize := utils.ParsePage(c).Size
+
+	if cursor != "" {
+		createdAt, id, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "Invalid cursor",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		query = query.Where("(reviews.created_at, reviews.id) < (?, ?)", createdAt, id)
+	}
+
+	var reviews []models.Review
+	// Берём на одну строку больше, чтобы узнать, есть ли следующая страница.
+	if err := query.Order("reviews.created_at DESC, reviews.id DESC").Limit(pageSize + 1).Find(&reviews).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch reviews",
@@ -142,13 +333,23 @@ func (rc *ReviewController) GetReviews(c *gin.Context) {
 		})
 		return
 	}
-	annotateArtistMarks(rc.DB, reviews)
+
+	var nextCursor *string
+	if len(reviews) > pageSize {
+		reviews = reviews[:pageSize]
+		last := reviews[len(reviews)-1]
+		token := utils.EncodeCursor(last.CreatedAt, last.ID)
+		nextCursor = &token
+	}
+	annotateArtistMarks(requestDB(c, rc.DB), reviews)
+	for i := range reviews {
+		hideModerator(c, &reviews[i])
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"reviews":   reviews,
-		"total":     total,
-		"page":      page,
-		"page_size": pageSize,
+		"reviews":     reviews,
+		"page_size":   pageSize,
+		"next_cursor": nextCursor,
 	})
 }
 
@@ -157,7 +358,7 @@ func (rc *ReviewController) GetReview(c *gin.Context) {
 	id := c.Param("id")
 	var review models.Review
 
-	if err := rc.DB.Preload("User").Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Album").Preload("Track.Genres").Preload("Likes").Preload("Likes.User").First(&review, id).Error; err != nil {
+	if err := requestDB(c, rc.DB).Preload("User", withDeletedAuthor).Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Album").Preload("Track.Genres").Preload("Likes").Preload("Likes.User").Preload("Moderator", withModeratorName).First(&review, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Review not found",
@@ -165,16 +366,38 @@ func (rc *ReviewController) GetReview(c *gin.Context) {
 		})
 		return
 	}
-	annotateArtistMark(rc.DB, &review)
+	annotateArtistMark(requestDB(c, rc.DB), &review)
+	hideModerator(c, &review)
+	review.FillScoreBreakdown(rc.Scoring)
 
 	c.JSON(http.StatusOK, review)
 }
 
+// userHasListened reports whether the user played the reviewed track — or, for an
+// album review, at least one track of the album — according to user_track_listens.
+func userHasListened(db *gorm.DB, userID uint, albumID, trackID *uint) bool {
+	query := db.Model(&models.UserTrackListen{}).Where("user_track_listens.user_id = ?", userID)
+	switch {
+	case trackID != nil:
+		query = query.Where("user_track_listens.track_id = ?", *trackID)
+	case albumID != nil:
+		query = query.Joins("JOIN tracks ON tracks.id = user_track_listens.track_id AND tracks.deleted_at IS NULL").
+			Where("tracks.album_id = ?", *albumID)
+	default:
+		return false
+	}
+	var count int64
+	if err := query.Limit(1).Count(&count).Error; err != nil {
+		slog.Warn("failed to check track listens", "user_id", userID, "error", err)
+		return false
+	}
+	return count > 0
+}
+
 // CreateReview creates a new review
 func (rc *ReviewController) CreateReview(c *gin.Context) {
 	userID, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
-		log.Printf("CreateReview: user not authenticated (no X-User-ID header)")
 		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
 			Error:   "Unauthorized",
 			Message: "Необходимо войти в систему для создания рецензии",
@@ -183,14 +406,22 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 		return
 	}
 
+	retryAfter, err := rc.withRequest(c).reviewRateLimitRetryAfter(userID)
+	if err != nil {
+		middleware.Logger(c).Error("failed to check review rate limit", "error", err)
+	} else if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, utils.ErrorResponse{
+			Error:   "Too Many Requests",
+			Message: "Слишком много рецензий за последний час. Попробуйте позже.",
+			Code:    http.StatusTooManyRequests,
+		})
+		return
+	}
+
 	var req CreateReviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Error binding JSON in CreateReview: %v", err)
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: fmt.Sprintf("Invalid request data: %v", err.Error()),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
@@ -212,8 +443,15 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 		return
 	}
 
-	// Convert atmosphere rating (1-10) to multiplier (1.0000-1.6072)
-	atmosphereMultiplier := convertAtmosphereToMultiplier(req.AtmosphereRating)
+	// Конвертация корректна только для 1-10: шкалу проверяем здесь, а не
+	// binding-тегом, чтобы клиент получил ошибку именно поля atmosphere_rating.
+	if err := utils.AtmosphereRatingFieldError(*req.AtmosphereRating); err != nil {
+		utils.RespondBindingError(c, err)
+		return
+	}
+
+	// Convert atmosphere rating (1-10) to multiplier
+	atmosphereMultiplier := rc.Scoring.AtmosphereMultiplier(*req.AtmosphereRating)
 
 	// Validate review data
 	review := models.Review{
@@ -221,15 +459,18 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 		AlbumID:              req.AlbumID,
 		TrackID:              req.TrackID,
 		Text:                 req.Text,
+		HasSpoilers:          req.HasSpoilers,
+		ContentWarning:       strings.TrimSpace(req.ContentWarning),
 		RatingRhymes:         req.RatingRhymes,
 		RatingStructure:      req.RatingStructure,
 		RatingImplementation: req.RatingImplementation,
 		RatingIndividuality:  req.RatingIndividuality,
+		AtmosphereRating:     *req.AtmosphereRating,
 		AtmosphereMultiplier: atmosphereMultiplier,
 	}
 
-	if err := utils.ValidateReview(&review); err != nil {
-		log.Printf("Validation error in CreateReview: %v", err)
+	if err := utils.ValidateReview(&review, rc.Scoring); err != nil {
+		middleware.Logger(c).Debug("review validation failed", "error", err)
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 			Error:   "Validation Error",
 			Message: fmt.Sprintf("Ошибка валидации: %v", err.Error()),
@@ -241,8 +482,7 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 	// Check if album or track exists
 	if req.AlbumID != nil {
 		var album models.Album
-		if err := rc.DB.First(&album, *req.AlbumID).Error; err != nil {
-			log.Printf("Album %d not found: %v", *req.AlbumID, err)
+		if err := requestDB(c, rc.DB).First(&album, *req.AlbumID).Error; err != nil {
 			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 				Error:   "Bad Request",
 				Message: fmt.Sprintf("Альбом с ID %d не найден", *req.AlbumID),
@@ -250,11 +490,19 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 			})
 			return
 		}
+		// Альбом на модерации ещё не публичный: рецензии на него портили бы средние.
+		if album.Status != models.AlbumStatusApproved {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "Альбом ещё не прошёл модерацию, рецензии на него пока недоступны",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
 
 		// Check if user already has a review for this album
 		var existingReview models.Review
-		if err := rc.DB.Where("user_id = ? AND album_id = ? AND deleted_at IS NULL", userID, *req.AlbumID).First(&existingReview).Error; err == nil {
-			log.Printf("User %d already has a review for album %d", userID, *req.AlbumID)
+		if err := requestDB(c, rc.DB).Where("user_id = ? AND album_id = ? AND deleted_at IS NULL", userID, *req.AlbumID).First(&existingReview).Error; err == nil {
 			c.JSON(http.StatusConflict, utils.ErrorResponse{
 				Error:   "Conflict",
 				Message: "У вас уже есть рецензия для этого альбома. Пожалуйста, отредактируйте существующую рецензию.",
@@ -264,8 +512,7 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 		}
 	} else if req.TrackID != nil {
 		var track models.Track
-		if err := rc.DB.First(&track, *req.TrackID).Error; err != nil {
-			log.Printf("Track %d not found: %v", *req.TrackID, err)
+		if err := requestDB(c, rc.DB).First(&track, *req.TrackID).Error; err != nil {
 			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 				Error:   "Bad Request",
 				Message: fmt.Sprintf("Трек с ID %d не найден", *req.TrackID),
@@ -273,11 +520,20 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 			})
 			return
 		}
+		var trackAlbum models.Album
+		if err := requestDB(c, rc.DB).Select("id", "status").First(&trackAlbum, track.AlbumID).Error; err == nil &&
+			trackAlbum.Status != models.AlbumStatusApproved {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "Альбом трека ещё не прошёл модерацию, рецензии на него пока недоступны",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
 
 		// Check if user already has a review for this track
 		var existingReview models.Review
-		if err := rc.DB.Where("user_id = ? AND track_id = ? AND deleted_at IS NULL", userID, *req.TrackID).First(&existingReview).Error; err == nil {
-			log.Printf("User %d already has a review for track %d", userID, *req.TrackID)
+		if err := requestDB(c, rc.DB).Where("user_id = ? AND track_id = ? AND deleted_at IS NULL", userID, *req.TrackID).First(&existingReview).Error; err == nil {
 			c.JSON(http.StatusConflict, utils.ErrorResponse{
 				Error:   "Conflict",
 				Message: "У вас уже есть рецензия для этого трека. Пожалуйста, отредактируйте существующую рецензию.",
@@ -288,7 +544,8 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 	}
 
 	// Calculate final score
-	review.CalculateFinalScore()
+	review.CalculateFinalScore(rc.Scoring)
+	review.HasListened = userHasListened(requestDB(c, rc.DB), review.UserID, review.AlbumID, review.TrackID)
 
 	// Text reviews go to moderation, while score-only ratings can be published immediately.
 	if strings.TrimSpace(review.Text) == "" {
@@ -297,44 +554,40 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 		review.Status = models.ReviewStatusPending
 	}
 
-	if err := rc.DB.Create(&review).Error; err != nil {
-		// Log detailed error for debugging
-		log.Printf("Error creating review: %v", err)
-		log.Printf("Review data: UserID=%d, AlbumID=%v, TrackID=%v, Text=%s",
-			review.UserID, review.AlbumID, review.TrackID, review.Text)
-
-		// Provide more detailed error message
-		errorMessage := "Failed to create review"
-		if err.Error() != "" {
-			errorMessage = fmt.Sprintf("Failed to create review: %v", err)
+	if err := requestDB(c, rc.DB).Create(&review).Error; err != nil {
+		// Параллельный запрос успел создать рецензию между проверкой выше и INSERT —
+		// частичный уникальный индекс ux_reviews_user_album / ux_reviews_user_track.
+		if utils.IsUniqueViolation(err) {
+			message := "У вас уже есть рецензия для этого альбома. Пожалуйста, отредактируйте существующую рецензию."
+			if review.TrackID != nil {
+				message = "У вас уже есть рецензия для этого трека. Пожалуйста, отредактируйте существующую рецензию."
+			}
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: message,
+				Code:    http.StatusConflict,
+			})
+			return
 		}
 
+		// Текст рецензии не логируется: это пользовательский контент
+		middleware.Logger(c).Error("failed to create review", "album_id", review.AlbumID, "track_id", review.TrackID, "error", err)
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
-			Message: errorMessage,
+			Message: "Failed to create review",
 			Code:    http.StatusInternalServerError,
 		})
 		return
 	}
 
-	// Update album average rating if review is approved and is for an album
-	if review.Status == models.ReviewStatusApproved && review.AlbumID != nil {
-		albumController := &AlbumController{DB: rc.DB}
-		if err := albumController.CalculateAverageRating(*review.AlbumID); err != nil {
-			// Log error but don't fail the request
-		}
-	}
-
-	// Update track average rating if review is approved and is for a track
-	if review.Status == models.ReviewStatusApproved && review.TrackID != nil {
-		trackController := &TrackController{DB: rc.DB}
-		if err := trackController.CalculateAverageRating(*review.TrackID); err != nil {
-			// Log error but don't fail the request
-		}
+	// Одобренная сразу рецензия входит в среднее альбома или трека; ошибка
+	// пересчёта логируется и запрос не валит.
+	if review.Status == models.ReviewStatusApproved {
+		rc.recalcReviewTargets(review.AlbumID, review.TrackID)
 	}
 
 	// Preload relationships
-	query := rc.DB.Preload("User").Preload("Likes").Preload("Likes.User")
+	query := requestDB(c, rc.DB).Preload("User", withDeletedAuthor).Preload("Likes").Preload("Likes.User")
 	if review.AlbumID != nil {
 		query = query.Preload("Album").Preload("Album.Genre")
 	}
@@ -342,7 +595,11 @@ func (rc *ReviewController) CreateReview(c *gin.Context) {
 		query = query.Preload("Track").Preload("Track.Album").Preload("Track.Genres")
 	}
 	query.First(&review, review.ID)
-	annotateArtistMark(rc.DB, &review)
+	annotateArtistMark(requestDB(c, rc.DB), &review)
+	if review.Status == models.ReviewStatusApproved {
+		rc.notifyReviewApproved(&review)
+	}
+	review.FillScoreBreakdown(rc.Scoring)
 	c.JSON(http.StatusCreated, review)
 }
 
@@ -351,7 +608,7 @@ func (rc *ReviewController) UpdateReview(c *gin.Context) {
 	id := c.Param("id")
 	var review models.Review
 
-	if err := rc.DB.First(&review, id).Error; err != nil {
+	if err := requestDB(c, rc.DB).First(&review, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Review not found",
@@ -383,11 +640,7 @@ func (rc *ReviewController) UpdateReview(c *gin.Context) {
 
 	var req UpdateReviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
@@ -395,33 +648,47 @@ func (rc *ReviewController) UpdateReview(c *gin.Context) {
 	originalText := review.Text
 	textChanged := false
 
-	// Обновляем текст только если поле было передано в запросе
+	// Обновляем текст только если поле было передано в запросе: nil — текст не
+	// трогаем, "" (или одни пробелы) — очищаем, и это тоже изменение текста.
 	if req.Text != nil {
 		newText := *req.Text
+		if strings.TrimSpace(newText) == "" {
+			newText = ""
+		}
 		if newText != originalText {
 			textChanged = true
 			review.Text = newText
 		}
 	}
 
-	// Update ratings
-	if req.RatingRhymes != 0 && req.RatingRhymes != review.RatingRhymes {
-		review.RatingRhymes = req.RatingRhymes
+	// Метки спойлеров — чистые метаданные, статус модерации не меняют.
+	if req.HasSpoilers != nil {
+		review.HasSpoilers = *req.HasSpoilers
 	}
-	if req.RatingStructure != 0 && req.RatingStructure != review.RatingStructure {
-		review.RatingStructure = req.RatingStructure
+	if req.ContentWarning != nil {
+		review.ContentWarning = strings.TrimSpace(*req.ContentWarning)
+	}
+
+	// Update ratings (только переданные)
+	if req.RatingRhymes != nil {
+		review.RatingRhymes = *req.RatingRhymes
 	}
-	if req.RatingImplementation != 0 && req.RatingImplementation != review.RatingImplementation {
-		review.RatingImplementation = req.RatingImplementation
+	if req.RatingStructure != nil {
+		review.RatingStructure = *req.RatingStructure
 	}
-	if req.RatingIndividuality != 0 && req.RatingIndividuality != review.RatingIndividuality {
-		review.RatingIndividuality = req.RatingIndividuality
+	if req.RatingImplementation != nil {
+		review.RatingImplementation = *req.RatingImplementation
 	}
-	if req.AtmosphereRating != 0 {
-		newMultiplier := convertAtmosphereToMultiplier(req.AtmosphereRating)
-		if newMultiplier != review.AtmosphereMultiplier {
-			review.AtmosphereMultiplier = newMultiplier
+	if req.RatingIndividuality != nil {
+		review.RatingIndividuality = *req.RatingIndividuality
+	}
+	if req.AtmosphereRating != nil {
+		if err := utils.AtmosphereRatingFieldError(*req.AtmosphereRating); err != nil {
+			utils.RespondBindingError(c, err)
+			return
 		}
+		review.AtmosphereRating = *req.AtmosphereRating
+		review.AtmosphereMultiplier = rc.Scoring.AtmosphereMultiplier(*req.AtmosphereRating)
 	}
 
 	// Логика изменения статуса для обычных пользователей:
@@ -438,7 +705,7 @@ func (rc *ReviewController) UpdateReview(c *gin.Context) {
 	// Админы могут редактировать без изменения статуса
 
 	// Validate updated review
-	if err := utils.ValidateReview(&review); err != nil {
+	if err := utils.ValidateReview(&review, rc.Scoring); err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 			Error:   "Validation Error",
 			Message: err.Error(),
@@ -448,9 +715,13 @@ func (rc *ReviewController) UpdateReview(c *gin.Context) {
 	}
 
 	// Recalculate final score
-	review.CalculateFinalScore()
+	review.CalculateFinalScore(rc.Scoring)
+	// Отметку только выставляем: прослушивание могло случиться уже после публикации.
+	if !review.HasListened {
+		review.HasListened = userHasListened(requestDB(c, rc.DB), review.UserID, review.AlbumID, review.TrackID)
+	}
 
-	if err := rc.DB.Save(&review).Error; err != nil {
+	if err := requestDB(c, rc.DB).Save(&review).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to update review",
@@ -462,7 +733,7 @@ func (rc *ReviewController) UpdateReview(c *gin.Context) {
 	// Пересчитываем средний рейтинг и альбома, и трека.
 	rc.recalcReviewTargets(review.AlbumID, review.TrackID)
 
-	rc.DB.Preload("User").Preload("Album").Preload("Album.Genre").First(&review, review.ID)
+	requestDB(c, rc.DB).Preload("User", withDeletedAuthor).Preload("Album").Preload("Album.Genre").First(&review, review.ID)
 	c.JSON(http.StatusOK, review)
 }
 
@@ -471,7 +742,7 @@ func (rc *ReviewController) DeleteReview(c *gin.Context) {
 	id := c.Param("id")
 	var review models.Review
 
-	if err := rc.DB.First(&review, id).Error; err != nil {
+	if err := requestDB(c, rc.DB).First(&review, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Review not found",
@@ -503,7 +774,7 @@ func (rc *ReviewController) DeleteReview(c *gin.Context) {
 
 	albumID := review.AlbumID
 	trackID := review.TrackID
-	if err := rc.DB.Delete(&review).Error; err != nil {
+	if err := requestDB(c, rc.DB).Delete(&review).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to delete review",
@@ -525,7 +796,7 @@ func (rc *ReviewController) ApproveReview(c *gin.Context) {
 	id := c.Param("id")
 	var review models.Review
 
-	if err := rc.DB.First(&review, id).Error; err != nil {
+	if err := requestDB(c, rc.DB).First(&review, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Review not found",
@@ -544,12 +815,14 @@ func (rc *ReviewController) ApproveReview(c *gin.Context) {
 		return
 	}
 
+	wasApproved := review.Status == models.ReviewStatusApproved
 	review.Status = models.ReviewStatusApproved
+	review.RejectionReason = ""
 	review.ModeratedBy = &userID
 	now := time.Now()
 	review.ModeratedAt = &now
 
-	if err := rc.DB.Save(&review).Error; err != nil {
+	if err := requestDB(c, rc.DB).Save(&review).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to approve review",
@@ -561,16 +834,25 @@ func (rc *ReviewController) ApproveReview(c *gin.Context) {
 	// Одобрение меняет состав approved-рецензий → пересчитываем альбом и трек.
 	rc.recalcReviewTargets(review.AlbumID, review.TrackID)
 
-	rc.DB.Preload("User").Preload("Album").Preload("Album.Genre").First(&review, review.ID)
+	requestDB(c, rc.DB).Preload("User", withDeletedAuthor).Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Album").Preload("Moderator", withModeratorName).First(&review, review.ID)
+	// Вебхук — только на переход в approved, повторное одобрение не дублирует уведомление.
+	if !wasApproved {
+		rc.notifyReviewApproved(&review)
+	}
 	c.JSON(http.StatusOK, review)
 }
 
+// RejectReviewRequest is the optional body of RejectReview
+type RejectReviewRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
 // RejectReview rejects a review (admin only)
 func (rc *ReviewController) RejectReview(c *gin.Context) {
 	id := c.Param("id")
 	var review models.Review
 
-	if err := rc.DB.First(&review, id).Error; err != nil {
+	if err := requestDB(c, rc.DB).First(&review, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Review not found",
@@ -589,12 +871,20 @@ func (rc *ReviewController) RejectReview(c *gin.Context) {
 		return
 	}
 
+	// Причина необязательна: тело можно не передавать вовсе.
+	var req RejectReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.RespondBindingError(c, err)
+		return
+	}
+
 	review.Status = models.ReviewStatusRejected
+	review.RejectionReason = strings.TrimSpace(req.Reason)
 	review.ModeratedBy = &userID
 	now := time.Now()
 	review.ModeratedAt = &now
 
-	if err := rc.DB.Save(&review).Error; err != nil {
+	if err := requestDB(c, rc.DB).Save(&review).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to reject review",
@@ -606,10 +896,49 @@ func (rc *ReviewController) RejectReview(c *gin.Context) {
 	// Отклонённая рецензия больше не участвует в среднем — пересчитываем.
 	rc.recalcReviewTargets(review.AlbumID, review.TrackID)
 
-	rc.DB.Preload("User").Preload("Album").Preload("Album.Genre").First(&review, review.ID)
+	requestDB(c, rc.DB).Preload("User", withDeletedAuthor).Preload("Album").Preload("Album.Genre").Preload("Moderator", withModeratorName).First(&review, review.ID)
 	c.JSON(http.StatusOK, review)
 }
 
+// GetMyReviews returns the caller's own reviews in any status — личный кабинет
+// автора: здесь видны pending и rejected вместе с причиной отклонения.
+func (rc *ReviewController) GetMyReviews(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	query := requestDB(c, rc.DB).Model(&models.Review{}).Where("user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	page := utils.ParsePage(c)
+
+	var total int64
+	query.Count(&total)
+
+	var reviews []models.Review
+	if err := query.Preload("Album").Preload("Track").Preload("Track.Album").
+		Order(utils.SafeOrderClause(c.Query("sort_by"), c.Query("sort_order"), reviewSortColumns, "created_at")).
+		Offset(page.Offset()).Limit(page.Size).
+		Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch reviews",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("reviews", reviews, total, page))
+}
+
 // LikeReview adds a like to a review
 func (rc *ReviewController) LikeReview(c *gin.Context) {
 	reviewID := c.Param("id")
@@ -625,7 +954,7 @@ func (rc *ReviewController) LikeReview(c *gin.Context) {
 
 	// Check if review exists
 	var review models.Review
-	if err := rc.DB.First(&review, reviewID).Error; err != nil {
+	if err := requestDB(c, rc.DB).First(&review, reviewID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Review not found",
@@ -636,8 +965,8 @@ func (rc *ReviewController) LikeReview(c *gin.Context) {
 
 	// Check if like already exists
 	var existingLike models.ReviewLike
-	if err := rc.DB.Where("user_id = ? AND review_id = ?", userID, reviewID).First(&existingLike).Error; err == nil {
-		c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true})
+	if err := requestDB(c, rc.DB).Where("user_id = ? AND review_id = ?", userID, reviewID).First(&existingLike).Error; err == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true, "likes_count": countReviewLikes(requestDB(c, rc.DB), review.ID)})
 		return
 	}
 
@@ -647,7 +976,12 @@ func (rc *ReviewController) LikeReview(c *gin.Context) {
 		ReviewID: review.ID,
 	}
 
-	if err := rc.DB.Create(&like).Error; err != nil {
+	if err := requestDB(c, rc.DB).Create(&like).Error; err != nil {
+		// Параллельный запрос успел поставить тот же лайк — это не ошибка.
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true, "likes_count": countReviewLikes(requestDB(c, rc.DB), review.ID)})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to like review",
@@ -656,7 +990,15 @@ func (rc *ReviewController) LikeReview(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Review liked", "liked": true})
+	c.JSON(http.StatusCreated, gin.H{"message": "Review liked", "liked": true, "likes_count": countReviewLikes(requestDB(c, rc.DB), review.ID)})
+}
+
+// countReviewLikes returns the like total of a review. По тем же лайкам
+// сортируются популярные рецензии, так что кнопка и лента не расходятся (0 при ошибке).
+func countReviewLikes(db *gorm.DB, reviewID uint) int64 {
+	var count int64
+	db.Model(&models.ReviewLike{}).Where("review_id = ?", reviewID).Count(&count)
+	return count
 }
 
 // UnlikeReview removes a like from a review
@@ -674,7 +1016,7 @@ func (rc *ReviewController) UnlikeReview(c *gin.Context) {
 
 	// Check if review exists
 	var review models.Review
-	if err := rc.DB.First(&review, reviewID).Error; err != nil {
+	if err := requestDB(c, rc.DB).First(&review, reviewID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Review not found",
@@ -685,7 +1027,7 @@ func (rc *ReviewController) UnlikeReview(c *gin.Context) {
 
 	// Жёсткое удаление: при soft-delete остаточная строка конфликтовала бы
 	// с уникальным индексом (user_id, review_id) при повторном лайке.
-	if err := rc.DB.Unscoped().Where("user_id = ? AND review_id = ?", userID, reviewID).Delete(&models.ReviewLike{}).Error; err != nil {
+	if err := requestDB(c, rc.DB).Unscoped().Where("user_id = ? AND review_id = ?", userID, reviewID).Delete(&models.ReviewLike{}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to unlike review",
@@ -694,7 +1036,7 @@ func (rc *ReviewController) UnlikeReview(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Review unliked", "liked": false})
+	c.JSON(http.StatusOK, gin.H{"message": "Review unliked", "liked": false, "likes_count": countReviewLikes(requestDB(c, rc.DB), review.ID)})
 }
 
 // GetPopularReviews retrieves most liked reviews from last 24 hours, with a recent fallback for demo stability.
@@ -709,7 +1051,7 @@ func (rc *ReviewController) GetPopularReviews(c *gin.Context) {
 	last24Hours := time.Now().Add(-24 * time.Hour)
 	recentApprovedAlbumReviews := func(db *gorm.DB) *gorm.DB {
 		return db.Model(&models.Review{}).
-			Preload("User").
+			Preload("User", withDeletedAuthor).
 			Preload("Album").
 			Preload("Album.Genre").
 			Preload("Track").
@@ -722,7 +1064,7 @@ func (rc *ReviewController) GetPopularReviews(c *gin.Context) {
 	}
 
 	var reviews []models.Review
-	query := recentApprovedAlbumReviews(rc.DB).
+	query := recentApprovedAlbumReviews(requestDB(c, rc.DB)).
 		Where("created_at >= ?", last24Hours).
 		Order("created_at DESC").
 		Limit(limit * 2)
@@ -742,7 +1084,7 @@ func (rc *ReviewController) GetPopularReviews(c *gin.Context) {
 			seen = append(seen, review.ID)
 		}
 		var fallback []models.Review
-		fallbackQuery := recentApprovedAlbumReviews(rc.DB).
+		fallbackQuery := recentApprovedAlbumReviews(requestDB(c, rc.DB)).
 			Order("created_at DESC").
 			Limit((limit - len(reviews)) * 2)
 		if len(seen) > 0 {
@@ -759,7 +1101,7 @@ func (rc *ReviewController) GetPopularReviews(c *gin.Context) {
 		reviews = append(reviews, fallback...)
 	}
 
-	annotateArtistMarks(rc.DB, reviews)
+	annotateArtistMarks(requestDB(c, rc.DB), reviews)
 
 	sort.SliceStable(reviews, func(i, j int) bool {
 		return len(reviews[i].Likes) > len(reviews[j].Likes)
@@ -769,5 +1111,232 @@ func (rc *ReviewController) GetPopularReviews(c *gin.Context) {
 		reviews = reviews[:limit]
 	}
 
-	c.JSON(http.StatusOK, reviews)
+	c.JSON(http.StatusOK, utils.NewList("reviews", reviews, limit))
+}
+
+// GetRecentlyReviewed returns the latest approved review of each album/track,
+// newest first: лента «недавно оценённые» без повторов одного релиза.
+func (rc *ReviewController) GetRecentlyReviewed(c *gin.Context) {
+	limit := 10
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 && parsedLimit <= 50 {
+			limit = parsedLimit
+		}
+	}
+
+	// DISTINCT ON оставляет по одной (самой свежей) рецензии на цель; у цели
+	// заполнен ровно один из album_id / track_id, NULL в DISTINCT ON равны.
+	var ids []uint
+	if err := requestDB(c, rc.DB).Raw(`
+		SELECT id FROM (
+			SELECT DISTINCT ON (album_id, track_id) id, created_at
+			FROM reviews
+			WHERE status = ? AND deleted_at IS NULL
+			ORDER BY album_id, track_id, created_at DESC, id DESC
+		) AS latest
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`, models.ReviewStatusApproved, limit).
+		Scan(&ids).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch recently reviewed",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	reviews := []models.Review{}
+	if len(ids) > 0 {
+		if err := requestDB(c, rc.DB).Preload("User", withDeletedAuthor).
+			Preload("Album").
+			Preload("Album.Genre").
+			Preload("Track").
+			Preload("Track.Album").
+			Where("id IN ?", ids).
+			Order("created_at DESC, id DESC").
+			Find(&reviews).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to fetch recently reviewed",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
+	annotateArtistMarks(requestDB(c, rc.DB), reviews)
+	c.JSON(http.StatusOK, utils.NewList("reviews", reviews, limit))
+}
+
+// GetPendingReviewCount returns the number of reviews awaiting moderation for
+// the admin badge. Только COUNT, строки не читаются.
+func (rc *ReviewController) GetPendingReviewCount(c *gin.Context) {
+	var count int64
+	if err := requestDB(c, rc.DB).Model(&models.Review{}).Where("status = ?", models.ReviewStatusPending).Count(&count).Error; err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to count pending reviews")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// RecomputeScores recalculates atmosphere multipliers and final scores of all
+// reviews with the current scoring config and refreshes album/track averages.
+// Нужен после смены SCORE_BASE_WEIGHT / SCORE_ATMOSPHERE_MAX.
+func (rc *ReviewController) RecomputeScores(c *gin.Context) {
+	scoring := rc.Scoring
+	defaults := models.ScoringConfig{BaseWeight: models.DefaultScoreBaseWeight, AtmosphereMax: models.DefaultScoreAtmosphereMax}
+
+	var reviews []models.Review
+	if err := requestDB(c, rc.DB).Select("id", "album_id", "track_id", "rating_rhymes", "rating_structure",
+		"rating_implementation", "rating_individuality", "atmosphere_rating", "atmosphere_multiplier", "final_score").
+		Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch reviews",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	albumIDs := map[uint]bool{}
+	trackIDs := map[uint]bool{}
+	updated := 0
+	if err := requestDB(c, rc.DB).Transaction(func(tx *gorm.DB) error {
+		for i := range reviews {
+			review := &reviews[i]
+			rating := review.AtmosphereRating
+			if rating == 0 {
+				// Рецензия старше колонки atmosphere_rating: множитель ещё по формуле по умолчанию.
+				rating = int(math.Round(defaults.AtmosphereRating(review.AtmosphereMultiplier)))
+			}
+			multiplier := scoring.AtmosphereMultiplier(rating)
+			baseSum := review.RatingRhymes + review.RatingStructure + review.RatingImplementation + review.RatingIndividuality
+			finalScore := scoring.FinalScore(baseSum, multiplier)
+			if rating == review.AtmosphereRating && multiplier == review.AtmosphereMultiplier && finalScore == review.FinalScore {
+				continue
+			}
+			// UpdateColumns: пересчёт формулы не должен менять updated_at рецензии.
+			if err := tx.Model(review).UpdateColumns(map[string]interface{}{
+				"atmosphere_rating":     rating,
+				"atmosphere_multiplier": multiplier,
+				"final_score":           finalScore,
+			}).Error; err != nil {
+				return err
+			}
+			updated++
+			if review.AlbumID != nil {
+				albumIDs[*review.AlbumID] = true
+			}
+			if review.TrackID != nil {
+				trackIDs[*review.TrackID] = true
+			}
+		}
+		for albumID := range albumIDs {
+			if err := (&AlbumController{DB: tx}).CalculateAverageRating(albumID); err != nil {
+				return err
+			}
+		}
+		for trackID := range trackIDs {
+			if err := (&TrackController{DB: tx}).CalculateAverageRating(trackID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to recompute review scores",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scoring":         scoring,
+		"reviews_total":   len(reviews),
+		"reviews_updated": updated,
+		"albums_updated":  len(albumIDs),
+		"tracks_updated":  len(trackIDs),
+	})
+}
+
+// recomputeRatingsBatchSize — сколько альбомов или треков пересчитывается в одной транзакции.
+const recomputeRatingsBatchSize = 200
+
+// RecomputeRatings recalculates average_rating of every album and track from
+// approved reviews. Инструмент ремонта: средние могли разойтись после импорта
+// или ручной правки данных. Каждая пачка — своя транзакция, поэтому прерванный
+// запрос оставляет уже пересчитанные пачки; повторный вызов безопасен.
+func (rc *ReviewController) RecomputeRatings(c *gin.Context) {
+	db := requestDB(c, rc.DB)
+	albumsTotal, albumsUpdated, err := recomputeAverages(db, &models.Album{}, func(tx *gorm.DB, id uint) error {
+		return (&AlbumController{DB: tx}).CalculateAverageRating(id)
+	})
+	if err != nil {
+		middleware.Logger(c).Error("failed to recompute album ratings", "error", err)
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to recompute album ratings")
+		return
+	}
+	tracksTotal, tracksUpdated, err := recomputeAverages(db, &models.Track{}, func(tx *gorm.DB, id uint) error {
+		return (&TrackController{DB: tx}).CalculateAverageRating(id)
+	})
+	if err != nil {
+		middleware.Logger(c).Error("failed to recompute track ratings", "error", err)
+		utils.RespondError(c, http.StatusInternalServerError, "Failed to recompute track ratings")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"albums_total":   albumsTotal,
+		"albums_updated": albumsUpdated,
+		"tracks_total":   tracksTotal,
+		"tracks_updated": tracksUpdated,
+	})
+}
+
+// recomputeAverages walks model rows by id in batches, calls recalc for each and
+// returns how many rows were processed and how many average_rating values changed.
+func recomputeAverages(db *gorm.DB, model interface{}, recalc func(tx *gorm.DB, id uint) error) (int, int, error) {
+	type ratingRow struct {
+		ID            uint
+		AverageRating float64
+	}
+	total, updated := 0, 0
+	var lastID uint
+	for {
+		var rows []ratingRow
+		if err := db.Model(model).Select("id", "average_rating").Where("id > ?", lastID).
+			Order("id").Limit(recomputeRatingsBatchSize).Find(&rows).Error; err != nil {
+			return total, updated, err
+		}
+		if len(rows) == 0 {
+			return total, updated, nil
+		}
+		lastID = rows[len(rows)-1].ID
+
+		before := make(map[uint]float64, len(rows))
+		ids := make([]uint, 0, len(rows))
+		for _, row := range rows {
+			before[row.ID] = row.AverageRating
+			ids = append(ids, row.ID)
+		}
+		var after []ratingRow
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, id := range ids {
+				if err := recalc(tx, id); err != nil {
+					return err
+				}
+			}
+			return tx.Model(model).Select("id", "average_rating").Where("id IN ?", ids).Find(&after).Error
+		})
+		if err != nil {
+			return total, updated, err
+		}
+		total += len(rows)
+		for _, row := range after {
+			if row.AverageRating != before[row.ID] {
+				updated++
+			}
+		}
+	}
 }
@@ -0,0 +1,832 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"music-review-site/backend/models"
+	"music-review-site/backend/utils"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Недавно оценённые: конверт пагинации, по одной последней рецензии на релиз.
+func TestGetRecentlyReviewedEnvelope(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	album := seedAlbum(t, db, "recent", models.AlbumStatusApproved)
+	first := seedUser(t, db, "recent-first", false)
+	second := seedUser(t, db, "recent-second", false)
+	seedAlbumReview(t, db, first.ID, album.ID, 30)
+	latest := seedAlbumReview(t, db, second.ID, album.ID, 50)
+
+	w := serve(rc.GetRecentlyReviewed, http.MethodGet, "/reviews/recent", "/reviews/recent?limit=50", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("recent: %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Reviews  []models.Review `json:"reviews"`
+		PageSize int             `json:"page_size"`
+		HasNext  bool            `json:"has_next"`
+	}
+	decode(t, w, &body)
+	if body.PageSize != 50 || body.HasNext {
+		t.Errorf("envelope: page_size %d, has_next %v", body.PageSize, body.HasNext)
+	}
+	count := 0
+	for _, review := range body.Reviews {
+		if review.AlbumID != nil && *review.AlbumID == album.ID {
+			count++
+			if review.ID != latest.ID {
+				t.Errorf("want latest review %d of the album, got %d", latest.ID, review.ID)
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("album listed %d times, want once", count)
+	}
+}
+
+// Частичный PUT без оценок проходит binding: оценки необязательны.
+func TestUpdateReviewRequestBindsTextOnly(t *testing.T) {
+	var req UpdateReviewRequest
+	if err := binding.JSON.BindBody([]byte(`{"text":""}`), &req); err != nil {
+		t.Fatalf(`bind {"text":""}: %v`, err)
+	}
+	if req.Text == nil || *req.Text != "" || req.RatingRhymes != nil {
+		t.Errorf("bound request = %+v", req)
+	}
+	if err := binding.JSON.BindBody([]byte(`{"rating_rhymes":11}`), &req); err == nil {
+		t.Error("rating_rhymes=11 passed validation")
+	}
+}
+
+// atmosphere_rating вне шкалы проходит binding: шкалу проверяет CreateReview,
+// чтобы вернуть ошибку поля, а не общий отказ тега. Отсутствие поля — ошибка binding.
+func TestCreateReviewRequestBindsAtmosphereOutOfRange(t *testing.T) {
+	var req CreateReviewRequest
+	body := `{"album_id": 1, "rating_rhymes": 5, "rating_structure": 5, "rating_implementation": 5,
+		"rating_individuality": 5, "atmosphere_rating": 0}`
+	if err := binding.JSON.BindBody([]byte(body), &req); err != nil {
+		t.Fatalf("atmosphere_rating 0 rejected by binding: %v", err)
+	}
+	if req.AtmosphereRating == nil || *req.AtmosphereRating != 0 {
+		t.Errorf("atmosphere_rating bound as %v", req.AtmosphereRating)
+	}
+	req = CreateReviewRequest{}
+	body = `{"album_id": 1, "rating_rhymes": 5, "rating_structure": 5, "rating_implementation": 5, "rating_individuality": 5}`
+	if err := binding.JSON.BindBody([]byte(body), &req); err == nil {
+		t.Error("missing atmosphere_rating passed binding")
+	}
+}
+
+// Переданный пустой text очищает рецензию и отправляет её на модерацию.
+func TestUpdateReviewClearsText(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "clear-text", false)
+	album := seedAlbum(t, db, "clear-text", models.AlbumStatusApproved)
+	review := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	db.Model(&review).Update("text", "старый текст")
+
+	target := fmt.Sprintf("/reviews/%d", review.ID)
+	w := serve(rc.UpdateReview, http.MethodPut, "/reviews/:id", target, `{"text":""}`, &author)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT %s: %d %s", target, w.Code, w.Body.String())
+	}
+	var stored models.Review
+	db.First(&stored, review.ID)
+	if stored.Text != "" || stored.Status != models.ReviewStatusPending {
+		t.Errorf("want empty pending review, got text %q status %s", stored.Text, stored.Status)
+	}
+	if stored.RatingRhymes != 5 {
+		t.Errorf("rating_rhymes changed to %d", stored.RatingRhymes)
+	}
+}
+
+// Без поля text текст не трогается: меняются только переданные оценки.
+func TestUpdateReviewKeepsOmittedText(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "keep-text", false)
+	album := seedAlbum(t, db, "keep-text", models.AlbumStatusApproved)
+	review := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	db.Model(&review).Update("text", "текст остаётся")
+
+	target := fmt.Sprintf("/reviews/%d", review.ID)
+	w := serve(rc.UpdateReview, http.MethodPut, "/reviews/:id", target, `{"rating_rhymes":7}`, &author)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT %s: %d %s", target, w.Code, w.Body.String())
+	}
+	var stored models.Review
+	db.First(&stored, review.ID)
+	if stored.Text != "текст остаётся" || stored.Status != models.ReviewStatusApproved {
+		t.Errorf("want untouched approved text, got %q status %s", stored.Text, stored.Status)
+	}
+	if stored.RatingRhymes != 7 || stored.RatingStructure != 5 {
+		t.Errorf("ratings = %d/%d, want 7/5", stored.RatingRhymes, stored.RatingStructure)
+	}
+}
+
+// При лимите ReviewsPerHour рецензия сверх лимита за час получает 429 с Retry-After.
+func TestCreateReviewRateLimit(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring(), ReviewsPerHour: 2}
+	author := seedUser(t, db, "rate-limited", false)
+	seedAlbumReview(t, db, author.ID, seedAlbum(t, db, "rate-0", models.AlbumStatusApproved).ID, 40)
+
+	create := func(albumID uint) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"album_id": %d, "rating_rhymes": 5, "rating_structure": 5,
+			"rating_implementation": 5, "rating_individuality": 5, "atmosphere_rating": 5}`, albumID)
+		return serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", body, &author)
+	}
+	if w := create(seedAlbum(t, db, "rate-1", models.AlbumStatusApproved).ID); w.Code != http.StatusCreated {
+		t.Fatalf("second review: want 201, got %d %s", w.Code, w.Body.String())
+	}
+	w := create(seedAlbum(t, db, "rate-2", models.AlbumStatusApproved).ID)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third review: want 429, got %d %s", w.Code, w.Body.String())
+	}
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 3600 {
+		t.Errorf("Retry-After = %q", w.Header().Get("Retry-After"))
+	}
+}
+
+// webhookStub поднимает медленный приёмник вебхука: он отдаёт полученный payload
+// в канал и не отвечает, пока тест не закончится.
+func webhookStub(t *testing.T) (string, <-chan reviewWebhookPayload) {
+	t.Helper()
+	received := make(chan reviewWebhookPayload, 4)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload reviewWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode webhook: %v", err)
+		}
+		received <- payload
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	return server.URL, received
+}
+
+// awaitWebhook ждёт один вызов вебхука.
+func awaitWebhook(t *testing.T, received <-chan reviewWebhookPayload) reviewWebhookPayload {
+	t.Helper()
+	select {
+	case payload := <-received:
+		return payload
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+		return reviewWebhookPayload{}
+	}
+}
+
+// Вебхук уходит и при одобрении модератором, и при автопубликации рецензии без
+// текста, а медленный приёмник не задерживает ответ.
+func TestReviewApprovedWebhook(t *testing.T) {
+	db := testDB(t)
+	url, received := webhookStub(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring(), WebhookURL: url, PublicSiteURL: "https://example.test"}
+	album := seedAlbum(t, db, "webhook", models.AlbumStatusApproved)
+	author := seedUser(t, db, "webhook-author", false)
+	admin := seedUser(t, db, "webhook-admin", true)
+
+	pending := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	db.Model(&pending).Updates(map[string]interface{}{"text": "на модерации", "status": models.ReviewStatusPending})
+	start := time.Now()
+	w := serve(rc.ApproveReview, http.MethodPost, "/reviews/:id/approve", fmt.Sprintf("/reviews/%d/approve", pending.ID), "", &admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", w.Code, w.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("approve waited for the webhook: %v", elapsed)
+	}
+	payload := awaitWebhook(t, received)
+	if payload.Event != "review.approved" || payload.Review.ID != pending.ID || payload.User.Username != author.Username ||
+		payload.Album == nil || payload.Album.ID != album.ID {
+		t.Errorf("approve payload = %+v", payload)
+	}
+	if want := fmt.Sprintf("https://example.test/albums/%d", album.ID); payload.Review.URL != want {
+		t.Errorf("review url = %q, want %q", payload.Review.URL, want)
+	}
+
+	other := seedAlbum(t, db, "webhook-auto", models.AlbumStatusApproved)
+	body := fmt.Sprintf(`{"album_id": %d, "rating_rhymes": 6, "rating_structure": 6,
+		"rating_implementation": 6, "rating_individuality": 6, "atmosphere_rating": 6}`, other.ID)
+	start = time.Now()
+	w = serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", body, &author)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("create waited for the webhook: %v", elapsed)
+	}
+	var created models.Review
+	decode(t, w, &created)
+	if payload := awaitWebhook(t, received); payload.Review.ID != created.ID || payload.Album == nil || payload.Album.ID != other.ID {
+		t.Errorf("auto-approve payload = %+v", payload)
+	}
+}
+
+// has_spoilers и content_warning сохраняются при создании и меняются при
+// правке, не отправляя рецензию на модерацию.
+func TestReviewSpoilerFlagsRoundTrip(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "spoiler-author", false)
+	album := seedAlbum(t, db, "spoilers", models.AlbumStatusApproved)
+
+	body := fmt.Sprintf(`{"album_id": %d, "has_spoilers": true, "content_warning": " финал ",
+		"rating_rhymes": 5, "rating_structure": 5, "rating_implementation": 5,
+		"rating_individuality": 5, "atmosphere_rating": 5}`, album.ID)
+	w := serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", body, &author)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var created models.Review
+	decode(t, w, &created)
+	if !created.HasSpoilers || created.ContentWarning != "финал" {
+		t.Errorf("created: has_spoilers %v, content_warning %q", created.HasSpoilers, created.ContentWarning)
+	}
+
+	target := fmt.Sprintf("/reviews/%d", created.ID)
+	w = serve(rc.UpdateReview, http.MethodPut, "/reviews/:id", target, `{"has_spoilers": false, "content_warning": ""}`, &author)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	var updated models.Review
+	decode(t, w, &updated)
+	if updated.HasSpoilers || updated.ContentWarning != "" || updated.Status != models.ReviewStatusApproved {
+		t.Errorf("updated: has_spoilers %v, content_warning %q, status %s", updated.HasSpoilers, updated.ContentWarning, updated.Status)
+	}
+}
+
+// Счётчик для бейджа считает только рецензии на модерации, без удалённых.
+func TestGetPendingReviewCount(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "pending-count-admin", true)
+	count := func() int64 {
+		w := serve(rc.GetPendingReviewCount, http.MethodGet, "/admin/reviews/pending-count", "/admin/reviews/pending-count", "", &admin)
+		if w.Code != http.StatusOK {
+			t.Fatalf("pending count: %d %s", w.Code, w.Body.String())
+		}
+		var body struct {
+			Count int64 `json:"count"`
+		}
+		decode(t, w, &body)
+		return body.Count
+	}
+	before := count()
+
+	album := seedAlbum(t, db, "pending-count", models.AlbumStatusApproved)
+	for i, status := range []models.ReviewStatus{models.ReviewStatusPending, models.ReviewStatusPending, models.ReviewStatusApproved, models.ReviewStatusRejected, models.ReviewStatusPending} {
+		author := seedUser(t, db, fmt.Sprintf("pending-count-%d", i), false)
+		review := seedAlbumReview(t, db, author.ID, album.ID, 40)
+		db.Model(&review).Update("status", status)
+		if i == 4 {
+			db.Delete(&review)
+		}
+	}
+	if after := count(); after != before+2 {
+		t.Errorf("pending count = %d, want %d", after, before+2)
+	}
+}
+
+func TestLikeReviewStatuses(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "liked-review-author", false)
+	user := seedUser(t, db, "review-liker", false)
+	review := seedAlbumReview(t, db, author.ID, seedAlbum(t, db, "liked-review", models.AlbumStatusApproved).ID, 40)
+	checkLikeCycle(t, rc.LikeReview, rc.UnlikeReview, "/reviews/:id/like", fmt.Sprintf("/reviews/%d/like", review.ID), &user)
+}
+
+// Отклонённые рецензии с причиной через ?status= видят только администратор
+// и сам автор; остальным фильтр по статусу не открывает ничего, кроме approved.
+func TestGetReviewsStatusFilterRestricted(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "rejected-author", false)
+	stranger := seedUser(t, db, "rejected-stranger", false)
+	admin := seedUser(t, db, "rejected-admin", true)
+	rejected := seedAlbumReview(t, db, author.ID, seedAlbum(t, db, "rejected", models.AlbumStatusApproved).ID, 40)
+	db.Model(&rejected).Updates(map[string]interface{}{"status": models.ReviewStatusRejected, "rejection_reason": "оскорбления"})
+
+	listed := func(query string, user *models.User) bool {
+		w := serve(rc.GetReviews, http.MethodGet, "/reviews", "/reviews?page_size=100&"+query, "", user)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /reviews?%s: %d %s", query, w.Code, w.Body.String())
+		}
+		var body struct {
+			Reviews []models.Review `json:"reviews"`
+		}
+		decode(t, w, &body)
+		for _, review := range body.Reviews {
+			if review.ID == rejected.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	own := fmt.Sprintf("status=rejected&user_id=%d", author.ID)
+	cases := []struct {
+		name  string
+		query string
+		user  *models.User
+		want  bool
+	}{
+		{"guest", "status=rejected", nil, false},
+		{"guest with user_id", own, nil, false},
+		{"stranger with user_id", own, &stranger, false},
+		{"author without user_id", "status=rejected", &author, false},
+		{"author", own, &author, true},
+		{"admin", "status=rejected", &admin, true},
+	}
+	for _, tc := range cases {
+		if got := listed(tc.query, tc.user); got != tc.want {
+			t.Errorf("%s: rejected review listed = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// Курсорная пагинация: две страницы по created_at DESC без пропусков и
+// повторов, на последней next_cursor = null.
+func TestGetReviewsCursorWalk(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	album := seedAlbum(t, db, "cursor", models.AlbumStatusApproved)
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var want []uint
+	for i := 0; i < 3; i++ {
+		review := seedAlbumReview(t, db, seedUser(t, db, fmt.Sprintf("cursor-%d", i), false).ID, album.ID, 40)
+		db.Model(&review).Update("created_at", base.Add(time.Duration(i)*time.Minute))
+		want = append([]uint{review.ID}, want...)
+	}
+
+	type cursorPage struct {
+		Reviews    []models.Review `json:"reviews"`
+		PageSize   int             `json:"page_size"`
+		NextCursor *string         `json:"next_cursor"`
+	}
+	fetch := func(cursor string) cursorPage {
+		target := fmt.Sprintf("/reviews?album_id=%d&page_size=2&cursor=%s", album.ID, url.QueryEscape(cursor))
+		w := serve(rc.GetReviews, http.MethodGet, "/reviews", target, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", target, w.Code, w.Body.String())
+		}
+		var page cursorPage
+		decode(t, w, &page)
+		return page
+	}
+
+	first := fetch("")
+	if len(first.Reviews) != 2 || first.NextCursor == nil {
+		t.Fatalf("first page: %d reviews, next_cursor %v", len(first.Reviews), first.NextCursor)
+	}
+	second := fetch(*first.NextCursor)
+	if len(second.Reviews) != 1 || second.NextCursor != nil {
+		t.Fatalf("second page: %d reviews, next_cursor %v", len(second.Reviews), second.NextCursor)
+	}
+	var got []uint
+	for _, review := range append(first.Reviews, second.Reviews...) {
+		got = append(got, review.ID)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("walked %v, want %v", got, want)
+	}
+
+	w := serve(rc.GetReviews, http.MethodGet, "/reviews", "/reviews?cursor=garbage", "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid cursor: want 400, got %d", w.Code)
+	}
+}
+
+// has_listened ставит сервер по прослушиваниям автора: значение из запроса
+// игнорируется, а при правке отметка появляется, если автор дослушал позже.
+func TestReviewHasListenedRoundTrip(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "listener", false)
+	track := seedTrack(t, db, seedAlbum(t, db, "listened", models.AlbumStatusApproved).ID, "Listened", 1)
+
+	body := fmt.Sprintf(`{"track_id": %d, "has_listened": true, "rating_rhymes": 5, "rating_structure": 5,
+		"rating_implementation": 5, "rating_individuality": 5, "atmosphere_rating": 5}`, track.ID)
+	w := serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", body, &author)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var created models.Review
+	decode(t, w, &created)
+	if created.HasListened {
+		t.Error("has_listened taken from the request without a listen")
+	}
+
+	mustCreate(t, db, &models.UserTrackListen{UserID: author.ID, TrackID: track.ID, FirstListenedAt: time.Now()})
+	target := fmt.Sprintf("/reviews/%d", created.ID)
+	w = serve(rc.UpdateReview, http.MethodPut, "/reviews/:id", target, `{"rating_rhymes": 6}`, &author)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	var updated models.Review
+	decode(t, w, &updated)
+	if !updated.HasListened {
+		t.Error("has_listened not set after the author listened to the track")
+	}
+
+	w = serve(rc.GetReview, http.MethodGet, "/reviews/:id", target, "", nil)
+	var fetched map[string]interface{}
+	decode(t, w, &fetched)
+	if fetched["has_listened"] != true {
+		t.Errorf("GET review: has_listened = %v", fetched["has_listened"])
+	}
+}
+
+// atmosphere_rating вне шкалы 1-10 отклоняется ошибкой именно этого поля — и
+// при создании, и при правке рецензии.
+func TestReviewAtmosphereRatingOutOfRange(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "atmosphere", false)
+	album := seedAlbum(t, db, "atmosphere", models.AlbumStatusApproved)
+	review := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	target := fmt.Sprintf("/reviews/%d", review.ID)
+
+	for _, rating := range []int{0, 11} {
+		create := fmt.Sprintf(`{"album_id": %d, "rating_rhymes": 5, "rating_structure": 5,
+			"rating_implementation": 5, "rating_individuality": 5, "atmosphere_rating": %d}`, album.ID, rating)
+		update := fmt.Sprintf(`{"atmosphere_rating": %d}`, rating)
+		for name, w := range map[string]*httptest.ResponseRecorder{
+			"create": serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", create, &author),
+			"update": serve(rc.UpdateReview, http.MethodPut, "/reviews/:id", target, update, &author),
+		} {
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s with atmosphere_rating %d: want 400, got %d %s", name, rating, w.Code, w.Body.String())
+				continue
+			}
+			var body utils.ErrorResponse
+			decode(t, w, &body)
+			if len(body.Fields) != 1 || body.Fields[0].Field != "atmosphere_rating" {
+				t.Errorf("%s with atmosphere_rating %d: fields %+v", name, rating, body.Fields)
+			}
+		}
+	}
+
+	var stored models.Review
+	db.First(&stored, review.ID)
+	if stored.AtmosphereRating != review.AtmosphereRating || stored.AtmosphereMultiplier != review.AtmosphereMultiplier {
+		t.Errorf("rejected update changed the review: %d/%v", stored.AtmosphereRating, stored.AtmosphereMultiplier)
+	}
+}
+
+// Альбом на модерации ещё не публичный: рецензия на него и на его трек — 400.
+func TestCreateReviewRejectsPendingAlbum(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "pending-reviewer", false)
+	album := seedAlbum(t, db, "pending-review", models.AlbumStatusPending)
+	track := seedTrack(t, db, album.ID, "Pending", 1)
+
+	ratings := `"rating_rhymes": 5, "rating_structure": 5, "rating_implementation": 5,
+		"rating_individuality": 5, "atmosphere_rating": 5`
+	for name, body := range map[string]string{
+		"album": fmt.Sprintf(`{"album_id": %d, %s}`, album.ID, ratings),
+		"track": fmt.Sprintf(`{"track_id": %d, %s}`, track.ID, ratings),
+	} {
+		w := serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", body, &author)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s of a pending album: want 400, got %d %s", name, w.Code, w.Body.String())
+		}
+	}
+	var count int64
+	db.Model(&models.Review{}).Where("user_id = ?", author.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("%d reviews stored", count)
+	}
+}
+
+// Испорченная вручную средняя альбома и трека восстанавливается по рецензиям
+// и попадает в счётчики *_updated; остальные строки не считаются изменёнными.
+func TestRecomputeRatingsFixesStoredAverage(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "recompute-admin", true)
+	album := seedAlbum(t, db, "recompute", models.AlbumStatusApproved)
+	seedAlbumReview(t, db, admin.ID, album.ID, 40)
+	track := seedTrack(t, db, album.ID, "Recompute", 1)
+	trackID := track.ID
+	mustCreate(t, db, &models.Review{
+		UserID: admin.ID, TrackID: &trackID,
+		RatingRhymes: 5, RatingStructure: 5, RatingImplementation: 5, RatingIndividuality: 5,
+		AtmosphereMultiplier: 1, FinalScore: 30, Status: models.ReviewStatusApproved,
+	})
+
+	type result struct {
+		AlbumsUpdated int `json:"albums_updated"`
+		TracksUpdated int `json:"tracks_updated"`
+	}
+	recompute := func() result {
+		w := serve(rc.RecomputeRatings, http.MethodPost, "/admin/recompute-ratings", "/admin/recompute-ratings", "", &admin)
+		if w.Code != http.StatusOK {
+			t.Fatalf("recompute: %d %s", w.Code, w.Body.String())
+		}
+		var body result
+		decode(t, w, &body)
+		return body
+	}
+	// Первый проход выравнивает всё, что уже есть в базе, второй — только испорченное.
+	recompute()
+	db.Model(&models.Album{}).Where("id = ?", album.ID).Update("average_rating", 99)
+	db.Model(&models.Track{}).Where("id = ?", track.ID).Update("average_rating", 1)
+
+	if got := recompute(); got.AlbumsUpdated != 1 || got.TracksUpdated != 1 {
+		t.Errorf("updated %+v, want one album and one track", got)
+	}
+	var storedAlbum models.Album
+	var storedTrack models.Track
+	db.First(&storedAlbum, album.ID)
+	db.First(&storedTrack, track.ID)
+	if storedAlbum.AverageRating != 40 || storedTrack.AverageRating != 30 {
+		t.Errorf("averages %v / %v, want 40 / 30", storedAlbum.AverageRating, storedTrack.AverageRating)
+	}
+}
+
+// Ошибки разных обработчиков приходят в одном конверте utils.ErrorResponse:
+// error, message, code (равный HTTP-статусу) и fields только у ошибок полей.
+func TestHandlersReturnErrorEnvelope(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	user := seedUser(t, db, "error-envelope", false)
+
+	cases := []struct {
+		name       string
+		w          *httptest.ResponseRecorder
+		code       int
+		wantFields bool
+	}{
+		{"like without auth", serve(rc.LikeReview, http.MethodPost, "/reviews/:id/like", "/reviews/1/like", "", nil), http.StatusUnauthorized, false},
+		{"like missing review", serve(rc.LikeReview, http.MethodPost, "/reviews/:id/like", "/reviews/999999999/like", "", &user), http.StatusNotFound, false},
+		{"create review without ratings", serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", `{"album_id": 1}`, &user), http.StatusBadRequest, true},
+		{"create review with broken JSON", serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", `{"album_id":`, &user), http.StatusBadRequest, false},
+		{"missing album", serve(ac.GetAlbum, http.MethodGet, "/albums/:id", "/albums/999999999", "", nil), http.StatusNotFound, false},
+		{"missing track", serve(tc.GetTrack, http.MethodGet, "/tracks/:id", "/tracks/999999999", "", nil), http.StatusNotFound, false},
+	}
+	allowed := map[string]bool{"error": true, "message": true, "code": true, "fields": true}
+	for _, step := range cases {
+		if step.w.Code != step.code {
+			t.Errorf("%s: want %d, got %d %s", step.name, step.code, step.w.Code, step.w.Body.String())
+			continue
+		}
+		var raw map[string]json.RawMessage
+		decode(t, step.w, &raw)
+		for key := range raw {
+			if !allowed[key] {
+				t.Errorf("%s: unexpected key %q in %s", step.name, key, step.w.Body.String())
+			}
+		}
+		var body utils.ErrorResponse
+		decode(t, step.w, &body)
+		if body.Error == "" || body.Message == "" || body.Code != step.code {
+			t.Errorf("%s: envelope %+v", step.name, body)
+		}
+		if (len(body.Fields) > 0) != step.wantFields {
+			t.Errorf("%s: fields %+v", step.name, body.Fields)
+		}
+	}
+}
+
+// score_breakdown в ответах на создание и получение рецензии сходится с
+// CalculateFinalScore и с сохранённой final_score.
+func TestReviewScoreBreakdownMatchesFinalScore(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "breakdown", false)
+	album := seedAlbum(t, db, "breakdown", models.AlbumStatusApproved)
+
+	body := fmt.Sprintf(`{"album_id": %d, "rating_rhymes": 7, "rating_structure": 8,
+		"rating_implementation": 6, "rating_individuality": 9, "atmosphere_rating": 7}`, album.ID)
+	w := serve(rc.CreateReview, http.MethodPost, "/reviews", "/reviews", body, &author)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var created models.Review
+	decode(t, w, &created)
+
+	want := models.Review{
+		RatingRhymes:         7,
+		RatingStructure:      8,
+		RatingImplementation: 6,
+		RatingIndividuality:  9,
+		AtmosphereMultiplier: rc.Scoring.AtmosphereMultiplier(7),
+	}
+	want.CalculateFinalScore(rc.Scoring)
+
+	w = serve(rc.GetReview, http.MethodGet, "/reviews/:id", fmt.Sprintf("/reviews/%d", created.ID), "", nil)
+	var fetched models.Review
+	decode(t, w, &fetched)
+
+	for name, review := range map[string]models.Review{"create": created, "get": fetched} {
+		b := review.ScoreBreakdown
+		if b == nil {
+			t.Errorf("%s: no score_breakdown", name)
+			continue
+		}
+		if b.BaseSum != 30 || b.AtmosphereRating != 7 || b.AtmosphereMultiplier != want.AtmosphereMultiplier {
+			t.Errorf("%s: breakdown %+v", name, *b)
+		}
+		if b.WeightedSum != float64(b.BaseSum)*b.BaseWeight {
+			t.Errorf("%s: weighted_sum %v != %d × %v", name, b.WeightedSum, b.BaseSum, b.BaseWeight)
+		}
+		if b.FinalScore != want.FinalScore || review.FinalScore != want.FinalScore {
+			t.Errorf("%s: breakdown %v, final_score %v, CalculateFinalScore %v", name, b.FinalScore, review.FinalScore, want.FinalScore)
+		}
+	}
+}
+
+// Карточки альбома и трека отмечают, рецензировал ли их текущий пользователь
+// (включая ещё не одобренную рецензию), и отдают ID его рецензии; гостю
+// поля не отдаются.
+func TestReviewedByMeOnAlbumAndTrack(t *testing.T) {
+	db := testDB(t)
+	ac := &AlbumController{DB: db, Scoring: models.DefaultScoring()}
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	reviewer := seedUser(t, db, "reviewed-by-me", false)
+	stranger := seedUser(t, db, "not-reviewed", false)
+	album := seedAlbum(t, db, "reviewed-by-me", models.AlbumStatusApproved)
+	track := seedTrack(t, db, album.ID, "Reviewed", 1)
+
+	albumReview := seedAlbumReview(t, db, reviewer.ID, album.ID, 40)
+	trackID := track.ID
+	trackReview := models.Review{UserID: reviewer.ID, TrackID: &trackID, RatingRhymes: 5, RatingStructure: 5,
+		RatingImplementation: 5, RatingIndividuality: 5, AtmosphereMultiplier: 1, FinalScore: 40, Status: models.ReviewStatusPending}
+	mustCreate(t, db, &trackReview)
+
+	type card struct {
+		ReviewedByMe *bool `json:"reviewed_by_me"`
+		MyReviewID   *uint `json:"my_review_id"`
+	}
+	fetch := func(name string, w *httptest.ResponseRecorder) card {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", name, w.Code, w.Body.String())
+		}
+		var got card
+		decode(t, w, &got)
+		return got
+	}
+	getAlbum := func(user *models.User) card {
+		return fetch("album", serve(ac.GetAlbum, http.MethodGet, "/albums/:id", fmt.Sprintf("/albums/%d", album.ID), "", user))
+	}
+	getTrack := func(user *models.User) card {
+		return fetch("track", serve(tc.GetTrack, http.MethodGet, "/tracks/:id", fmt.Sprintf("/tracks/%d", track.ID), "", user))
+	}
+
+	for name, check := range map[string]struct {
+		got  card
+		want uint
+	}{
+		"album": {getAlbum(&reviewer), albumReview.ID},
+		"track": {getTrack(&reviewer), trackReview.ID},
+	} {
+		if check.got.ReviewedByMe == nil || !*check.got.ReviewedByMe || check.got.MyReviewID == nil || *check.got.MyReviewID != check.want {
+			t.Errorf("%s, reviewer: %+v, want reviewed with review %d", name, check.got, check.want)
+		}
+	}
+	for name, got := range map[string]card{"album": getAlbum(&stranger), "track": getTrack(&stranger)} {
+		if got.ReviewedByMe == nil || *got.ReviewedByMe || got.MyReviewID != nil {
+			t.Errorf("%s, stranger: %+v, want reviewed_by_me=false without review id", name, got)
+		}
+	}
+	for name, got := range map[string]card{"album": getAlbum(nil), "track": getTrack(nil)} {
+		if got.ReviewedByMe != nil || got.MyReviewID != nil {
+			t.Errorf("%s, guest: %+v, want no fields", name, got)
+		}
+	}
+}
+
+// Одобренная рецензия несёт moderated_at для всех, а кто её проверил
+// (moderated_by, moderator_username) — только администратору и автору: и в
+// GET /reviews/:id, и в списке GET /reviews.
+func TestReviewModeratorVisibility(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "moderator-admin", true)
+	author := seedUser(t, db, "moderated-author", false)
+	stranger := seedUser(t, db, "moderated-stranger", false)
+	album := seedAlbum(t, db, "moderated", models.AlbumStatusApproved)
+	review := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	db.Model(&review).Update("status", models.ReviewStatusPending)
+
+	target := fmt.Sprintf("/reviews/%d", review.ID)
+	w := serve(rc.ApproveReview, http.MethodPost, "/reviews/:id/approve", target+"/approve", "", &admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve: %d %s", w.Code, w.Body.String())
+	}
+
+	check := func(name string, got map[string]interface{}, full bool) {
+		t.Helper()
+		if got["moderated_at"] == nil {
+			t.Errorf("%s: no moderated_at", name)
+		}
+		if !full {
+			if got["moderated_by"] != nil || got["moderator_username"] != nil {
+				t.Errorf("%s: moderator exposed: %v / %v", name, got["moderated_by"], got["moderator_username"])
+			}
+			return
+		}
+		if got["moderated_by"] != float64(admin.ID) || got["moderator_username"] != admin.Username {
+			t.Errorf("%s: moderator %v / %v, want %d / %s", name, got["moderated_by"], got["moderator_username"], admin.ID, admin.Username)
+		}
+	}
+	var approved map[string]interface{}
+	decode(t, w, &approved)
+	check("approve response", approved, true)
+
+	listTarget := fmt.Sprintf("/reviews?album_id=%d", album.ID)
+	for name, viewer := range map[string]struct {
+		user *models.User
+		full bool
+	}{
+		"admin":    {&admin, true},
+		"author":   {&author, true},
+		"stranger": {&stranger, false},
+		"guest":    {nil, false},
+	} {
+		var got map[string]interface{}
+		decode(t, serve(rc.GetReview, http.MethodGet, "/reviews/:id", target, "", viewer.user), &got)
+		check(name+", GET review", got, viewer.full)
+
+		var list struct {
+			Reviews []map[string]interface{} `json:"reviews"`
+		}
+		decode(t, serve(rc.GetReviews, http.MethodGet, "/reviews", listTarget, "", viewer.user), &list)
+		if len(list.Reviews) != 1 {
+			t.Fatalf("%s, list: %d reviews", name, len(list.Reviews))
+		}
+		check(name+", list", list.Reviews[0], viewer.full)
+	}
+}
+
+// GET /reviews?target=album|track оставляет рецензии только на альбомы или
+// только на треки, total считается по той же выборке; иное значение — 400.
+func TestGetReviewsTargetFilter(t *testing.T) {
+	db := testDB(t)
+	rc := &ReviewController{DB: db, Scoring: models.DefaultScoring()}
+	author := seedUser(t, db, "target-filter", false)
+	album := seedAlbum(t, db, "target-filter", models.AlbumStatusApproved)
+	albumReview := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	trackReviews := make(map[uint]bool)
+	for number := 1; number <= 2; number++ {
+		trackID := seedTrack(t, db, album.ID, fmt.Sprintf("Target %d", number), number).ID
+		review := models.Review{UserID: author.ID, TrackID: &trackID, RatingRhymes: 5, RatingStructure: 5,
+			RatingImplementation: 5, RatingIndividuality: 5, AtmosphereMultiplier: 1, FinalScore: 40, Status: models.ReviewStatusApproved}
+		mustCreate(t, db, &review)
+		trackReviews[review.ID] = true
+	}
+
+	list := func(target string) (map[uint]bool, int64) {
+		t.Helper()
+		query := fmt.Sprintf("/reviews?user_id=%d&page_size=100&target=%s", author.ID, target)
+		w := serve(rc.GetReviews, http.MethodGet, "/reviews", query, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", query, w.Code, w.Body.String())
+		}
+		var body struct {
+			Reviews []models.Review `json:"reviews"`
+			Total   int64           `json:"total"`
+		}
+		decode(t, w, &body)
+		ids := make(map[uint]bool, len(body.Reviews))
+		for _, review := range body.Reviews {
+			ids[review.ID] = true
+		}
+		return ids, body.Total
+	}
+
+	if ids, total := list("album"); len(ids) != 1 || !ids[albumReview.ID] || total != 1 {
+		t.Errorf("target=album: %v (total %d), want only %d", ids, total, albumReview.ID)
+	}
+	if ids, total := list("track"); fmt.Sprint(ids) != fmt.Sprint(trackReviews) || total != 2 {
+		t.Errorf("target=track: %v (total %d), want %v", ids, total, trackReviews)
+	}
+	if ids, total := list(""); len(ids) != 3 || total != 3 {
+		t.Errorf("no target: %v (total %d), want all 3", ids, total)
+	}
+
+	w := serve(rc.GetReviews, http.MethodGet, "/reviews", "/reviews?target=artist", "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("target=artist: want 400, got %d", w.Code)
+	}
+}
@@ -1,16 +1,25 @@
 package controllers
 
 import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"music-review-site/backend/middleware"
 	"music-review-site/backend/models"
 	"music-review-site/backend/utils"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type SearchController struct {
-	DB *gorm.DB
+	DB  *gorm.DB
+	Log *SearchLog // Журнал запросов для статистики; nil — запросы не пишутся
 }
 
 // ArtistSearchResult represents artist search result
@@ -25,51 +34,241 @@ type SearchResponse struct {
 	Artists []ArtistSearchResult `json:"artists"`
 	Albums  []models.Album       `json:"albums"`
 	Tracks  []TrackSearchResult  `json:"tracks"`
+	Users   []UserSearchResult   `json:"users"`
+	Reviews []ReviewSearchResult `json:"reviews"`
+}
+
+// UserSearchResult represents a user found by username
+type UserSearchResult struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	AvatarPath  string `json:"avatar_path"`
+	ReviewCount int64  `json:"review_count"` // Одобренные рецензии
+}
+
+// ReviewSearchResult represents an approved review matched by text.
+// Фрагмент разбит на части до, само совпадение и после — для подсветки.
+type ReviewSearchResult struct {
+	ID            uint    `json:"id"`
+	ExcerptBefore string  `json:"excerpt_before"`
+	ExcerptMatch  string  `json:"excerpt_match"`
+	ExcerptAfter  string  `json:"excerpt_after"`
+	AlbumID       *uint   `json:"album_id"`
+	TrackID       *uint   `json:"track_id"`
+	Title         string  `json:"title"` // Название альбома или трека
+	Artist        string  `json:"artist"`
+	UserID        uint    `json:"user_id"`
+	Username      string  `json:"username"`
+	FinalScore    float64 `json:"final_score"`
+}
+
+// searchTypes — разделы поиска; без параметра types ищем во всех.
+var searchTypes = []string{"artists", "albums", "tracks", "users", "reviews"}
+
+// reviewExcerptRadius — сколько символов контекста вокруг совпадения в рецензии.
+const reviewExcerptRadius = 60
+
+// parseSearchTypes parses ?types=albums,tracks. Unknown names are an error so
+// typos don't silently return empty sections.
+func parseSearchTypes(raw string) (map[string]bool, error) {
+	selected := make(map[string]bool, len(searchTypes))
+	if strings.TrimSpace(raw) == "" {
+		for _, name := range searchTypes {
+			selected[name] = true
+		}
+		return selected, nil
+	}
+	known := make(map[string]bool, len(searchTypes))
+	for _, name := range searchTypes {
+		known[name] = true
+	}
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown search type %q (allowed: %s)", name, strings.Join(searchTypes, ", "))
+		}
+		selected[name] = true
+	}
+	return selected, nil
 }
 
 // TrackSearchResult represents track with album info for search
 type TrackSearchResult struct {
-	ID             uint   `json:"id"`
-	Title          string `json:"title"`
-	AlbumID        uint   `json:"album_id"`
-	AlbumTitle     string `json:"album_title"`
-	Artist         string `json:"artist"`
-	CoverImagePath string `json:"cover_image_path"`
+	ID                   uint    `json:"id"`
+	Title                string  `json:"title"`
+	AlbumID              uint    `json:"album_id"`
+	AlbumTitle           string  `json:"album_title"`
+	Artist               string  `json:"artist"`
+	CoverImagePath       string  `json:"cover_image_path"`
+	AverageRating        float64 `json:"average_rating"`
+	ApprovedReviewsCount int64   `json:"approved_reviews_count"`
 }
 
 // Search performs search across albums and tracks
 func (sc *SearchController) Search(c *gin.Context) {
 	query := c.Query("q")
 	limit := 5 // Limit results for autocomplete
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 && parsedLimit <= 20 {
+			limit = parsedLimit
+		}
+	}
 
-	if query == "" {
-		c.JSON(http.StatusOK, SearchResponse{
-			Artists: []ArtistSearchResult{},
-			Albums:  []models.Album{},
-			Tracks:  []TrackSearchResult{},
+	types, err := parseSearchTypes(c.Query("types"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
 		})
 		return
 	}
 
-	// Search for unique artists
+	// Невыбранные разделы остаются пустыми массивами: форма ответа не меняется.
+	response := SearchResponse{
+		Artists: []ArtistSearchResult{},
+		Albums:  []models.Album{},
+		Tracks:  []TrackSearchResult{},
+		Users:   []UserSearchResult{},
+		Reviews: []ReviewSearchResult{},
+	}
+	if query == "" {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Ищем и по исходному запросу, и по транслитерации: "basta" находит «Баста».
+	terms := utils.SearchVariants(query)
+	if types["artists"] {
+		if response.Artists, err = sc.withRequest(c).searchArtists(terms, artistAutocompleteOrder, 0, limit); err != nil {
+			respondSearchError(c, "artists")
+			return
+		}
+	}
+	if types["albums"] {
+		if response.Albums, err = sc.withRequest(c).searchAlbums(terms, albumAutocompleteOrder, 0, limit); err != nil {
+			respondSearchError(c, "albums")
+			return
+		}
+	}
+	if types["tracks"] {
+		searchLyrics := c.Query("search_lyrics") == "true" || c.Query("search_lyrics") == "1"
+		if response.Tracks, err = sc.withRequest(c).searchTracks(terms, searchLyrics, trackAutocompleteOrder, 0, limit); err != nil {
+			respondSearchError(c, "tracks")
+			return
+		}
+	}
+	if types["users"] {
+		if response.Users, err = sc.withRequest(c).searchUsers(terms, limit); err != nil {
+			respondSearchError(c, "users")
+			return
+		}
+	}
+	if types["reviews"] {
+		if response.Reviews, err = sc.withRequest(c).searchReviews(terms, limit); err != nil {
+			respondSearchError(c, "reviews")
+			return
+		}
+	}
+
+	sc.recordSearch(c, query, len(response.Artists)+len(response.Albums)+len(response.Tracks)+len(response.Users)+len(response.Reviews))
+	c.JSON(http.StatusOK, response)
+}
+
+func respondSearchError(c *gin.Context, section string) {
+	c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+		Error:   "Internal Server Error",
+		Message: "Failed to search " + section,
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+// Порядок автодополнения; страницы результатов сортируются по белым спискам ниже.
+const (
+	artistAutocompleteOrder = "album_count DESC, artist ASC"
+	albumAutocompleteOrder  = "created_at DESC"
+	trackAutocompleteOrder  = "relevance ASC, tracks.average_rating DESC, tracks.created_at DESC"
+)
+
+// Белые списки сортировки страниц поиска (sort_by → ORDER BY).
+var (
+	artistSearchSortColumns = map[string]string{
+		"album_count": "album_count",
+		"name":        "artist",
+	}
+	trackSearchSortColumns = map[string]string{
+		"relevance":      "relevance",
+		"created_at":     "tracks.created_at",
+		"average_rating": "tracks.average_rating",
+		"title":          "tracks.title",
+	}
+)
+
+// ilikeAny builds "(col ILIKE ? OR …)" over every column × query variant.
+// prefix=true ищет совпадение с начала значения ("term%"), иначе — вхождение.
+func ilikeAny(columns, terms []string, prefix bool) (string, []interface{}) {
+	conditions := make([]string, 0, len(columns)*len(terms))
+	args := make([]interface{}, 0, len(columns)*len(terms))
+	for _, column := range columns {
+		for _, term := range terms {
+			conditions = append(conditions, column+" ILIKE ?")
+			if prefix {
+				args = append(args, term+"%")
+			} else {
+				args = append(args, "%"+term+"%")
+			}
+		}
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// artistSearchQuery matches approved albums by artist. Общий для автодополнения
+// и страницы артистов, чтобы условия совпадения не разъехались.
+func (sc *SearchController) artistSearchQuery(terms []string) *gorm.DB {
+	match, args := ilikeAny([]string{"artist"}, terms, false)
+	return sc.DB.Model(&models.Album{}).
+		Where("status = ?", models.AlbumStatusApproved).
+		Where(match, args...)
+}
+
+// albumSearchQuery matches approved albums by title or artist.
+func (sc *SearchController) albumSearchQuery(terms []string) *gorm.DB {
+	match, args := ilikeAny([]string{"title", "artist"}, terms, false)
+	return sc.DB.Model(&models.Album{}).
+		Where("status = ?", models.AlbumStatusApproved).
+		Where(match, args...)
+}
+
+// trackSearchQuery matches tracks of approved albums by title, album, artist or lyrics.
+func (sc *SearchController) trackSearchQuery(terms []string, searchLyrics bool) *gorm.DB {
+	columns := []string{"tracks.title", "albums.title", "albums.artist"}
+	// Поиск по тексту песен — только по явному флагу: ILIKE по большим текстам дороже.
+	if searchLyrics {
+		columns = append(columns, "tracks.lyrics")
+	}
+	match, args := ilikeAny(columns, terms, false)
+	return sc.DB.Model(&models.Track{}).
+		Joins("JOIN albums ON tracks.album_id = albums.id AND albums.deleted_at IS NULL AND albums.status = ?", models.AlbumStatusApproved).
+		Where(match, args...)
+}
+
+// searchArtists finds unique artists of approved albums.
+func (sc *SearchController) searchArtists(terms []string, order string, offset, limit int) ([]ArtistSearchResult, error) {
 	var artistResults []struct {
-		Artist string
-		Count  int64
+		Artist     string
+		AlbumCount int64
 	}
-	artistQuery := sc.DB.Model(&models.Album{}).
-		Select("artist, COUNT(*) as count").
-		Where("artist ILIKE ?", "%"+query+"%").
+	if err := sc.artistSearchQuery(terms).
+		Select("artist, COUNT(*) AS album_count").
 		Group("artist").
-		Order("count DESC").
-		Limit(limit)
-
-	if err := artistQuery.Scan(&artistResults).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to search artists",
-			Code:    http.StatusInternalServerError,
-		})
-		return
+		Order(order).
+		Offset(offset).
+		Limit(limit).
+		Scan(&artistResults).Error; err != nil {
+		return nil, err
 	}
 
 	// Get first album cover for each artist
@@ -77,73 +276,432 @@ func (sc *SearchController) Search(c *gin.Context) {
 	for i, result := range artistResults {
 		// Get first album for this artist to use as avatar
 		var firstAlbum models.Album
-		sc.DB.Where("artist = ?", result.Artist).
+		sc.DB.Where("artist = ? AND status = ?", result.Artist, models.AlbumStatusApproved).
 			Order("created_at ASC").
 			First(&firstAlbum)
-		
+
 		artists[i] = ArtistSearchResult{
 			Name:           result.Artist,
-			Count:          int(result.Count),
+			Count:          int(result.AlbumCount),
 			CoverImagePath: firstAlbum.CoverImagePath,
 		}
 	}
 
-	var albums []models.Album
-	albumQuery := sc.DB.Model(&models.Album{}).
+	return artists, nil
+}
+
+// searchAlbums finds approved albums by title or artist.
+func (sc *SearchController) searchAlbums(terms []string, order string, offset, limit int) ([]models.Album, error) {
+	albums := []models.Album{}
+	if err := sc.albumSearchQuery(terms).
 		Preload("Genre").
-		Where("title ILIKE ? OR artist ILIKE ?", "%"+query+"%", "%"+query+"%").
+		Order(order).
+		Offset(offset).
 		Limit(limit).
-		Order("created_at DESC")
-
-	if err := albumQuery.Find(&albums).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to search albums",
-			Code:    http.StatusInternalServerError,
-		})
-		return
+		Find(&albums).Error; err != nil {
+		return nil, err
 	}
+	return albums, nil
+}
 
-	var tracks []models.Track
-	trackQuery := sc.DB.Model(&models.Track{}).
-		Preload("Album").
-		Joins("JOIN albums ON tracks.album_id = albums.id").
-		Where("tracks.title ILIKE ? OR albums.title ILIKE ? OR albums.artist ILIKE ?",
-			"%"+query+"%", "%"+query+"%", "%"+query+"%").
+// searchTracks finds tracks of approved albums. В order доступна колонка relevance:
+// 0 — название трека начинается с запроса.
+func (sc *SearchController) searchTracks(terms []string, searchLyrics bool, order string, offset, limit int) ([]TrackSearchResult, error) {
+	var trackRows []struct {
+		ID                   uint
+		Title                string
+		AlbumID              uint
+		CoverImagePath       string
+		AlbumTitle           string
+		Artist               string
+		AlbumCoverImagePath  string
+		AverageRating        float64
+		ApprovedReviewsCount int64
+	}
+	titlePrefix, prefixArgs := ilikeAny([]string{"tracks.title"}, terms, true)
+	if err := sc.trackSearchQuery(terms, searchLyrics).
+		Select(`tracks.id, tracks.title, tracks.album_id, tracks.cover_image_path, tracks.average_rating,
+			albums.title AS album_title, albums.artist, albums.cover_image_path AS album_cover_image_path,
+			(SELECT COUNT(*) FROM reviews
+			 WHERE reviews.track_id = tracks.id AND reviews.status = ? AND reviews.deleted_at IS NULL
+			) AS approved_reviews_count,
+			CASE WHEN `+titlePrefix+` THEN 0 ELSE 1 END AS relevance`,
+			append([]interface{}{models.ReviewStatusApproved}, prefixArgs...)...).
+		Order(order).
+		Offset(offset).
 		Limit(limit).
-		Order("tracks.created_at DESC")
-
-	if err := trackQuery.Find(&tracks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to search tracks",
-			Code:    http.StatusInternalServerError,
-		})
-		return
+		Scan(&trackRows).Error; err != nil {
+		return nil, err
 	}
 
 	// Convert tracks to search results
-	trackResults := make([]TrackSearchResult, len(tracks))
-	for i, track := range tracks {
+	trackResults := make([]TrackSearchResult, len(trackRows))
+	for i, row := range trackRows {
 		// Use track cover if available, otherwise use album cover
-		coverImagePath := track.CoverImagePath
+		coverImagePath := row.CoverImagePath
 		if coverImagePath == "" {
-			coverImagePath = track.Album.CoverImagePath
+			coverImagePath = row.AlbumCoverImagePath
 		}
 
 		trackResults[i] = TrackSearchResult{
-			ID:             track.ID,
-			Title:          track.Title,
-			AlbumID:        track.AlbumID,
-			AlbumTitle:     track.Album.Title,
-			Artist:         track.Album.Artist,
-			CoverImagePath: coverImagePath,
+			ID:                   row.ID,
+			Title:                row.Title,
+			AlbumID:              row.AlbumID,
+			AlbumTitle:           row.AlbumTitle,
+			Artist:               row.Artist,
+			CoverImagePath:       coverImagePath,
+			AverageRating:        row.AverageRating,
+			ApprovedReviewsCount: row.ApprovedReviewsCount,
 		}
 	}
 
-	c.JSON(http.StatusOK, SearchResponse{
-		Artists: artists,
-		Albums:  albums,
-		Tracks:  trackResults,
-	})
+	return trackResults, nil
+}
+
+// searchPageParams reads q, page and page_size of a paginated search page.
+func searchPageParams(c *gin.Context) (terms []string, page utils.Page) {
+	if query := strings.TrimSpace(c.Query("q")); query != "" {
+		terms = utils.SearchVariants(query)
+	}
+	return terms, utils.ParsePage(c)
+}
+
+// SearchArtistsPage returns all matching artists with pagination
+// (sort_by=album_count|name).
+func (sc *SearchController) SearchArtistsPage(c *gin.Context) {
+	terms, page := searchPageParams(c)
+	artists := []ArtistSearchResult{}
+	var total int64
+	if len(terms) > 0 {
+		if err := sc.withRequest(c).artistSearchQuery(terms).Distinct("artist").Count(&total).Error; err != nil {
+			respondSearchError(c, "artists")
+			return
+		}
+		order := utils.SafeOrderClause(c.Query("sort_by"), c.Query("sort_order"), artistSearchSortColumns, "album_count") + ", artist ASC"
+		var err error
+		if artists, err = sc.withRequest(c).searchArtists(terms, order, page.Offset(), page.Size); err != nil {
+			respondSearchError(c, "artists")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("artists", artists, total, page))
+}
+
+// SearchAlbumsPage returns all matching albums with pagination; сортировка —
+// тот же белый список, что у GET /albums.
+func (sc *SearchController) SearchAlbumsPage(c *gin.Context) {
+	terms, page := searchPageParams(c)
+	albums := []models.Album{}
+	var total int64
+	if len(terms) > 0 {
+		if err := sc.withRequest(c).albumSearchQuery(terms).Count(&total).Error; err != nil {
+			respondSearchError(c, "albums")
+			return
+		}
+		order := utils.SafeOrderClause(c.Query("sort_by"), c.Query("sort_order"), albumSortColumns, "created_at") + ", id DESC"
+		var err error
+		if albums, err = sc.withRequest(c).searchAlbums(terms, order, page.Offset(), page.Size); err != nil {
+			respondSearchError(c, "albums")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("albums", albums, total, page))
+}
+
+// SearchTracksPage returns all matching tracks with pagination
+// (sort_by=relevance|created_at|average_rating|title, по умолчанию relevance).
+func (sc *SearchController) SearchTracksPage(c *gin.Context) {
+	terms, page := searchPageParams(c)
+	searchLyrics := c.Query("search_lyrics") == "true" || c.Query("search_lyrics") == "1"
+	tracks := []TrackSearchResult{}
+	var total int64
+	if len(terms) > 0 {
+		if err := sc.withRequest(c).trackSearchQuery(terms, searchLyrics).Count(&total).Error; err != nil {
+			respondSearchError(c, "tracks")
+			return
+		}
+		// relevance по возрастанию означает «лучшие совпадения первыми», поэтому
+		// направление по умолчанию для него ASC.
+		sortOrder := c.Query("sort_order")
+		if sortOrder == "" && (c.Query("sort_by") == "" || c.Query("sort_by") == "relevance") {
+			sortOrder = "asc"
+		}
+		order := utils.SafeOrderClause(c.Query("sort_by"), sortOrder, trackSearchSortColumns, "relevance") +
+			", tracks.average_rating DESC, tracks.id DESC"
+		var err error
+		if tracks, err = sc.withRequest(c).searchTracks(terms, searchLyrics, order, page.Offset(), page.Size); err != nil {
+			respondSearchError(c, "tracks")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("tracks", tracks, total, page))
+}
+
+// searchUsers finds users by username: сначала совпадения с начала имени,
+// затем самые активные авторы.
+func (sc *SearchController) searchUsers(terms []string, limit int) ([]UserSearchResult, error) {
+	var rows []struct {
+		UserSearchResult
+		Relevance int
+	}
+	usernamePrefix, prefixArgs := ilikeAny([]string{"users.username"}, terms, true)
+	match, args := ilikeAny([]string{"users.username"}, terms, false)
+	err := sc.DB.Model(&models.User{}).
+		Select(`users.id, users.username, users.avatar_path,
+			(SELECT COUNT(*) FROM reviews
+			 WHERE reviews.user_id = users.id AND reviews.status = ? AND reviews.deleted_at IS NULL
+			) AS review_count,
+			CASE WHEN `+usernamePrefix+` THEN 0 ELSE 1 END AS relevance`,
+			append([]interface{}{models.ReviewStatusApproved}, prefixArgs...)...).
+		Where(match, args...).
+		Order("relevance ASC, review_count DESC, users.id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	users := make([]UserSearchResult, len(rows))
+	for i, row := range rows {
+		users[i] = row.UserSearchResult
+	}
+	return users, nil
+}
+
+// searchReviews finds approved reviews of approved releases by text.
+func (sc *SearchController) searchReviews(terms []string, limit int) ([]ReviewSearchResult, error) {
+	var rows []struct {
+		ID         uint
+		Text       string
+		AlbumID    *uint
+		TrackID    *uint
+		Title      string
+		Artist     string
+		UserID     uint
+		Username   string
+		FinalScore float64
+	}
+	match, args := ilikeAny([]string{"reviews.text"}, terms, false)
+	err := sc.DB.Model(&models.Review{}).
+		Select(`reviews.id, reviews.text, reviews.album_id, reviews.track_id, reviews.user_id, reviews.final_score,
+			COALESCE(tracks.title, albums.title) AS title,
+			COALESCE(track_albums.artist, albums.artist) AS artist,
+			users.username`).
+		Joins("JOIN users ON users.id = reviews.user_id").
+		Joins("LEFT JOIN albums ON albums.id = reviews.album_id AND albums.deleted_at IS NULL").
+		Joins("LEFT JOIN tracks ON tracks.id = reviews.track_id AND tracks.deleted_at IS NULL").
+		Joins("LEFT JOIN albums AS track_albums ON track_albums.id = tracks.album_id AND track_albums.deleted_at IS NULL").
+		Where("reviews.status = ?", models.ReviewStatusApproved).
+		Where(match, args...).
+		Where("COALESCE(track_albums.status, albums.status) = ?", models.AlbumStatusApproved).
+		Order("reviews.created_at DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ReviewSearchResult, len(rows))
+	for i, row := range rows {
+		// Совпасть мог любой из вариантов запроса — подсвечиваем первый найденный.
+		var before, match, after string
+		for _, term := range terms {
+			var found bool
+			if before, match, after, found = utils.ExcerptAround(row.Text, term, reviewExcerptRadius); found {
+				break
+			}
+		}
+		results[i] = ReviewSearchResult{
+			ID:            row.ID,
+			ExcerptBefore: before,
+			ExcerptMatch:  match,
+			ExcerptAfter:  after,
+			AlbumID:       row.AlbumID,
+			TrackID:       row.TrackID,
+			Title:         row.Title,
+			Artist:        row.Artist,
+			UserID:        row.UserID,
+			Username:      row.Username,
+			FinalScore:    row.FinalScore,
+		}
+	}
+	return results, nil
+}
+
+// Подсказки «возможно, вы имели в виду»: не больше suggestLimit вариантов с
+// похожестью (pg_trgm similarity) не ниже suggestMinSimilarity.
+const (
+	suggestLimit         = 5
+	suggestMinSimilarity = 0.3
+)
+
+// SearchSuggestion is a close match for a query that found nothing.
+type SearchSuggestion struct {
+	Type   string  `json:"type"`             // artist | album | track
+	ID     *uint   `json:"id"`               // nil для артиста
+	Text   string  `json:"text"`             // Имя артиста или название
+	Artist string  `json:"artist,omitempty"` // Для альбомов и треков
+	Score  float64 `json:"score"`            // similarity, для отладки порога
+}
+
+// SearchSuggest returns up to 5 artists, album and track titles similar to q.
+// Требует pg_trgm (см. миграцию 0022); без расширения запрос падает — 500.
+func (sc *SearchController) SearchSuggest(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	suggestions := []SearchSuggestion{}
+	if query == "" {
+		c.JSON(http.StatusOK, gin.H{"query": query, "suggestions": suggestions})
+		return
+	}
+
+	// Оператор % отбирает кандидатов по GIN-индексу (порог pg_trgm по умолчанию
+	// тоже 0.3), similarity() даёт сам балл для сортировки и ответа.
+	if err := requestDB(c, sc.DB).Raw(`
+		SELECT * FROM (
+			SELECT 'artist' AS type, NULL AS id, artist AS text, '' AS artist, MAX(similarity(artist, @q)) AS score
+			FROM albums
+			WHERE deleted_at IS NULL AND status = @status AND artist % @q
+			GROUP BY artist
+			UNION ALL
+			SELECT 'album', id, title, artist, similarity(title, @q)
+			FROM albums
+			WHERE deleted_at IS NULL AND status = @status AND title % @q
+			UNION ALL
+			SELECT 'track', tracks.id, tracks.title, albums.artist, similarity(tracks.title, @q)
+			FROM tracks
+			JOIN albums ON tracks.album_id = albums.id AND albums.deleted_at IS NULL AND albums.status = @status
+			WHERE tracks.deleted_at IS NULL AND tracks.title % @q
+		) AS candidates
+		WHERE score >= @min
+		ORDER BY score DESC, text ASC
+		LIMIT @limit`,
+		sql.Named("q", query),
+		sql.Named("status", models.AlbumStatusApproved),
+		sql.Named("min", suggestMinSimilarity),
+		sql.Named("limit", suggestLimit),
+	).Scan(&suggestions).Error; err != nil {
+		middleware.Logger(c).Error("search suggest failed (pg_trgm installed?)", "error", err)
+		respondSearchError(c, "suggestions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"query": query, "suggestions": suggestions})
+}
+
+// searchLogMinLength — запросы короче (в символах) не пишем: это в основном
+// промежуточный ввод автодополнения.
+const searchLogMinLength = 3
+
+// searchStatsWindow — окно для популярных запросов и запросов без результатов.
+const searchStatsWindow = 7 * 24 * time.Hour
+
+// normalizeSearchQuery приводит запрос к виду, по которому он группируется в журнале.
+func normalizeSearchQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// searchLogQueueSize — сколько запросов может ждать записи в журнал. При
+// переполнении новые записи отбрасываются: статистика поиска не стоит того,
+// чтобы копить горутины или задерживать ответ.
+const searchLogQueueSize = 256
+
+// SearchLog пишет журнал поиска (search_queries) одной фоновой горутиной
+// через очередь фиксированного размера.
+type SearchLog struct {
+	db    *gorm.DB
+	queue chan models.SearchQuery
+}
+
+// NewSearchLog starts the writer goroutine; она живёт, пока жив процесс.
+func NewSearchLog(db *gorm.DB) *SearchLog {
+	l := &SearchLog{db: db, queue: make(chan models.SearchQuery, searchLogQueueSize)}
+	go l.run()
+	return l
+}
+
+func (l *SearchLog) run() {
+	for entry := range l.queue {
+		if err := l.db.Create(&entry).Error; err != nil {
+			slog.Warn("failed to record search query", "error", err)
+		}
+	}
+}
+
+// enqueue ставит запись в очередь, не блокируясь; при полной очереди запись теряется.
+func (l *SearchLog) enqueue(entry models.SearchQuery) {
+	select {
+	case l.queue <- entry:
+	default:
+		slog.Warn("search log queue is full, query dropped")
+	}
+}
+
+// recordSearch ставит запрос в очередь журнала поиска, чтобы не задерживать ответ.
+func (sc *SearchController) recordSearch(c *gin.Context, query string, resultsCount int) {
+	if sc.Log == nil {
+		return
+	}
+	normalized := normalizeSearchQuery(query)
+	if utf8.RuneCountInString(normalized) < searchLogMinLength {
+		return
+	}
+	entry := models.SearchQuery{Query: normalized, ResultsCount: resultsCount}
+	if userID, ok := middleware.GetUserIDFromContext(c); ok {
+		entry.UserID = &userID
+	}
+	sc.Log.enqueue(entry)
+}
+
+// SearchQueryStat is a normalized query with the number of times it was searched.
+type SearchQueryStat struct {
+	Query          string    `json:"query"`
+	Count          int64     `json:"count"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// searchQueryStats groups logged queries of the last week; withResults
+// выбирает запросы с результатами (true) или без них (false).
+func (sc *SearchController) searchQueryStats(withResults bool, limit int) ([]SearchQueryStat, error) {
+	stats := []SearchQueryStat{}
+	condition := "results_count = 0"
+	if withResults {
+		condition = "results_count > 0"
+	}
+	err := sc.DB.Model(&models.SearchQuery{}).
+		Select("query, COUNT(*) AS count, MAX(created_at) AS last_searched_at").
+		Where("created_at > ?", time.Now().Add(-searchStatsWindow)).
+		Where(condition).
+		Group("query").
+		Order("count DESC, last_searched_at DESC").
+		Limit(limit).
+		Scan(&stats).Error
+	return stats, err
+}
+
+// GetTrendingSearches returns the 10 most frequent queries of the last 7 days
+// that produced results.
+func (sc *SearchController) GetTrendingSearches(c *gin.Context) {
+	stats, err := sc.withRequest(c).searchQueryStats(true, 10)
+	if err != nil {
+		respondSearchError(c, "trending queries")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"queries": stats})
+}
+
+// AdminZeroResultSearches returns frequent queries of the last 7 days that found
+// nothing — подсказка, чего не хватает в каталоге (limit до 100, по умолчанию 50).
+func (sc *SearchController) AdminZeroResultSearches(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	stats, err := sc.withRequest(c).searchQueryStats(false, limit)
+	if err != nil {
+		respondSearchError(c, "zero-result queries")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"queries": stats})
 }
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"music-review-site/backend/models"
+)
+
+// Поиск находит одобренную рецензию по тексту и отдаёт фрагмент с совпадением,
+// автора и альбом; рецензия на модерации с тем же словом не находится.
+func TestSearchFindsReviewByText(t *testing.T) {
+	db := testDB(t)
+	sc := &SearchController{DB: db}
+	album := seedAlbum(t, db, "search-reviews", models.AlbumStatusApproved)
+	author := seedUser(t, db, "search-reviewer", false)
+	other := seedUser(t, db, "search-pending", false)
+	approved := seedAlbumReview(t, db, author.ID, album.ID, 40)
+	db.Model(&approved).Update("text", "Звук плотный, а припев звучит зефирно-гаражно до самого конца.")
+	pending := seedAlbumReview(t, db, other.ID, album.ID, 40)
+	db.Model(&pending).Updates(map[string]interface{}{"text": "тоже зефирно-гаражно", "status": models.ReviewStatusPending})
+
+	w := serve(sc.Search, http.MethodGet, "/search", "/search?q=зефирно-гаражно&types=reviews", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("search: %d %s", w.Code, w.Body.String())
+	}
+	var body SearchResponse
+	decode(t, w, &body)
+	if len(body.Reviews) != 1 {
+		t.Fatalf("want 1 review, got %+v", body.Reviews)
+	}
+	found := body.Reviews[0]
+	if found.ID != approved.ID || found.Username != author.Username || found.Title != album.Title {
+		t.Errorf("result = %+v", found)
+	}
+	if found.ExcerptMatch != "зефирно-гаражно" {
+		t.Errorf("excerpt match = %q", found.ExcerptMatch)
+	}
+	if len(body.Albums) != 0 || len(body.Users) != 0 {
+		t.Errorf("types=reviews filled other sections: %+v", body)
+	}
+}
+
+// Число одобренных рецензий и средняя оценка трека в поиске совпадают с
+// таблицей reviews; рецензия на модерации не учитывается.
+func TestSearchTrackReviewStats(t *testing.T) {
+	db := testDB(t)
+	sc := &SearchController{DB: db}
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	track := seedTrack(t, db, seedAlbum(t, db, "search-stats", models.AlbumStatusApproved).ID, "Квазистатичный", 1)
+	trackID := track.ID
+	for i, review := range []struct {
+		score  float64
+		status models.ReviewStatus
+	}{{40, models.ReviewStatusApproved}, {60, models.ReviewStatusApproved}, {10, models.ReviewStatusPending}} {
+		author := seedUser(t, db, fmt.Sprintf("search-stats-%d", i), false)
+		mustCreate(t, db, &models.Review{
+			UserID: author.ID, TrackID: &trackID,
+			RatingRhymes: 5, RatingStructure: 5, RatingImplementation: 5, RatingIndividuality: 5,
+			AtmosphereMultiplier: 1, FinalScore: review.score, Status: review.status,
+		})
+	}
+	if err := tc.CalculateAverageRating(track.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var want struct {
+		Count int64
+		Avg   float64
+	}
+	db.Model(&models.Review{}).Select("COUNT(*) AS count, ROUND(AVG(final_score)) AS avg").
+		Where("track_id = ? AND status = ?", track.ID, models.ReviewStatusApproved).Scan(&want)
+
+	w := serve(sc.Search, http.MethodGet, "/search", "/search?q=Квазистатичный&types=tracks", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("search: %d %s", w.Code, w.Body.String())
+	}
+	var body SearchResponse
+	decode(t, w, &body)
+	if len(body.Tracks) != 1 {
+		t.Fatalf("want 1 track, got %+v", body.Tracks)
+	}
+	got := body.Tracks[0]
+	if got.ApprovedReviewsCount != want.Count || got.AverageRating != want.Avg || want.Count != 2 {
+		t.Errorf("track stats %d / %v, reviews table %d / %v", got.ApprovedReviewsCount, got.AverageRating, want.Count, want.Avg)
+	}
+}
+
+// По тексту песни трек находится только с search_lyrics=true.
+func TestSearchTracksByLyrics(t *testing.T) {
+	db := testDB(t)
+	sc := &SearchController{DB: db}
+	track := seedTrack(t, db, seedAlbum(t, db, "search-lyrics", models.AlbumStatusApproved).ID, "Без слов в названии", 1)
+	db.Model(&track).Update("lyrics", "где-то шуршит гиперборейский снег")
+
+	found := func(query string) bool {
+		w := serve(sc.Search, http.MethodGet, "/search", "/search?types=tracks&q=гиперборейский"+query, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("search%s: %d %s", query, w.Code, w.Body.String())
+		}
+		var body SearchResponse
+		decode(t, w, &body)
+		return len(body.Tracks) == 1 && body.Tracks[0].ID == track.ID
+	}
+	if found("") {
+		t.Error("lyrics matched without search_lyrics")
+	}
+	if !found("&search_lyrics=true") {
+		t.Error("lyrics not matched with search_lyrics=true")
+	}
+}
@@ -1,13 +1,15 @@
 package controllers
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"music-review-site/backend/middleware"
 	"music-review-site/backend/models"
 	"music-review-site/backend/utils"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,7 +17,9 @@ import (
 )
 
 type TrackController struct {
-	DB *gorm.DB
+	DB            *gorm.DB
+	ListenLimiter *utils.ListenLimiter
+	Scoring       models.ScoringConfig // SCORE_*: перевод множителя атмосферы в шкалу 1–10
 }
 
 // CreateTrackRequest represents track creation request
@@ -24,15 +28,50 @@ type CreateTrackRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Duration    *int   `json:"duration"`
 	TrackNumber *int   `json:"track_number"`
+	Lyrics      string `json:"lyrics"`
 	GenreIDs    []uint `json:"genre_ids"` // Array of genre IDs
 }
 
 // UpdateTrackRequest represents track update request
 type UpdateTrackRequest struct {
-	Title       string `json:"title"`
-	Duration    *int   `json:"duration"`
-	TrackNumber *int   `json:"track_number"`
-	GenreIDs    []uint `json:"genre_ids"` // Array of genre IDs
+	Title       string  `json:"title"`
+	Duration    *int    `json:"duration"`
+	TrackNumber *int    `json:"track_number"`
+	Lyrics      *string `json:"lyrics"`    // Pointer: пустая строка очищает текст
+	GenreIDs    []uint  `json:"genre_ids"` // Array of genre IDs
+}
+
+// ReorderTracksRequest is the new order of all tracks of an album
+type ReorderTracksRequest struct {
+	TrackIDs []uint `json:"track_ids" binding:"required"`
+}
+
+// validateTrackFields проверяет длительность и номер трека; номер должен быть
+// уникален в пределах альбома (excludeTrackID — сам редактируемый трек).
+// Возвращает HTTP-статус и текст ошибки, либо 0, если всё в порядке.
+func (tc *TrackController) validateTrackFields(albumID uint, duration, trackNumber *int, excludeTrackID uint) (int, string) {
+	if duration != nil {
+		if err := utils.ValidateTrackDuration(*duration); err != nil {
+			return http.StatusBadRequest, err.Error()
+		}
+	}
+	if trackNumber == nil {
+		return 0, ""
+	}
+	if err := utils.ValidateTrackNumber(*trackNumber); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+
+	var conflicting models.Track
+	err := tc.DB.Where("album_id = ? AND track_number = ? AND id <> ?", albumID, *trackNumber, excludeTrackID).
+		First(&conflicting).Error
+	if err == nil {
+		return http.StatusConflict, fmt.Sprintf("track_number %d is already used by track %q (id %d) in this album", *trackNumber, conflicting.Title, conflicting.ID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusInternalServerError, "Failed to validate track_number"
+	}
+	return 0, ""
 }
 
 // GetTracks retrieves tracks for an album
@@ -40,7 +79,7 @@ func (tc *TrackController) GetTracks(c *gin.Context) {
 	albumID := c.Param("id")
 	var tracks []models.Track
 
-	if err := tc.DB.Preload("Likes").Preload("Genres").Where("album_id = ?", albumID).Order("track_number ASC, created_at ASC").Find(&tracks).Error; err != nil {
+	if err := requestDB(c, tc.DB).Scopes(visibleTrackAlbums(c)).Preload("Likes").Preload("Genres").Where("album_id = ?", albumID).Order(trackListOrder).Find(&tracks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch tracks",
@@ -49,20 +88,43 @@ func (tc *TrackController) GetTracks(c *gin.Context) {
 		return
 	}
 
-	// Среднее считаем агрегатом на чтении (read-only), без UPDATE на каждый трек.
-	for i := range tracks {
-		if err := tc.AttachAverageScoreBreakdown(&tracks[i]); err != nil {
-			log.Printf("Warning: failed to attach average score breakdown for track %d: %v", tracks[i].ID, err)
-		}
+	// Среднее считаем агрегатом на чтении (read-only), одним GROUP BY на все треки.
+	if err := tc.withRequest(c).attachScoreBreakdowns(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach average score breakdown", "album_id", albumID, "error", err)
+	}
+	if err := tc.withRequest(c).attachListens7d(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach listens", "album_id", albumID, "error", err)
 	}
 
-	c.JSON(http.StatusOK, tracks)
+	// Все треки альбома на одной странице, как у списка жанров.
+	c.JSON(http.StatusOK, utils.NewList("tracks", tracks, len(tracks)))
 }
 
 // GetAllTracks retrieves all tracks with filtering, sorting and pagination
 func (tc *TrackController) GetAllTracks(c *gin.Context) {
+	// min_rating и album_id проверяются заранее: молча проигнорированный фильтр
+	// вернул бы клиенту не ту выборку.
+	var minRating *float64
+	if value := c.Query("min_rating"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			utils.RespondError(c, http.StatusBadRequest, "min_rating must be a non-negative number")
+			return
+		}
+		minRating = &parsed
+	}
+	var albumID *uint64
+	if value := c.Query("album_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "album_id must be a positive integer")
+			return
+		}
+		albumID = &parsed
+	}
+
 	var tracks []models.Track
-	query := tc.DB.Model(&models.Track{}).Preload("Album").Preload("Album.Genre").Preload("Genres").Preload("Likes")
+	query := requestDB(c, tc.DB).Model(&models.Track{}).Scopes(visibleTrackAlbums(c)).Preload("Album").Preload("Album.Genre").Preload("Genres").Preload("Likes")
 
 	// Filter by genre_ids (array) - AND logic: track must have ALL selected genres
 	if genreIDsParam := c.QueryArray("genre_ids[]"); len(genreIDsParam) > 0 {
@@ -87,6 +149,12 @@ func (tc *TrackController) GetAllTracks(c *gin.Context) {
 	if search := c.Query("search"); search != "" {
 		query = query.Where("tracks.title ILIKE ? OR EXISTS (SELECT 1 FROM albums WHERE albums.id = tracks.album_id AND albums.artist ILIKE ?)", "%"+search+"%", "%"+search+"%")
 	}
+	if minRating != nil {
+		query = query.Where("tracks.average_rating >= ?", *minRating)
+	}
+	if albumID != nil {
+		query = query.Where("tracks.album_id = ?", *albumID)
+	}
 
 	// Sort
 	sortBy := c.DefaultQuery("sort_by", "created_at")
@@ -112,6 +180,12 @@ func (tc *TrackController) GetAllTracks(c *gin.Context) {
 		} else {
 			query = query.Order("tracks.average_rating ASC NULLS LAST, tracks.created_at ASC")
 		}
+	case "listens":
+		if sortOrder == "desc" {
+			query = query.Order(listens7dExpr + " DESC, tracks.created_at DESC")
+		} else {
+			query = query.Order(listens7dExpr + " ASC, tracks.created_at ASC")
+		}
 	case "likes_count":
 		// Sort by number of likes
 		if sortOrder == "desc" {
@@ -129,7 +203,7 @@ func (tc *TrackController) GetAllTracks(c *gin.Context) {
 
 	// Count total with same filters (before pagination)
 	var total int64
-	countQuery := tc.DB.Model(&models.Track{})
+	countQuery := requestDB(c, tc.DB).Model(&models.Track{}).Scopes(visibleTrackAlbums(c))
 
 	// Apply same filters to count query
 	if genreIDsParam := c.QueryArray("genre_ids[]"); len(genreIDsParam) > 0 {
@@ -150,14 +224,18 @@ func (tc *TrackController) GetAllTracks(c *gin.Context) {
 	if search := c.Query("search"); search != "" {
 		countQuery = countQuery.Where("tracks.title ILIKE ? OR EXISTS (SELECT 1 FROM albums WHERE albums.id = tracks.album_id AND albums.artist ILIKE ?)", "%"+search+"%", "%"+search+"%")
 	}
+	if minRating != nil {
+		countQuery = countQuery.Where("tracks.average_rating >= ?", *minRating)
+	}
+	if albumID != nil {
+		countQuery = countQuery.Where("tracks.album_id = ?", *albumID)
+	}
 	countQuery.Count(&total)
 
 	// Pagination
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
+	page := utils.ParsePage(c)
 
-	if err := query.Offset(offset).Limit(pageSize).Find(&tracks).Error; err != nil {
+	if err := query.Offset(page.Offset()).Limit(page.Size).Find(&tracks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch tracks",
@@ -168,17 +246,45 @@ func (tc *TrackController) GetAllTracks(c *gin.Context) {
 
 	// Среднее считаем агрегатом на чтении (read-only). Треки уже загружены
 	// со всеми связями основным запросом — повторная загрузка и UPDATE не нужны.
-	for i := range tracks {
-		if err := tc.AttachAverageScoreBreakdown(&tracks[i]); err != nil {
-			log.Printf("Warning: failed to attach average score breakdown for track %d: %v", tracks[i].ID, err)
-		}
+	if err := tc.withRequest(c).attachScoreBreakdowns(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach average score breakdown", "error", err)
+	}
+	if err := tc.withRequest(c).attachListens7d(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach listens", "error", err)
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("tracks", tracks, total, page))
+}
+
+// GetTracksBatch retrieves tracks by a list of IDs preserving the requested order
+func (tc *TrackController) GetTracksBatch(c *gin.Context) {
+	ids, ok := batchIDsFromQuery(c)
+	if !ok {
+		return
 	}
 
+	var tracks []models.Track
+	if err := requestDB(c, tc.DB).Scopes(visibleTrackAlbums(c)).Preload("Album").Preload("Album.Genre").Preload("Likes").Preload("Genres").
+		Where("id IN ?", ids).Find(&tracks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch tracks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if err := tc.withRequest(c).attachScoreBreakdowns(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach average score breakdown", "error", err)
+	}
+	if err := tc.withRequest(c).attachListens7d(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach listens", "error", err)
+	}
+
+	ordered, missing := orderByIDs(ids, tracks, func(t *models.Track) uint { return t.ID })
 	c.JSON(http.StatusOK, gin.H{
-		"tracks":    tracks,
-		"total":     total,
-		"page":      page,
-		"page_size": pageSize,
+		"tracks":  ordered,
+		"missing": missing,
 	})
 }
 
@@ -187,7 +293,7 @@ func (tc *TrackController) GetTrack(c *gin.Context) {
 	id := c.Param("id")
 	var track models.Track
 
-	if err := tc.DB.Preload("Album").Preload("Album.Genre").Preload("Likes").Preload("Genres").First(&track, id).Error; err != nil {
+	if err := requestDB(c, tc.DB).Preload("Album").Preload("Album.Genre").Preload("Likes").Preload("Genres").First(&track, id).Error; err != nil || !albumVisibleTo(&track.Album, c) {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Track not found",
@@ -197,28 +303,140 @@ func (tc *TrackController) GetTrack(c *gin.Context) {
 	}
 
 	// Среднее — агрегатом на чтении, без UPDATE.
-	if err := tc.AttachAverageScoreBreakdown(&track); err != nil {
-		log.Printf("Warning: failed to attach average score breakdown for track %d: %v", track.ID, err)
+	if err := tc.withRequest(c).AttachAverageScoreBreakdown(&track); err != nil {
+		middleware.Logger(c).Warn("failed to attach average score breakdown", "track_id", track.ID, "error", err)
+	}
+	if err := tc.withRequest(c).attachSiblingTracks(&track); err != nil {
+		middleware.Logger(c).Warn("failed to resolve sibling tracks", "track_id", track.ID, "error", err)
+	}
+	single := []models.Track{track}
+	if err := tc.withRequest(c).attachListens7d(single); err != nil {
+		middleware.Logger(c).Warn("failed to attach listens", "track_id", track.ID, "error", err)
+	}
+	track = single[0]
+	if c.Query("include") == "reviews" {
+		if err := tc.withRequest(c).attachLatestReviews(&track); err != nil {
+			middleware.Logger(c).Warn("failed to attach latest reviews", "track_id", track.ID, "error", err)
+		}
 	}
+	track.ReviewedByMe, track.MyReviewID = currentUserReview(c, requestDB(c, tc.DB), "track_id", track.ID)
 
 	c.JSON(http.StatusOK, track)
 }
 
+// latestReviewsLimit и latestReviewTextLimit задают превью рецензий в карточке трека.
+const (
+	latestReviewsLimit    = 3
+	latestReviewTextLimit = 300
+)
+
+// attachLatestReviews добавляет число одобренных рецензий и последние из них с
+// укороченным текстом — чтобы странице трека не нужен был отдельный запрос.
+func (tc *TrackController) attachLatestReviews(track *models.Track) error {
+	var count int64
+	if err := tc.DB.Model(&models.Review{}).
+		Where("track_id = ? AND status = ?", track.ID, models.ReviewStatusApproved).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	reviews := []models.Review{}
+	if count > 0 {
+		if err := tc.DB.Preload("User", withDeletedAuthor).
+			Where("track_id = ? AND status = ?", track.ID, models.ReviewStatusApproved).
+			Order("created_at DESC").
+			Limit(latestReviewsLimit).
+			Find(&reviews).Error; err != nil {
+			return err
+		}
+	}
+	for i := range reviews {
+		reviews[i].Text = utils.TruncateRunes(reviews[i].Text, latestReviewTextLimit)
+	}
+
+	track.ReviewCount = &count
+	track.LatestReviews = reviews
+	return nil
+}
+
+// trackOrderKey — порядок треков внутри альбома: по номеру, треки без номера в
+// конце, при равенстве/пропусках — по created_at и id. Совпадает с GetTracks.
+const trackOrderKey = "(COALESCE(track_number, 2147483647), created_at, id)"
+
+// trackListOrder — тот же порядок для ORDER BY списков треков. NULLS LAST
+// прописан явно, а id добавлен последним ключом, чтобы треки с одинаковым
+// created_at не менялись местами между запросами.
+const trackListOrder = "track_number ASC NULLS LAST, created_at ASC, id ASC"
+
+// attachSiblingTracks находит предыдущий и следующий трек альбома двумя
+// запросами с LIMIT 1. Мягко удалённые треки отсекает стандартный scope GORM.
+func (tc *TrackController) attachSiblingTracks(track *models.Track) error {
+	position := 2147483647
+	if track.TrackNumber != nil {
+		position = *track.TrackNumber
+	}
+
+	var prev, next []models.TrackStub
+	if err := tc.DB.Model(&models.Track{}).
+		Select("id, title, track_number").
+		Where("album_id = ? AND "+trackOrderKey+" < (?, ?, ?)", track.AlbumID, position, track.CreatedAt, track.ID).
+		Order("COALESCE(track_number, 2147483647) DESC, created_at DESC, id DESC").
+		Limit(1).
+		Scan(&prev).Error; err != nil {
+		return err
+	}
+	if err := tc.DB.Model(&models.Track{}).
+		Select("id, title, track_number").
+		Where("album_id = ? AND "+trackOrderKey+" > (?, ?, ?)", track.AlbumID, position, track.CreatedAt, track.ID).
+		Order("COALESCE(track_number, 2147483647) ASC, created_at ASC, id ASC").
+		Limit(1).
+		Scan(&next).Error; err != nil {
+		return err
+	}
+
+	if len(prev) > 0 {
+		track.PrevTrack = &prev[0]
+	}
+	if len(next) > 0 {
+		track.NextTrack = &next[0]
+	}
+	return nil
+}
+
+// GetTrackLyrics returns the lyrics of a track. Текст отдаётся отдельным
+// запросом, чтобы не тащить его в списки треков.
+func (tc *TrackController) GetTrackLyrics(c *gin.Context) {
+	id := c.Param("id")
+	var track models.Track
+
+	if err := requestDB(c, tc.DB).Scopes(visibleTrackAlbums(c)).Select("id", "title", "lyrics").First(&track, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Track not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"track_id":   track.ID,
+		"title":      track.Title,
+		"lyrics":     track.Lyrics,
+		"has_lyrics": strings.TrimSpace(track.Lyrics) != "",
+	})
+}
+
 // CreateTrack creates a new track
 func (tc *TrackController) CreateTrack(c *gin.Context) {
 	var req CreateTrackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
-		})
+		utils.RespondBindingError(c, err)
 		return
 	}
 
 	// Check if album exists
 	var album models.Album
-	if err := tc.DB.First(&album, req.AlbumID).Error; err != nil {
+	if err := requestDB(c, tc.DB).First(&album, req.AlbumID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 			Error:   "Bad Request",
 			Message: "Album not found",
@@ -227,14 +445,51 @@ func (tc *TrackController) CreateTrack(c *gin.Context) {
 		return
 	}
 
+	if status, message := tc.withRequest(c).validateTrackFields(req.AlbumID, req.Duration, req.TrackNumber, 0); status != 0 {
+		c.JSON(status, utils.ErrorResponse{
+			Error:   http.StatusText(status),
+			Message: message,
+			Code:    status,
+		})
+		return
+	}
+
+	// Номер не передан — ставим трек в конец альбома.
+	if req.TrackNumber == nil {
+		var maxNumber int
+		if err := requestDB(c, tc.DB).Model(&models.Track{}).
+			Where("album_id = ?", req.AlbumID).
+			Select("COALESCE(MAX(track_number), 0)").
+			Scan(&maxNumber).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to assign track_number",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		next := maxNumber + 1
+		req.TrackNumber = &next
+	}
+
 	track := models.Track{
 		AlbumID:     req.AlbumID,
 		Title:       req.Title,
 		Duration:    req.Duration,
 		TrackNumber: req.TrackNumber,
+		Lyrics:      req.Lyrics,
 	}
 
-	if err := tc.DB.Create(&track).Error; err != nil {
+	if err := requestDB(c, tc.DB).Create(&track).Error; err != nil {
+		// Параллельное создание могло занять номер после проверки — решает уникальный индекс.
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: fmt.Sprintf("track_number %d is already used in this album", *req.TrackNumber),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to create track",
@@ -246,12 +501,12 @@ func (tc *TrackController) CreateTrack(c *gin.Context) {
 	// Associate genres if provided
 	if len(req.GenreIDs) > 0 {
 		var genres []models.Genre
-		if err := tc.DB.Where("id IN ?", req.GenreIDs).Find(&genres).Error; err == nil {
-			tc.DB.Model(&track).Association("Genres").Replace(genres)
+		if err := requestDB(c, tc.DB).Where("id IN ?", req.GenreIDs).Find(&genres).Error; err == nil {
+			requestDB(c, tc.DB).Model(&track).Association("Genres").Replace(genres)
 		}
 	}
 
-	tc.DB.Preload("Album").Preload("Genres").First(&track, track.ID)
+	requestDB(c, tc.DB).Preload("Album").Preload("Genres").First(&track, track.ID)
 	c.JSON(http.StatusCreated, track)
 }
 
@@ -260,7 +515,7 @@ func (tc *TrackController) UpdateTrack(c *gin.Context) {
 	id := c.Param("id")
 	var track models.Track
 
-	if err := tc.DB.First(&track, id).Error; err != nil {
+	if err := requestDB(c, tc.DB).First(&track, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Track not found",
@@ -271,10 +526,15 @@ func (tc *TrackController) UpdateTrack(c *gin.Context) {
 
 	var req UpdateTrackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
-			Error:   "Bad Request",
-			Message: err.Error(),
-			Code:    http.StatusBadRequest,
+		utils.RespondBindingError(c, err)
+		return
+	}
+
+	if status, message := tc.withRequest(c).validateTrackFields(track.AlbumID, req.Duration, req.TrackNumber, track.ID); status != 0 {
+		c.JSON(status, utils.ErrorResponse{
+			Error:   http.StatusText(status),
+			Message: message,
+			Code:    status,
 		})
 		return
 	}
@@ -288,8 +548,19 @@ func (tc *TrackController) UpdateTrack(c *gin.Context) {
 	if req.TrackNumber != nil {
 		track.TrackNumber = req.TrackNumber
 	}
+	if req.Lyrics != nil {
+		track.Lyrics = *req.Lyrics
+	}
 
-	if err := tc.DB.Save(&track).Error; err != nil {
+	if err := requestDB(c, tc.DB).Save(&track).Error; err != nil {
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: "track_number is already used in this album",
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to update track",
@@ -302,16 +573,16 @@ func (tc *TrackController) UpdateTrack(c *gin.Context) {
 	if req.GenreIDs != nil {
 		var genres []models.Genre
 		if len(req.GenreIDs) > 0 {
-			if err := tc.DB.Where("id IN ?", req.GenreIDs).Find(&genres).Error; err == nil {
-				tc.DB.Model(&track).Association("Genres").Replace(genres)
+			if err := requestDB(c, tc.DB).Where("id IN ?", req.GenreIDs).Find(&genres).Error; err == nil {
+				requestDB(c, tc.DB).Model(&track).Association("Genres").Replace(genres)
 			}
 		} else {
 			// Clear all genres if empty array
-			tc.DB.Model(&track).Association("Genres").Clear()
+			requestDB(c, tc.DB).Model(&track).Association("Genres").Clear()
 		}
 	}
 
-	tc.DB.Preload("Album").Preload("Genres").First(&track, track.ID)
+	requestDB(c, tc.DB).Preload("Album").Preload("Genres").First(&track, track.ID)
 	c.JSON(http.StatusOK, track)
 }
 
@@ -320,7 +591,7 @@ func (tc *TrackController) DeleteTrack(c *gin.Context) {
 	id := c.Param("id")
 	var track models.Track
 
-	if err := tc.DB.First(&track, id).Error; err != nil {
+	if err := requestDB(c, tc.DB).First(&track, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Track not found",
@@ -329,7 +600,13 @@ func (tc *TrackController) DeleteTrack(c *gin.Context) {
 		return
 	}
 
-	if err := tc.DB.Delete(&track).Error; err != nil {
+	err := requestDB(c, tc.DB).Transaction(func(tx *gorm.DB) error {
+		if err := deleteTrackDependents(tx, []uint{track.ID}); err != nil {
+			return err
+		}
+		return tx.Delete(&track).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to delete track",
@@ -341,7 +618,168 @@ func (tc *TrackController) DeleteTrack(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Track deleted successfully"})
 }
 
-// GetPopularTracks retrieves most liked tracks from last 24 hours
+// ReorderTracks rewrites track numbers of an album in the given order (1..n)
+func (tc *TrackController) ReorderTracks(c *gin.Context) {
+	var album models.Album
+	if err := requestDB(c, tc.DB).First(&album, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Album not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var req ReorderTracksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondBindingError(c, err)
+		return
+	}
+
+	var albumTrackIDs []uint
+	if err := requestDB(c, tc.DB).Model(&models.Track{}).Where("album_id = ?", album.ID).Pluck("id", &albumTrackIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch album tracks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// Порядок задаётся целиком: каждый трек альбома ровно один раз, чужих нет.
+	// Иначе новые номера 1..n столкнутся с номерами неупомянутых треков.
+	inAlbum := make(map[uint]bool, len(albumTrackIDs))
+	for _, id := range albumTrackIDs {
+		inAlbum[id] = true
+	}
+	seen := make(map[uint]bool, len(req.TrackIDs))
+	for _, id := range req.TrackIDs {
+		if !inAlbum[id] {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Bad Request",
+				Message: fmt.Sprintf("track %d does not belong to this album", id),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		if seen[id] {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Bad Request",
+				Message: fmt.Sprintf("track %d is listed more than once", id),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(albumTrackIDs) {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "track_ids must list every track of the album",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := requestDB(c, tc.DB).Transaction(func(tx *gorm.DB) error {
+		// Сначала снимаем номера: уникальный индекс ux_tracks_album_number
+		// не отложенный, и обмен номерами по одному трека упал бы на нём.
+		if err := tx.Model(&models.Track{}).Where("album_id = ?", album.ID).
+			Update("track_number", nil).Error; err != nil {
+			return err
+		}
+		for i, id := range req.TrackIDs {
+			if err := tx.Model(&models.Track{}).Where("id = ?", id).
+				Update("track_number", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to reorder tracks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	var tracks []models.Track
+	requestDB(c, tc.DB).Where("album_id = ?", album.ID).Order(trackListOrder).Find(&tracks)
+	c.JSON(http.StatusOK, gin.H{"tracks": tracks})
+}
+
+// deleteTrackDependents убирает всё, что висит на удаляемых треках: рецензии и
+// лайки — мягко (чтобы не участвовали в званиях и популярном), связи с жанрами —
+// физически. Пересчитывать средние не нужно: самих треков больше нет.
+func deleteTrackDependents(tx *gorm.DB, trackIDs []uint) error {
+	if len(trackIDs) == 0 {
+		return nil
+	}
+	if err := tx.Where("track_id IN ?", trackIDs).Delete(&models.Review{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("track_id IN ?", trackIDs).Delete(&models.TrackLike{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("track_id IN ?", trackIDs).Delete(&models.TrackGenre{}).Error
+}
+
+// popularWindowMin/Max ограничивают окно подсчёта лайков для популярных треков.
+const (
+	popularWindowMin     = time.Hour
+	popularWindowMax     = 30 * 24 * time.Hour
+	popularWindowDefault = 24 * time.Hour
+)
+
+// parsePopularWindow разбирает параметр window: "6h", "24h", "7d", "30d".
+// Пустое значение — окно по умолчанию (24 часа).
+func parsePopularWindow(value string) (time.Duration, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "" {
+		return popularWindowDefault, nil
+	}
+
+	var window time.Duration
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("window must look like 24h or 7d")
+		}
+		window = time.Duration(days) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("window must look like 24h or 7d")
+		}
+		window = parsed
+	}
+
+	if window < popularWindowMin || window > popularWindowMax {
+		return 0, fmt.Errorf("window must be between 1h and 30d")
+	}
+	return window, nil
+}
+
+// parseGenreFilter собирает ID жанров из genre_id и genre_ids[]; мусорные значения пропускаются.
+func parseGenreFilter(c *gin.Context) []uint {
+	raw := c.QueryArray("genre_ids[]")
+	if genreID := c.Query("genre_id"); genreID != "" {
+		raw = append(raw, genreID)
+	}
+
+	genreIDs := make([]uint, 0, len(raw))
+	for _, idStr := range raw {
+		if id, err := strconv.ParseUint(idStr, 10, 32); err == nil {
+			genreIDs = append(genreIDs, uint(id))
+		}
+	}
+	return genreIDs
+}
+
+// GetPopularTracks retrieves most liked tracks for a time window (24 hours by default).
+// Если лайкнутых в окне треков меньше limit, остаток добирается по average_rating.
+// Витрина общая для всех и кешируется, поэтому в ней только треки одобренных альбомов.
 func (tc *TrackController) GetPopularTracks(c *gin.Context) {
 	limit := 10
 	if limitParam := c.Query("limit"); limitParam != "" {
@@ -349,12 +787,31 @@ func (tc *TrackController) GetPopularTracks(c *gin.Context) {
 			limit = parsedLimit
 		}
 	}
-	since := time.Now().Add(-24 * time.Hour)
+	window, err := parsePopularWindow(c.Query("window"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	since := time.Now().Add(-window)
+	genreIDs := parseGenreFilter(c)
+
+	genreFilterSQL := ""
+	args := []interface{}{models.AlbumStatusApproved, since}
+	if len(genreIDs) > 0 {
+		genreFilterSQL = " AND EXISTS (SELECT 1 FROM track_genres tg WHERE tg.track_id = t.id AND tg.genre_id IN ?)"
+		args = append(args, genreIDs)
+	}
+	args = append(args, limit)
 
 	// Для демо берём по одному лидеру от каждого артиста. Иначе при плотном
 	// каталоге один исполнитель легко занимает весь топ несколькими треками.
 	type popularTrackRow struct {
 		TrackID   uint
+		Artist    string
 		LikeCount int64
 	}
 	var rankedRows []popularTrackRow
@@ -362,22 +819,22 @@ func (tc *TrackController) GetPopularTracks(c *gin.Context) {
 		WITH counts AS (
 			SELECT t.id AS track_id, a.artist, COUNT(tl.id) AS like_count
 			FROM tracks t
-			JOIN albums a ON a.id = t.album_id AND a.deleted_at IS NULL
+			JOIN albums a ON a.id = t.album_id AND a.deleted_at IS NULL AND a.status = ?
 			LEFT JOIN track_likes tl ON tl.track_id = t.id
 				AND tl.created_at >= ? AND tl.deleted_at IS NULL
-			WHERE t.deleted_at IS NULL
+			WHERE t.deleted_at IS NULL` + genreFilterSQL + `
 			GROUP BY t.id, a.artist
 		), ranked AS (
-			SELECT track_id, like_count,
+			SELECT track_id, artist, like_count,
 				ROW_NUMBER() OVER (PARTITION BY artist ORDER BY like_count DESC, track_id DESC) AS artist_rank
 			FROM counts
 		)
-		SELECT track_id, like_count
+		SELECT track_id, artist, like_count
 		FROM ranked
-		WHERE artist_rank = 1
+		WHERE artist_rank = 1 AND like_count > 0
 		ORDER BY like_count DESC, track_id DESC
 		LIMIT ?`
-	if err := tc.DB.Raw(rankingSQL, since, limit).Scan(&rankedRows).Error; err != nil {
+	if err := requestDB(c, tc.DB).Raw(rankingSQL, args...).Scan(&rankedRows).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch popular tracks",
@@ -386,16 +843,57 @@ func (tc *TrackController) GetPopularTracks(c *gin.Context) {
 		return
 	}
 
-	trackIDs := make([]uint, 0, len(rankedRows))
-	trackOrder := make(map[uint]int, len(rankedRows))
-	for index, row := range rankedRows {
+	trackIDs := make([]uint, 0, limit)
+	artists := make([]string, 0, len(rankedRows))
+	for _, row := range rankedRows {
 		trackIDs = append(trackIDs, row.TrackID)
-		trackOrder[row.TrackID] = index
+		artists = append(artists, row.Artist)
 	}
 
-	var tracks []models.Track
+	// В тихие периоды лайков мало — добиваем выдачу лучшими по оценке треками.
+	// Правило «один трек от артиста» действует и здесь: артисты, уже попавшие
+	// в топ по лайкам, пропускаются.
+	if len(trackIDs) < limit {
+		fillFilterSQL := genreFilterSQL
+		fillArgs := []interface{}{models.AlbumStatusApproved}
+		if len(genreIDs) > 0 {
+			fillArgs = append(fillArgs, genreIDs)
+		}
+		if len(artists) > 0 {
+			fillFilterSQL += " AND a.artist NOT IN ?"
+			fillArgs = append(fillArgs, artists)
+		}
+		fillArgs = append(fillArgs, limit-len(trackIDs))
+
+		var fillIDs []uint
+		fillSQL := `
+			WITH ranked AS (
+				SELECT t.id AS track_id, t.average_rating,
+					ROW_NUMBER() OVER (PARTITION BY a.artist ORDER BY t.average_rating DESC, t.id DESC) AS artist_rank
+				FROM tracks t
+				JOIN albums a ON a.id = t.album_id AND a.deleted_at IS NULL AND a.status = ?
+				WHERE t.deleted_at IS NULL` + fillFilterSQL + `
+			)
+			SELECT track_id
+			FROM ranked
+			WHERE artist_rank = 1
+			ORDER BY average_rating DESC, track_id DESC
+			LIMIT ?`
+		if err := requestDB(c, tc.DB).Raw(fillSQL, fillArgs...).Scan(&fillIDs).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Internal Server Error", Message: "Failed to fetch popular tracks", Code: http.StatusInternalServerError})
+			return
+		}
+		trackIDs = append(trackIDs, fillIDs...)
+	}
+
+	trackOrder := make(map[uint]int, len(trackIDs))
+	for index, trackID := range trackIDs {
+		trackOrder[trackID] = index
+	}
+
+	tracks := []models.Track{}
 	if len(trackIDs) > 0 {
-		if err := tc.DB.Preload("Album").Preload("Album.Genre").Preload("Genres").Preload("Likes").
+		if err := requestDB(c, tc.DB).Preload("Album").Preload("Album.Genre").Preload("Likes").
 			Where("id IN ?", trackIDs).Find(&tracks).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Internal Server Error", Message: "Failed to fetch popular tracks", Code: http.StatusInternalServerError})
 			return
@@ -403,23 +901,149 @@ func (tc *TrackController) GetPopularTracks(c *gin.Context) {
 		sort.SliceStable(tracks, func(i, j int) bool { return trackOrder[tracks[i].ID] < trackOrder[tracks[j].ID] })
 	}
 
-	// Среднее — агрегатом на чтении; дедуп жанров на уже загруженном треке.
+	// Жанры и средние — пакетно, без запроса на каждый трек.
+	if err := tc.withRequest(c).attachDistinctGenres(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to load genres for popular tracks", "error", err)
+	}
+	if err := tc.withRequest(c).attachScoreBreakdowns(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach average score breakdown", "error", err)
+	}
+	if err := tc.withRequest(c).attachListens7d(tracks); err != nil {
+		middleware.Logger(c).Warn("failed to attach listens", "error", err)
+	}
+
+	c.JSON(http.StatusOK, utils.NewList("tracks", tracks, limit))
+}
+
+// attachDistinctGenres загружает жанры треков одним запросом; DISTINCT страхует
+// от дублей в track_genres, оставшихся от старых сидов.
+func (tc *TrackController) attachDistinctGenres(tracks []models.Track) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+	trackIDs := make([]uint, 0, len(tracks))
+	for _, track := range tracks {
+		trackIDs = append(trackIDs, track.ID)
+	}
+
+	var rows []struct {
+		TrackID     uint
+		GenreID     uint
+		Name        string
+		Description string
+	}
+	if err := tc.DB.Table("track_genres").
+		Select("DISTINCT track_genres.track_id, genres.id AS genre_id, genres.name, genres.description").
+		Joins("JOIN genres ON genres.id = track_genres.genre_id AND genres.deleted_at IS NULL").
+		Where("track_genres.track_id IN ?", trackIDs).
+		Order("track_genres.track_id, genres.id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	genresByTrack := make(map[uint][]models.Genre, len(tracks))
+	for _, row := range rows {
+		genresByTrack[row.TrackID] = append(genresByTrack[row.TrackID], models.Genre{
+			ID:          row.GenreID,
+			Name:        row.Name,
+			Description: row.Description,
+		})
+	}
 	for i := range tracks {
-		if err := tc.AttachAverageScoreBreakdown(&tracks[i]); err != nil {
-			log.Printf("Warning: failed to attach average score breakdown for track %d: %v", tracks[i].ID, err)
-		}
-		seen := make(map[uint]bool, len(tracks[i].Genres))
-		unique := tracks[i].Genres[:0]
-		for _, genre := range tracks[i].Genres {
-			if !seen[genre.ID] {
-				seen[genre.ID] = true
-				unique = append(unique, genre)
-			}
+		tracks[i].Genres = genresByTrack[tracks[i].ID]
+	}
+	return nil
+}
+
+// listens7dExpr — прослушивания трека за последние 7 дней (включая сегодня).
+const listens7dExpr = "(SELECT COALESCE(SUM(track_listens.count), 0) FROM track_listens WHERE track_listens.track_id = tracks.id AND track_listens.day > CURRENT_DATE - 7)"
+
+// RecordListen registers a listen event for a track
+func (tc *TrackController) RecordListen(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Invalid track ID",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var track models.Track
+	if err := requestDB(c, tc.DB).Select("id").First(&track, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Track not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// Гость ограничивается по IP, авторизованный пользователь — по аккаунту.
+	key := "ip:" + c.ClientIP()
+	if userID, ok := middleware.GetUserIDFromContext(c); ok {
+		key = fmt.Sprintf("user:%d", userID)
+		// Факт прослушивания пишем до лимитера: для отметки has_listened у
+		// рецензии важен сам факт, а не то, попал ли запрос в счётчик.
+		if err := requestDB(c, tc.DB).Exec(`
+			INSERT INTO user_track_listens (user_id, track_id, first_listened_at) VALUES (?, ?, NOW())
+			ON CONFLICT (user_id, track_id) DO NOTHING`, userID, track.ID).Error; err != nil {
+			middleware.Logger(c).Warn("failed to record user track listen", "track_id", track.ID, "error", err)
 		}
-		tracks[i].Genres = unique
 	}
+	key = fmt.Sprintf("%s:track:%d", key, track.ID)
 
-	c.JSON(http.StatusOK, tracks)
+	if tc.ListenLimiter != nil && !tc.ListenLimiter.Allow(key) {
+		c.JSON(http.StatusOK, gin.H{"counted": false})
+		return
+	}
+
+	// Один upsert вместо read-modify-write: конкурентные запросы не теряют инкременты.
+	if err := requestDB(c, tc.DB).Exec(`
+		INSERT INTO track_listens (track_id, day, count) VALUES (?, CURRENT_DATE, 1)
+		ON CONFLICT (track_id, day) DO UPDATE SET count = track_listens.count + 1`, track.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to record listen",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"counted": true})
+}
+
+// attachListens7d заполняет Listens7d одним GROUP BY по track_id.
+func (tc *TrackController) attachListens7d(tracks []models.Track) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+	trackIDs := make([]uint, 0, len(tracks))
+	for _, track := range tracks {
+		trackIDs = append(trackIDs, track.ID)
+	}
+
+	var rows []struct {
+		TrackID uint
+		Total   int64
+	}
+	if err := tc.DB.Model(&models.TrackListen{}).
+		Select("track_id, SUM(count) AS total").
+		Where("track_id IN ? AND day > CURRENT_DATE - 7", trackIDs).
+		Group("track_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	totals := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		totals[row.TrackID] = row.Total
+	}
+	for i := range tracks {
+		tracks[i].Listens7d = totals[tracks[i].ID]
+	}
+	return nil
 }
 
 // LikeTrack adds a like to a track
@@ -437,7 +1061,7 @@ func (tc *TrackController) LikeTrack(c *gin.Context) {
 
 	// Check if track exists
 	var track models.Track
-	if err := tc.DB.First(&track, trackID).Error; err != nil {
+	if err := requestDB(c, tc.DB).First(&track, trackID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Track not found",
@@ -448,8 +1072,8 @@ func (tc *TrackController) LikeTrack(c *gin.Context) {
 
 	// Check if like already exists
 	var existingLike models.TrackLike
-	if err := tc.DB.Where("user_id = ? AND track_id = ?", userID, trackID).First(&existingLike).Error; err == nil {
-		c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true})
+	if err := requestDB(c, tc.DB).Where("user_id = ? AND track_id = ?", userID, trackID).First(&existingLike).Error; err == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true, "likes_count": countTrackLikes(requestDB(c, tc.DB), track.ID)})
 		return
 	}
 
@@ -459,7 +1083,12 @@ func (tc *TrackController) LikeTrack(c *gin.Context) {
 		TrackID: track.ID,
 	}
 
-	if err := tc.DB.Create(&like).Error; err != nil {
+	if err := requestDB(c, tc.DB).Create(&like).Error; err != nil {
+		// Параллельный запрос успел поставить тот же лайк — это не ошибка.
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusOK, gin.H{"message": "Already liked", "liked": true, "likes_count": countTrackLikes(requestDB(c, tc.DB), track.ID)})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to like track",
@@ -468,7 +1097,15 @@ func (tc *TrackController) LikeTrack(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Track liked", "liked": true})
+	c.JSON(http.StatusCreated, gin.H{"message": "Track liked", "liked": true, "likes_count": countTrackLikes(requestDB(c, tc.DB), track.ID)})
+}
+
+// countTrackLikes returns all-time likes of a track, а не только лайки за окно
+// популярных треков (window) — это число показывает кнопка лайка (0 при ошибке).
+func countTrackLikes(db *gorm.DB, trackID uint) int64 {
+	var count int64
+	db.Model(&models.TrackLike{}).Where("track_id = ?", trackID).Count(&count)
+	return count
 }
 
 // UnlikeTrack removes a like from a track
@@ -486,7 +1123,7 @@ func (tc *TrackController) UnlikeTrack(c *gin.Context) {
 
 	// Check if track exists
 	var track models.Track
-	if err := tc.DB.First(&track, trackID).Error; err != nil {
+	if err := requestDB(c, tc.DB).First(&track, trackID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "Track not found",
@@ -496,7 +1133,7 @@ func (tc *TrackController) UnlikeTrack(c *gin.Context) {
 	}
 
 	// Жёсткое удаление (см. уникальный индекс ux_track_like_pair).
-	if err := tc.DB.Unscoped().Where("user_id = ? AND track_id = ?", userID, trackID).Delete(&models.TrackLike{}).Error; err != nil {
+	if err := requestDB(c, tc.DB).Unscoped().Where("user_id = ? AND track_id = ?", userID, trackID).Delete(&models.TrackLike{}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to unlike track",
@@ -505,7 +1142,7 @@ func (tc *TrackController) UnlikeTrack(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Track unliked", "liked": false})
+	c.JSON(http.StatusOK, gin.H{"message": "Track unliked", "liked": false, "likes_count": countTrackLikes(requestDB(c, tc.DB), track.ID)})
 }
 
 // CalculateAverageRating calculates and updates average rating for a track
@@ -567,6 +1204,64 @@ func (tc *TrackController) AttachAverageScoreBreakdown(track *models.Track) erro
 	track.AverageRatingStructure = avg.Structure
 	track.AverageRatingImplementation = avg.Implementation
 	track.AverageRatingIndividuality = avg.Individuality
-	track.AverageAtmosphereRating = 1 + (avg.AtmosphereMult-1.0)/(0.6072/9.0)
+	track.AverageAtmosphereRating = tc.Scoring.AtmosphereRating(avg.AtmosphereMult)
+	return nil
+}
+
+// attachScoreBreakdowns — пакетный вариант AttachAverageScoreBreakdown:
+// один GROUP BY по track_id вместо отдельного агрегата на каждый трек.
+func (tc *TrackController) attachScoreBreakdowns(tracks []models.Track) error {
+	if len(tracks) == 0 {
+		return nil
+	}
+	trackIDs := make([]uint, 0, len(tracks))
+	for _, track := range tracks {
+		trackIDs = append(trackIDs, track.ID)
+	}
+
+	var rows []struct {
+		TrackID        uint
+		Count          int64
+		Rhymes         float64
+		Structure      float64
+		Implementation float64
+		Individuality  float64
+		AtmosphereMult float64
+		FinalScore     float64
+	}
+	if err := tc.DB.Model(&models.Review{}).
+		Select(`
+			track_id,
+			COUNT(*) AS count,
+			COALESCE(AVG(rating_rhymes), 0) AS rhymes,
+			COALESCE(AVG(rating_structure), 0) AS structure,
+			COALESCE(AVG(rating_implementation), 0) AS implementation,
+			COALESCE(AVG(rating_individuality), 0) AS individuality,
+			COALESCE(AVG(atmosphere_multiplier), 0) AS atmosphere_mult,
+			COALESCE(AVG(final_score), 0) AS final_score
+		`).
+		Where("track_id IN ? AND status = ?", trackIDs, models.ReviewStatusApproved).
+		Group("track_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	byTrack := make(map[uint]int, len(tracks))
+	for i := range tracks {
+		byTrack[tracks[i].ID] = i
+	}
+	for _, row := range rows {
+		i, ok := byTrack[row.TrackID]
+		if !ok || row.Count == 0 {
+			continue
+		}
+		tracks[i].ApprovedReviewsCount = row.Count
+		tracks[i].AverageRating = float64(int(row.FinalScore + 0.5))
+		tracks[i].AverageRatingRhymes = row.Rhymes
+		tracks[i].AverageRatingStructure = row.Structure
+		tracks[i].AverageRatingImplementation = row.Implementation
+		tracks[i].AverageRatingIndividuality = row.Individuality
+		tracks[i].AverageAtmosphereRating = tc.Scoring.AtmosphereRating(row.AtmosphereMult)
+	}
 	return nil
 }
@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"music-review-site/backend/models"
+)
+
+func TestTracksOfPendingAlbumsHiddenFromPublic(t *testing.T) {
+	db := testDB(t)
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+
+	approved := seedAlbum(t, db, "approved", models.AlbumStatusApproved)
+	pending := seedAlbum(t, db, "pending", models.AlbumStatusPending)
+	visible := seedTrack(t, db, approved.ID, "Visible", 1)
+	hidden := seedTrack(t, db, pending.ID, "Hidden", 1)
+	admin := seedUser(t, db, "track-admin", true)
+
+	public := listedTrackIDs(t, tc, "", nil)
+	if !public[visible.ID] || public[hidden.ID] {
+		t.Errorf("public listing: want only track %d, got %v", visible.ID, public)
+	}
+	if all := listedTrackIDs(t, tc, "", &admin); !all[visible.ID] || !all[hidden.ID] {
+		t.Errorf("admin listing: want both tracks, got %v", all)
+	}
+
+	get := func(trackID uint, user *models.User) int {
+		target := fmt.Sprintf("/tracks/%d", trackID)
+		return serve(tc.GetTrack, http.MethodGet, "/tracks/:id", target, "", user).Code
+	}
+	if code := get(hidden.ID, nil); code != http.StatusNotFound {
+		t.Errorf("GET pending track as guest: want 404, got %d", code)
+	}
+	if code := get(hidden.ID, &admin); code != http.StatusOK {
+		t.Errorf("GET pending track as admin: want 200, got %d", code)
+	}
+
+	w := serve(tc.GetTracks, http.MethodGet, "/albums/:id/tracks", fmt.Sprintf("/albums/%d/tracks", pending.ID), "", nil)
+	var body struct {
+		Tracks []models.Track `json:"tracks"`
+		Total  int64         `json:"total"`
+	}
+	decode(t, w, &body)
+	if len(body.Tracks) != 0 || body.Total != 0 {
+		t.Errorf("tracks of pending album as guest: want none, got %d (total %d)", len(body.Tracks), body.Total)
+	}
+}
+
+// listedTrackIDs возвращает ID треков из GET /tracks с заданной строкой запроса.
+func listedTrackIDs(t *testing.T, tc *TrackController, query string, user *models.User) map[uint]bool {
+	t.Helper()
+	w := serve(tc.GetAllTracks, http.MethodGet, "/tracks", "/tracks?page_size=100&"+query, "", user)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /tracks?%s: %d %s", query, w.Code, w.Body.String())
+	}
+	var body struct {
+		Tracks []models.Track `json:"tracks"`
+	}
+	decode(t, w, &body)
+	ids := make(map[uint]bool, len(body.Tracks))
+	for _, track := range body.Tracks {
+		ids[track.ID] = true
+	}
+	return ids
+}
+
+// Треки альбома отвечают конвертом пагинации, а средние оценки считаются одним
+// запросом на все треки: число запросов не растёт с числом треков.
+func TestGetAlbumTracksEnvelope(t *testing.T) {
+	db := testDB(t)
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	album := seedAlbum(t, db, "album-tracks", models.AlbumStatusApproved)
+	author := seedUser(t, db, "album-tracks-author", false)
+	first := seedTrack(t, db, album.ID, "First", 1)
+	trackID := first.ID
+	mustCreate(t, db, &models.Review{
+		UserID: author.ID, TrackID: &trackID,
+		RatingRhymes: 5, RatingStructure: 5, RatingImplementation: 5, RatingIndividuality: 5,
+		AtmosphereMultiplier: 1, FinalScore: 40, Status: models.ReviewStatusApproved,
+	})
+
+	target := fmt.Sprintf("/albums/%d/tracks", album.ID)
+	list := func() (int, map[string]interface{}) {
+		var body map[string]interface{}
+		queries := countQueries(t, db, func() {
+			w := serve(tc.GetTracks, http.MethodGet, "/albums/:id/tracks", target, "", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("GET %s: %d %s", target, w.Code, w.Body.String())
+			}
+			decode(t, w, &body)
+		})
+		return queries, body
+	}
+
+	one, _ := list()
+	for number := 2; number <= 6; number++ {
+		seedTrack(t, db, album.ID, fmt.Sprintf("Track %d", number), number)
+	}
+	six, body := list()
+	if six != one {
+		t.Errorf("queries grow with tracks: %d for 1 track, %d for 6", one, six)
+	}
+	if body["total"] != float64(6) || body["page"] != float64(1) || body["total_pages"] != float64(1) || body["has_next"] != false {
+		t.Errorf("envelope: %v", body)
+	}
+	tracks := body["tracks"].([]interface{})
+	if len(tracks) != 6 {
+		t.Fatalf("want 6 tracks, got %d", len(tracks))
+	}
+	top := tracks[0].(map[string]interface{})
+	if top["id"] != float64(first.ID) || top["average_rating"] != float64(40) {
+		t.Errorf("first track: want id %d with average 40, got %v", first.ID, top)
+	}
+}
+
+// Переупорядочивание переписывает номера 1..n в заданном порядке, а трек
+// другого альбома отклоняется без изменений.
+func TestReorderTracks(t *testing.T) {
+	db := testDB(t)
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "reorder-admin", true)
+	album := seedAlbum(t, db, "reorder", models.AlbumStatusApproved)
+	first := seedTrack(t, db, album.ID, "First", 1)
+	second := seedTrack(t, db, album.ID, "Second", 2)
+	third := seedTrack(t, db, album.ID, "Third", 3)
+	foreign := seedTrack(t, db, seedAlbum(t, db, "reorder-other", models.AlbumStatusApproved).ID, "Foreign", 1)
+
+	target := fmt.Sprintf("/albums/%d/tracks/reorder", album.ID)
+	reorder := func(ids ...uint) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"track_ids": %s}`, strings.Join(strings.Fields(fmt.Sprint(ids)), ","))
+		return serve(tc.ReorderTracks, http.MethodPost, "/albums/:id/tracks/reorder", target, body, &admin)
+	}
+	numbers := func() map[uint]int {
+		var tracks []models.Track
+		db.Where("album_id = ?", album.ID).Find(&tracks)
+		result := make(map[uint]int, len(tracks))
+		for _, track := range tracks {
+			result[track.ID] = *track.TrackNumber
+		}
+		return result
+	}
+
+	if w := reorder(third.ID, first.ID, second.ID); w.Code != http.StatusOK {
+		t.Fatalf("reorder: %d %s", w.Code, w.Body.String())
+	}
+	want := map[uint]int{third.ID: 1, first.ID: 2, second.ID: 3}
+	if got := numbers(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("numbers = %v, want %v", got, want)
+	}
+
+	if w := reorder(first.ID, second.ID, foreign.ID); w.Code != http.StatusBadRequest {
+		t.Errorf("foreign track: want 400, got %d %s", w.Code, w.Body.String())
+	}
+	if got := numbers(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("numbers changed by rejected reorder: %v", got)
+	}
+}
+
+func TestLikeTrackStatuses(t *testing.T) {
+	db := testDB(t)
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	user := seedUser(t, db, "track-liker", false)
+	track := seedTrack(t, db, seedAlbum(t, db, "liked-track", models.AlbumStatusApproved).ID, "Liked", 1)
+	checkLikeCycle(t, tc.LikeTrack, tc.UnlikeTrack, "/tracks/:id/like", fmt.Sprintf("/tracks/%d/like", track.ID), &user)
+}
+
+// Текст песни принимается при создании и правке, отдаётся только через
+// /tracks/:id/lyrics, а в списке вместо него — флаг has_lyrics.
+func TestTrackLyrics(t *testing.T) {
+	db := testDB(t)
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "lyrics-admin", true)
+	album := seedAlbum(t, db, "lyrics", models.AlbumStatusApproved)
+	plain := seedTrack(t, db, album.ID, "Instrumental", 2)
+
+	body := fmt.Sprintf(`{"album_id": %d, "title": "Sung", "track_number": 1, "lyrics": "первый куплет"}`, album.ID)
+	w := serve(tc.CreateTrack, http.MethodPost, "/tracks", "/tracks", body, &admin)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	var created models.Track
+	decode(t, w, &created)
+
+	lyrics := func(trackID uint) map[string]interface{} {
+		target := fmt.Sprintf("/tracks/%d/lyrics", trackID)
+		w := serve(tc.GetTrackLyrics, http.MethodGet, "/tracks/:id/lyrics", target, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", target, w.Code, w.Body.String())
+		}
+		var body map[string]interface{}
+		decode(t, w, &body)
+		return body
+	}
+	if got := lyrics(created.ID); got["lyrics"] != "первый куплет" || got["has_lyrics"] != true {
+		t.Errorf("lyrics after create: %v", got)
+	}
+
+	target := fmt.Sprintf("/tracks/%d", created.ID)
+	if w := serve(tc.UpdateTrack, http.MethodPut, "/tracks/:id", target, `{"lyrics": "второй куплет"}`, &admin); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	if got := lyrics(created.ID); got["lyrics"] != "второй куплет" {
+		t.Errorf("lyrics after update: %v", got)
+	}
+
+	w = serve(tc.GetAllTracks, http.MethodGet, "/tracks", fmt.Sprintf("/tracks?album_id=%d", album.ID), "", nil)
+	if strings.Contains(w.Body.String(), "куплет") {
+		t.Errorf("lyrics text in track list: %s", w.Body.String())
+	}
+	var list struct {
+		Tracks []struct {
+			ID        uint `json:"id"`
+			HasLyrics bool `json:"has_lyrics"`
+		} `json:"tracks"`
+	}
+	decode(t, w, &list)
+	flags := map[uint]bool{}
+	for _, track := range list.Tracks {
+		flags[track.ID] = track.HasLyrics
+	}
+	if len(flags) != 2 || !flags[created.ID] || flags[plain.ID] {
+		t.Errorf("has_lyrics in list: %v, want %d with lyrics and %d without", flags, created.ID, plain.ID)
+	}
+}
+
+// Номер трека уникален в альбоме: второй трек с тем же номером — 409 и при
+// создании, и при правке; в другом альбоме тот же номер свободен.
+func TestTrackNumberConflict(t *testing.T) {
+	db := testDB(t)
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "track-number-admin", true)
+	album := seedAlbum(t, db, "track-number", models.AlbumStatusApproved)
+	other := seedAlbum(t, db, "track-number-other", models.AlbumStatusApproved)
+
+	create := func(albumID uint, title string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"album_id": %d, "title": %q, "track_number": 3}`, albumID, title)
+		return serve(tc.CreateTrack, http.MethodPost, "/tracks", "/tracks", body, &admin)
+	}
+	if w := create(album.ID, "First"); w.Code != http.StatusCreated {
+		t.Fatalf("first track: %d %s", w.Code, w.Body.String())
+	}
+	if w := create(album.ID, "Second"); w.Code != http.StatusConflict {
+		t.Errorf("same number in the album: want 409, got %d %s", w.Code, w.Body.String())
+	}
+	if w := create(other.ID, "Elsewhere"); w.Code != http.StatusCreated {
+		t.Errorf("same number in another album: want 201, got %d %s", w.Code, w.Body.String())
+	}
+
+	fourth := seedTrack(t, db, album.ID, "Fourth", 4)
+	target := fmt.Sprintf("/tracks/%d", fourth.ID)
+	if w := serve(tc.UpdateTrack, http.MethodPut, "/tracks/:id", target, `{"track_number": 3}`, &admin); w.Code != http.StatusConflict {
+		t.Errorf("renumber onto a taken number: want 409, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve(tc.UpdateTrack, http.MethodPut, "/tracks/:id", target, `{"track_number": 4}`, &admin); w.Code != http.StatusOK {
+		t.Errorf("keep own number: want 200, got %d %s", w.Code, w.Body.String())
+	}
+
+	var count int64
+	db.Model(&models.Track{}).Where("album_id = ? AND track_number = 3", album.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("%d tracks numbered 3 in the album", count)
+	}
+}
+
+// Фильтры min_rating и album_id в GET /tracks: по отдельности, вместе и в
+// total; некорректное значение — 400, а не молча весь список.
+func TestGetAllTracksFilters(t *testing.T) {
+	db := testDB(t)
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	first := seedAlbum(t, db, "filters-first", models.AlbumStatusApproved)
+	second := seedAlbum(t, db, "filters-second", models.AlbumStatusApproved)
+	low := seedTrack(t, db, first.ID, "Low", 1)
+	high := seedTrack(t, db, first.ID, "High", 2)
+	other := seedTrack(t, db, second.ID, "Other", 1)
+	for track, rating := range map[uint]float64{low.ID: 30, high.ID: 45, other.ID: 50} {
+		if err := db.Model(&models.Track{}).Where("id = ?", track).Update("average_rating", rating).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ids := listedTrackIDs(t, tc, "min_rating=40", nil); ids[low.ID] || !ids[high.ID] || !ids[other.ID] {
+		t.Errorf("min_rating=40: listed %v, want %d and %d without %d", ids, high.ID, other.ID, low.ID)
+	}
+
+	for query, want := range map[string][]uint{
+		fmt.Sprintf("album_id=%d", first.ID):                {low.ID, high.ID},
+		fmt.Sprintf("album_id=%d&min_rating=40", first.ID):  {high.ID},
+		fmt.Sprintf("album_id=%d&min_rating=40", second.ID): {other.ID},
+		fmt.Sprintf("album_id=%d&min_rating=60", second.ID): {},
+	} {
+		w := serve(tc.GetAllTracks, http.MethodGet, "/tracks", "/tracks?page_size=100&"+query, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /tracks?%s: %d %s", query, w.Code, w.Body.String())
+		}
+		var body struct {
+			Tracks []models.Track `json:"tracks"`
+			Total  int64          `json:"total"`
+		}
+		decode(t, w, &body)
+		var got []uint
+		for _, track := range body.Tracks {
+			got = append(got, track.ID)
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if fmt.Sprint(got) != fmt.Sprint(want) || body.Total != int64(len(want)) {
+			t.Errorf("%s: listed %v (total %d), want %v", query, got, body.Total, want)
+		}
+	}
+
+	for _, query := range []string{"min_rating=-1", "min_rating=abc", "album_id=0x1", "album_id=-3"} {
+		w := serve(tc.GetAllTracks, http.MethodGet, "/tracks", "/tracks?"+query, "", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: want 400, got %d", query, w.Code)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"math"
 	"music-review-site/backend/middleware"
 	"music-review-site/backend/models"
@@ -13,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,7 +22,15 @@ import (
 )
 
 type UserController struct {
-	DB *gorm.DB
+	DB                 *gorm.DB
+	Mailer             utils.Mailer // Письма подтверждения email; nil — почта не настроена
+	UploadsDir         string       // UPLOADS_DIR: аватары лежат в <UploadsDir>/avatars
+	ExposeConfirmToken bool         // EXPOSE_EMAIL_CONFIRM_TOKEN: токен подтверждения email в ответе, только dev
+	PublicSiteURL      string       // PUBLIC_SITE_URL: адрес для ссылки подтверждения email
+	// UsernameChangeCooldown — как часто можно менять username
+	// (USERNAME_CHANGE_COOLDOWN_DAYS, по умолчанию 30 дней; 0 отключает кулдаун).
+	UsernameChangeCooldown time.Duration
+	Ranks                  *ProfileRanks // очки профилей для profile_rank; nil — считать на каждый запрос
 }
 
 // GetUser retrieves user by ID
@@ -28,7 +38,7 @@ func (uc *UserController) GetUser(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 
-	if err := uc.DB.First(&user, id).Error; err != nil {
+	if err := requestDB(c, uc.DB).First(&user, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "User not found",
@@ -39,25 +49,21 @@ func (uc *UserController) GetUser(c *gin.Context) {
 
 	user.Password = ""
 
-	badges := uc.CalculateUserBadges(user.ID)
-	stats := uc.CalculateUserStats(user.ID)
-	profileRank := uc.CalculateProfileRank(user.ID, stats)
-	genreStats := uc.CalculateGenreStats(user.ID)
-	favoriteAlbums := uc.GetFavoriteAlbums(user.FavoriteAlbumIDs)
-	favoriteArtists := uc.GetFavoriteArtists(user.FavoriteArtists)
-	favoriteTracks := uc.GetFavoriteTracks(user.FavoriteTrackIDs)
+	profile := uc.withRequest(c).profileSummary(user.ID)
+	favoriteAlbums := uc.withRequest(c).GetFavoriteAlbums(user.FavoriteAlbumIDs)
+	favoriteArtists := uc.withRequest(c).GetFavoriteArtists(user.FavoriteArtists)
+	favoriteTracks := uc.withRequest(c).GetFavoriteTracks(user.FavoriteTrackIDs)
 
 	var followersCount, followingCount int64
-	uc.DB.Model(&models.UserFollow{}).Where("following_id = ?", user.ID).Count(&followersCount)
-	uc.DB.Model(&models.UserFollow{}).Where("follower_id = ?", user.ID).Count(&followingCount)
+	requestDB(c, uc.DB).Model(&models.UserFollow{}).Where("following_id = ?", user.ID).Count(&followersCount)
+	requestDB(c, uc.DB).Model(&models.UserFollow{}).Where("follower_id = ?", user.ID).Count(&followingCount)
 
 	userResponse := gin.H{
 		"id":                 user.ID,
 		"username":           user.Username,
-		"email":              user.Email,
 		"avatar_path":        user.AvatarPath,
 		"bio":                user.Bio,
-		"social_links":       user.SocialLinks,
+		"social_links":       utils.ParseSocialLinks(user.SocialLinks),
 		"is_admin":           user.IsAdmin,
 		"is_verified_artist": user.IsVerifiedArtist,
 		"artist_name":        user.ArtistName,
@@ -65,22 +71,27 @@ func (uc *UserController) GetUser(c *gin.Context) {
 		"favorite_artists":   favoriteArtists,
 		"favorite_track_ids": user.FavoriteTrackIDs,
 		"preferences_manual": user.PreferencesManual,
+		"likes_private":      user.LikesPrivate,
+		"email_public":       user.EmailPublic,
 		"created_at":         user.CreatedAt,
 		"updated_at":         user.UpdatedAt,
-		"badges":             badges,
-		"stats":              stats,
-		"profile_rank":       profileRank,
-		"genre_stats":        genreStats,
+		"badges":             profile.Badges,
+		"stats":              profile.Stats,
+		"profile_rank":       profile.Rank,
+		"genre_stats":        profile.GenreStats,
 		"favorite_albums":    favoriteAlbums,
 		"favorite_tracks":    favoriteTracks,
 		"followers_count":    followersCount,
 		"following_count":    followingCount,
 	}
+	if canSeeUserEmail(c, &user) {
+		userResponse["email"] = user.Email
+	}
 
 	isFollowing := false
 	if viewerID, ok := middleware.GetUserIDFromContext(c); ok && viewerID != user.ID {
 		var fc int64
-		uc.DB.Model(&models.UserFollow{}).Where("follower_id = ? AND following_id = ?", viewerID, user.ID).Count(&fc)
+		requestDB(c, uc.DB).Model(&models.UserFollow{}).Where("follower_id = ? AND following_id = ?", viewerID, user.ID).Count(&fc)
 		isFollowing = fc > 0
 	}
 	userResponse["is_following"] = isFollowing
@@ -106,17 +117,17 @@ func (uc *UserController) FollowUser(c *gin.Context) {
 		return
 	}
 	var target models.User
-	if err := uc.DB.First(&target, targetID).Error; err != nil {
+	if err := requestDB(c, uc.DB).First(&target, targetID).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{Error: "Not Found", Message: "Пользователь не найден", Code: http.StatusNotFound})
 		return
 	}
 	var existing models.UserFollow
-	if err := uc.DB.Where("follower_id = ? AND following_id = ?", followerID, targetID).First(&existing).Error; err == nil {
+	if err := requestDB(c, uc.DB).Where("follower_id = ? AND following_id = ?", followerID, targetID).First(&existing).Error; err == nil {
 		c.JSON(http.StatusOK, gin.H{"following": true})
 		return
 	}
 	uf := models.UserFollow{FollowerID: followerID, FollowingID: targetID}
-	if err := uc.DB.Create(&uf).Error; err != nil {
+	if err := requestDB(c, uc.DB).Create(&uf).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Internal Server Error", Message: "Не удалось подписаться", Code: http.StatusInternalServerError})
 		return
 	}
@@ -136,7 +147,7 @@ func (uc *UserController) UnfollowUser(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{Error: "Unauthorized", Message: "Нужна авторизация", Code: http.StatusUnauthorized})
 		return
 	}
-	res := uc.DB.Where("follower_id = ? AND following_id = ?", followerID, targetID).Delete(&models.UserFollow{})
+	res := requestDB(c, uc.DB).Where("follower_id = ? AND following_id = ?", followerID, targetID).Delete(&models.UserFollow{})
 	if res.Error != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{Error: "Internal Server Error", Message: "Не удалось отписаться", Code: http.StatusInternalServerError})
 		return
@@ -148,7 +159,7 @@ func (uc *UserController) UnfollowUser(c *gin.Context) {
 func (uc *UserController) SetFavoriteAlbums(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
-	if err := uc.DB.First(&user, id).Error; err != nil {
+	if err := requestDB(c, uc.DB).First(&user, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{Error: "Not Found", Message: "User not found", Code: http.StatusNotFound})
 		return
 	}
@@ -165,7 +176,7 @@ func (uc *UserController) SetFavoriteAlbums(c *gin.Context) {
 		TrackIDs    []uint   `json:"track_ids"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, utils.ErrorResponse{Error: "Bad Request", Message: err.Error(), Code: http.StatusBadRequest})
+		utils.RespondBindingError(c, err)
 		return
 	}
 	if len(req.AlbumIDs) > 3 {
@@ -185,12 +196,12 @@ func (uc *UserController) SetFavoriteAlbums(c *gin.Context) {
 	trackIDsJSON, _ := json.Marshal(req.TrackIDs)
 	user.FavoriteTrackIDs = string(trackIDsJSON)
 	user.PreferencesManual = true
-	uc.DB.Save(&user)
+	requestDB(c, uc.DB).Save(&user)
 
 	c.JSON(http.StatusOK, gin.H{
-		"favorite_albums":    uc.GetFavoriteAlbums(user.FavoriteAlbumIDs),
-		"favorite_artists":   uc.GetFavoriteArtists(user.FavoriteArtists),
-		"favorite_tracks":    uc.GetFavoriteTracks(user.FavoriteTrackIDs),
+		"favorite_albums":    uc.withRequest(c).GetFavoriteAlbums(user.FavoriteAlbumIDs),
+		"favorite_artists":   uc.withRequest(c).GetFavoriteArtists(user.FavoriteArtists),
+		"favorite_tracks":    uc.withRequest(c).GetFavoriteTracks(user.FavoriteTrackIDs),
 		"preferences_manual": user.PreferencesManual,
 	})
 }
@@ -294,6 +305,7 @@ func (uc *UserController) GetFavoriteTracks(idsJSON string) []TrackSearchResult
 				AlbumTitle:     track.Album.Title,
 				Artist:         track.Album.Artist,
 				CoverImagePath: cover,
+				AverageRating:  track.AverageRating,
 			})
 		}
 	}
@@ -319,19 +331,69 @@ func calculateProfilePoints(stats UserStats) int {
 	))
 }
 
+// ProfileRanksTTL — сколько живут очки профилей в ProfileRanks. Пересчёт —
+// шесть агрегатов по целым таблицам, на каждый просмотр профиля это дорого,
+// а ранг, отстающий на минуту, никому не мешает.
+const ProfileRanksTTL = time.Minute
+
+// ProfileRanks хранит очки профилей всех пользователей для CalculateProfileRank
+// и пересчитывает их не чаще раза в ttl или по POST /admin/users/recompute-ranks.
+// Пересчёт идёт под мьютексом, чтобы одновременные просмотры профилей после
+// истечения ttl не запускали его параллельно.
+type ProfileRanks struct {
+	ttl        time.Duration
+	mu         sync.Mutex
+	points     map[uint]int
+	computedAt time.Time
+}
+
+// NewProfileRanks creates an empty cache; первый просмотр профиля его заполнит.
+func NewProfileRanks(ttl time.Duration) *ProfileRanks {
+	return &ProfileRanks{ttl: ttl}
+}
+
+// get returns cached points, recomputing them when they are older than ttl.
+// На nil считает очки без кэша.
+func (r *ProfileRanks) get(db *gorm.DB) (map[uint]int, error) {
+	if r == nil {
+		return calculateAllUserPoints(db)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.points != nil && time.Since(r.computedAt) < r.ttl {
+		return r.points, nil
+	}
+	return r.recomputeLocked(db)
+}
+
+// refresh recomputes points regardless of their age.
+func (r *ProfileRanks) refresh(db *gorm.DB) (map[uint]int, error) {
+	if r == nil {
+		return calculateAllUserPoints(db)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recomputeLocked(db)
+}
+
+func (r *ProfileRanks) recomputeLocked(db *gorm.DB) (map[uint]int, error) {
+	points, err := calculateAllUserPoints(db)
+	if err != nil {
+		return nil, err
+	}
+	r.points, r.computedAt = points, time.Now()
+	return points, nil
+}
+
 // CalculateProfileRank returns the user's position by profile experience.
-// Раньше здесь был O(N·M): на каждый просмотр профиля грузились все пользователи
-// и для каждого заново считалась полная статистика (~8 запросов на юзера).
-// Теперь очки всех пользователей считаются несколькими агрегатами, а ранг —
-// простым сравнением в памяти.
+// Очки остальных пользователей берутся из ProfileRanks, свои — из только что
+// посчитанной статистики профиля, чтобы новая рецензия сразу сдвигала ранг.
 func (uc *UserController) CalculateProfileRank(userID uint, userStats UserStats) int {
-	points := uc.calculateAllUserPoints()
-	userPoints := points[userID]
-	if userPoints == 0 {
-		// Подстраховка: если по какой-то причине юзера нет в агрегате,
-		// берём очки из уже посчитанной статистики профиля.
-		userPoints = calculateProfilePoints(userStats)
+	points, err := uc.Ranks.get(uc.DB)
+	if err != nil {
+		slog.Warn("failed to calculate profile points", "error", err)
 	}
+	userPoints := calculateProfilePoints(userStats)
 
 	rank := 1
 	for id, p := range points {
@@ -344,94 +406,124 @@ func (uc *UserController) CalculateProfileRank(userID uint, userStats UserStats)
 
 // calculateAllUserPoints возвращает карту "userID -> очки профиля" для всех
 // пользователей, рассчитанную фиксированным числом агрегирующих запросов.
-func (uc *UserController) calculateAllUserPoints() map[uint]int {
+func calculateAllUserPoints(db *gorm.DB) (map[uint]int, error) {
 	type aggRow struct {
 		UserID uint
 		N      int64
 	}
 
-	addAll := func(rows []aggRow, weight int, acc map[uint]int) {
-		for _, r := range rows {
-			acc[r.UserID] += int(r.N) * weight
-		}
+	aggregates := []struct {
+		weight int
+		query  *gorm.DB
+	}{
+		// Одобренные рецензии автора.
+		{320, db.Model(&models.Review{}).
+			Select("user_id, COUNT(*) AS n").
+			Where("status = ?", models.ReviewStatusApproved).
+			Group("user_id")},
+		// Лайки, поставленные пользователем.
+		{12, db.Model(&models.ReviewLike{}).Select("user_id, COUNT(*) AS n").Group("user_id")},
+		{12, db.Model(&models.AlbumLike{}).Select("user_id, COUNT(*) AS n").Group("user_id")},
+		{12, db.Model(&models.TrackLike{}).Select("user_id, COUNT(*) AS n").Group("user_id")},
+		// Лайки, полученные на одобренные рецензии автора.
+		{55, db.Table("review_likes").
+			Select("reviews.user_id AS user_id, COUNT(*) AS n").
+			Joins("JOIN reviews ON reviews.id = review_likes.review_id").
+			Where("reviews.status = ? AND reviews.deleted_at IS NULL AND review_likes.deleted_at IS NULL", models.ReviewStatusApproved).
+			Group("reviews.user_id")},
+		// Лайки от верифицированных артистов.
+		{240, db.Table("review_likes").
+			Select("reviews.user_id AS user_id, COUNT(*) AS n").
+			Joins("JOIN reviews ON reviews.id = review_likes.review_id").
+			Joins("JOIN users ON users.id = review_likes.user_id").
+			Where("reviews.status = ? AND reviews.deleted_at IS NULL AND review_likes.deleted_at IS NULL AND users.is_verified_artist = ?", models.ReviewStatusApproved, true).
+			Group("reviews.user_id")},
 	}
 
 	points := make(map[uint]int)
+	for _, agg := range aggregates {
+		var rows []aggRow
+		if err := agg.query.Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			points[row.UserID] += int(row.N) * agg.weight
+		}
+	}
+	return points, nil
+}
 
-	// Одобренные рецензии на автора.
-	var reviews []aggRow
-	uc.DB.Model(&models.Review{}).
-		Select("user_id, COUNT(*) AS n").
-		Where("status = ?", models.ReviewStatusApproved).
-		Group("user_id").Scan(&reviews)
-	addAll(reviews, 320, points)
-
-	// Лайки, поставленные пользователем.
-	var given []aggRow
-	uc.DB.Model(&models.ReviewLike{}).
-		Select("user_id, COUNT(*) AS n").
-		Group("user_id").Scan(&given)
-	addAll(given, 12, points)
-	var albumLikesGiven []aggRow
-	uc.DB.Model(&models.AlbumLike{}).
-		Select("user_id, COUNT(*) AS n").
-		Group("user_id").Scan(&albumLikesGiven)
-	addAll(albumLikesGiven, 12, points)
-	var trackLikesGiven []aggRow
-	uc.DB.Model(&models.TrackLike{}).
-		Select("user_id, COUNT(*) AS n").
-		Group("user_id").Scan(&trackLikesGiven)
-	addAll(trackLikesGiven, 12, points)
-
-	// Лайки, полученные на одобренные рецензии автора.
-	var received []aggRow
-	uc.DB.Table("review_likes").
-		Select("reviews.user_id AS user_id, COUNT(*) AS n").
-		Joins("JOIN reviews ON reviews.id = review_likes.review_id").
-		Where("reviews.status = ? AND reviews.deleted_at IS NULL", models.ReviewStatusApproved).
-		Group("reviews.user_id").Scan(&received)
-	addAll(received, 55, points)
-
-	// Лайки от верифицированных артистов.
-	var authorReceived []aggRow
-	uc.DB.Table("review_likes").
-		Select("reviews.user_id AS user_id, COUNT(*) AS n").
-		Joins("JOIN reviews ON reviews.id = review_likes.review_id").
-		Joins("JOIN users ON users.id = review_likes.user_id").
-		Where("reviews.status = ? AND reviews.deleted_at IS NULL AND users.is_verified_artist = ?", models.ReviewStatusApproved, true).
-		Group("reviews.user_id").Scan(&authorReceived)
-	addAll(authorReceived, 240, points)
+// RecomputeProfileRanks пересчитывает очки профилей сразу, не дожидаясь
+// ProfileRanksTTL, — после импорта или массовой модерации. Звания профиля
+// не кэшируются (два агрегата на просмотр), пересчитывать их не нужно.
+func (uc *UserController) RecomputeProfileRanks(c *gin.Context) {
+	points, err := uc.Ranks.refresh(requestDB(c, uc.DB))
+	if err != nil {
+		middleware.Logger(c).Error("failed to recompute profile ranks", "error", err)
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to recompute profile ranks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Profile ranks recomputed", "users": len(points)})
+}
 
-	return points
+// profileSummary — звания, статистика, ранг и жанры профиля в ответах GetUser
+// и UpdateUser.
+type profileSummary struct {
+	Badges     []Badge
+	Stats      UserStats
+	Rank       int
+	GenreStats []GenreStat
 }
 
-// CalculateUserStats returns profile statistics for a user
-func (uc *UserController) CalculateUserStats(userID uint) UserStats {
-	var stats UserStats
+// profileSummary считает всё агрегатами, без загрузки рецензий: число запросов
+// не зависит от того, сколько рецензий у пользователя. Жанровый агрегат нужен
+// и статистике, и званиям, поэтому выполняется один раз.
+func (uc *UserController) profileSummary(userID uint) profileSummary {
+	genreCounts, err := uc.reviewedGenreCounts(userID)
+	if err != nil {
+		slog.Warn("failed to count reviewed genres", "user_id", userID, "error", err)
+		genreCounts = []GenreStat{}
+	}
+	stats := uc.CalculateUserStats(userID, genreCounts)
+	return profileSummary{
+		Badges:     calculateUserBadges(stats.TotalReviews, genreCounts),
+		Stats:      stats,
+		Rank:       uc.CalculateProfileRank(userID, stats),
+		GenreStats: topGenreStats(genreCounts),
+	}
+}
 
-	var reviews []models.Review
-	uc.DB.Where("user_id = ? AND status = ?", userID, models.ReviewStatusApproved).Find(&reviews)
-	stats.TotalReviews = len(reviews)
+// CalculateUserStats returns profile statistics for a user; genreCounts — из
+// reviewedGenreCounts, первый жанр становится top_genre.
+func (uc *UserController) CalculateUserStats(userID uint, genreCounts []GenreStat) UserStats {
+	var stats UserStats
 
-	if stats.TotalReviews > 0 {
-		var totalScore float64
-		for _, r := range reviews {
-			totalScore += r.FinalScore
-		}
-		stats.AvgScore = math.Round(totalScore/float64(stats.TotalReviews)*10) / 10
+	var totals struct {
+		Count    int64
+		AvgScore float64
 	}
+	uc.DB.Model(&models.Review{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(final_score), 0) AS avg_score").
+		Where("user_id = ? AND status = ?", userID, models.ReviewStatusApproved).
+		Scan(&totals)
+	stats.TotalReviews = int(totals.Count)
+	stats.AvgScore = math.Round(totals.AvgScore*10) / 10
 
-	var reviewIDs []uint
-	for _, r := range reviews {
-		reviewIDs = append(reviewIDs, r.ID)
-	}
-	if len(reviewIDs) > 0 {
-		uc.DB.Model(&models.ReviewLike{}).Where("review_id IN ?", reviewIDs).Count(&stats.TotalLikesReceived)
-		uc.DB.Model(&models.ReviewLike{}).
-			Joins("JOIN users ON users.id = review_likes.user_id").
-			Where("review_likes.review_id IN ? AND users.is_verified_artist = ?", reviewIDs, true).
-			Count(&stats.AuthorLikesReceived)
+	receivedLikes := func() *gorm.DB {
+		return uc.DB.Model(&models.ReviewLike{}).
+			Joins("JOIN reviews ON reviews.id = review_likes.review_id AND reviews.deleted_at IS NULL").
+			Where("reviews.user_id = ? AND reviews.status = ?", userID, models.ReviewStatusApproved)
 	}
+	receivedLikes().Count(&stats.TotalLikesReceived)
+	receivedLikes().
+		Joins("JOIN users ON users.id = review_likes.user_id").
+		Where("users.is_verified_artist = ?", true).
+		Count(&stats.AuthorLikesReceived)
+
 	uc.DB.Model(&models.Review{}).
 		Where("user_id = ? AND status = ? AND btrim(coalesce(text, '')) = ''", userID, models.ReviewStatusApproved).
 		Count(&stats.RatingsWithoutReview)
@@ -441,9 +533,8 @@ func (uc *UserController) CalculateUserStats(userID uint) UserStats {
 	uc.DB.Model(&models.TrackLike{}).Where("user_id = ?", userID).Count(&trackLikesGiven)
 	stats.TotalLikesGiven = reviewLikesGiven + albumLikesGiven + trackLikesGiven
 
-	genreStats := uc.CalculateGenreStats(userID)
-	if len(genreStats) > 0 {
-		stats.TopGenre = genreStats[0].Name
+	if len(genreCounts) > 0 {
+		stats.TopGenre = genreCounts[0].Name
 	}
 
 	return stats
@@ -454,40 +545,169 @@ type GenreStat struct {
 	Count int    `json:"count"`
 }
 
-// CalculateGenreStats returns review counts per genre for radar chart
-func (uc *UserController) CalculateGenreStats(userID uint) []GenreStat {
-	var reviews []models.Review
-	uc.DB.Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Genres").
-		Where("user_id = ? AND status = ?", userID, models.ReviewStatusApproved).
-		Find(&reviews)
+// reviewedGenreCounts считает одобренные рецензии пользователя по жанрам одним
+// агрегатом: жанр альбома для рецензий на альбом и каждый жанр трека для рецензий
+// на трек. Рецензии на удалённые альбомы и треки не учитываются.
+func (uc *UserController) reviewedGenreCounts(userID uint) ([]GenreStat, error) {
+	result := []GenreStat{}
+	err := uc.DB.Raw(`
+		SELECT genres.name, COUNT(*) AS count
+		FROM (
+			SELECT albums.genre_id
+			FROM reviews
+			JOIN albums ON albums.id = reviews.album_id AND albums.deleted_at IS NULL
+			WHERE reviews.user_id = ? AND reviews.status = ? AND reviews.deleted_at IS NULL
+			UNION ALL
+			SELECT track_genres.genre_id
+			FROM reviews
+			JOIN tracks ON tracks.id = reviews.track_id AND tracks.deleted_at IS NULL
+			JOIN track_genres ON track_genres.track_id = tracks.id
+			WHERE reviews.user_id = ? AND reviews.status = ? AND reviews.deleted_at IS NULL
+		) reviewed
+		JOIN genres ON genres.id = reviewed.genre_id
+		GROUP BY genres.name
+		ORDER BY count DESC, genres.name ASC`,
+		userID, models.ReviewStatusApproved, userID, models.ReviewStatusApproved).Scan(&result).Error
+	return result, err
+}
 
-	counts := make(map[string]int)
-	for _, r := range reviews {
-		if r.AlbumID != nil && r.Album != nil && r.Album.Genre.ID > 0 {
-			counts[r.Album.Genre.Name]++
-		}
-		if r.TrackID != nil && r.Track != nil {
-			for _, g := range r.Track.Genres {
-				if g.ID > 0 {
-					counts[g.Name]++
-				}
-			}
+// topGenreStats returns at most 8 genres for the radar chart.
+func topGenreStats(genreCounts []GenreStat) []GenreStat {
+	if len(genreCounts) > 8 {
+		return genreCounts[:8]
+	}
+	return genreCounts
+}
+
+// MonthlyReviewCount is one point of the profile activity chart
+type MonthlyReviewCount struct {
+	Month string `json:"month"` // YYYY-MM
+	Count int64  `json:"count"`
+}
+
+// GetUserStats returns aggregated public statistics of a user profile.
+// Как и profileSummary, всё считается агрегатами в БД, без загрузки рецензий.
+func (uc *UserController) GetUserStats(c *gin.Context) {
+	var user models.User
+	if err := requestDB(c, uc.DB).Unscoped().First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "User not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	// Статистику удалённого аккаунта видит только администратор.
+	if user.DeletedAt.Valid {
+		if viewer, ok := middleware.GetUserFromContext(c); !ok || !viewer.IsAdmin {
+			c.JSON(http.StatusNotFound, utils.ErrorResponse{
+				Error:   "Not Found",
+				Message: "User not found",
+				Code:    http.StatusNotFound,
+			})
+			return
 		}
 	}
 
-	result := make([]GenreStat, 0, len(counts))
-	for name, count := range counts {
-		result = append(result, GenreStat{Name: name, Count: count})
+	var totals struct {
+		Count    int64
+		AvgScore float64
 	}
-	// Sort descending by count
-	sort.SliceStable(result, func(i, j int) bool {
-		return result[i].Count > result[j].Count
-	})
-	// Return top 8 genres max
-	if len(result) > 8 {
-		result = result[:8]
+	if err := requestDB(c, uc.DB).Model(&models.Review{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(final_score), 0) AS avg_score").
+		Where("user_id = ? AND status = ?", user.ID, models.ReviewStatusApproved).
+		Scan(&totals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to calculate user stats",
+			Code:    http.StatusInternalServerError,
+		})
+		return
 	}
-	return result
+
+	genreCounts, err := uc.withRequest(c).reviewedGenreCounts(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to calculate user stats",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	topGenre := ""
+	if len(genreCounts) > 0 {
+		topGenre = genreCounts[0].Name
+	}
+
+	monthly := []MonthlyReviewCount{}
+	if err := requestDB(c, uc.DB).Raw(`
+		SELECT to_char(months.month, 'YYYY-MM') AS month, COUNT(reviews.id) AS count
+		FROM generate_series(date_trunc('month', NOW()) - INTERVAL '11 months', date_trunc('month', NOW()), INTERVAL '1 month') AS months(month)
+		LEFT JOIN reviews ON date_trunc('month', reviews.created_at) = months.month
+			AND reviews.user_id = ? AND reviews.status = ? AND reviews.deleted_at IS NULL
+		GROUP BY months.month
+		ORDER BY months.month`,
+		user.ID, models.ReviewStatusApproved).Scan(&monthly).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to calculate user stats",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	var likesReceived int64
+	if err := requestDB(c, uc.DB).Model(&models.ReviewLike{}).
+		Joins("JOIN reviews ON reviews.id = review_likes.review_id AND reviews.deleted_at IS NULL").
+		Where("reviews.user_id = ? AND reviews.status = ?", user.ID, models.ReviewStatusApproved).
+		Count(&likesReceived).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to calculate user stats",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// Число лайкнутых альбомов/треков — часть библиотеки лайков, при likes_private скрываем.
+	var likedAlbums, likedTracks interface{}
+	if canSeeUserLikes(c, &user) {
+		var albumsCount, tracksCount int64
+		if err := requestDB(c, uc.DB).Model(&models.AlbumLike{}).
+			Where("user_id = ?", user.ID).
+			Where("EXISTS (SELECT 1 FROM albums WHERE albums.id = album_likes.album_id AND albums.deleted_at IS NULL)").
+			Count(&albumsCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to calculate user stats",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		if err := requestDB(c, uc.DB).Model(&models.TrackLike{}).
+			Where("user_id = ?", user.ID).
+			Where("EXISTS (SELECT 1 FROM tracks WHERE tracks.id = track_likes.track_id AND tracks.deleted_at IS NULL)").
+			Count(&tracksCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to calculate user stats",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		likedAlbums, likedTracks = albumsCount, tracksCount
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":                user.ID,
+		"approved_reviews_count": totals.Count,
+		"average_score_given":    math.Round(totals.AvgScore*10) / 10,
+		"top_genre":              topGenre,
+		"reviews_per_month":      monthly,
+		"likes_received":         likesReceived,
+		"liked_albums_count":     likedAlbums,
+		"liked_tracks_count":     likedTracks,
+	})
 }
 
 // canSeeAllReviewStatuses сообщает, вправе ли текущий зритель видеть рецензии
@@ -504,16 +724,164 @@ func canSeeAllReviewStatuses(c *gin.Context, targetID string) bool {
 	return strconv.FormatUint(uint64(user.ID), 10) == targetID
 }
 
-// GetUserLikedReviews retrieves reviews liked by a user.
+// canSeeUserLikes сообщает, может ли текущий зритель видеть лайки пользователя:
+// владелец и администратор — всегда, остальные — если лайки не скрыты (likes_private).
+func canSeeUserLikes(c *gin.Context, target *models.User) bool {
+	if !target.LikesPrivate {
+		return true
+	}
+	viewer, ok := middleware.GetUserFromContext(c)
+	return ok && (viewer.IsAdmin || viewer.ID == target.ID)
+}
+
+// canSeeUserEmail: email виден владельцу и администратору всегда, остальным —
+// только если пользователь сам включил email_public.
+func canSeeUserEmail(c *gin.Context, target *models.User) bool {
+	if target.EmailPublic {
+		return true
+	}
+	viewer, ok := middleware.GetUserFromContext(c)
+	return ok && (viewer.IsAdmin || viewer.ID == target.ID)
+}
+
+// loadLikesOwner загружает владельца лайков и проверяет доступ к ним. При отказе
+// сам пишет ответ и возвращает false.
+func (uc *UserController) loadLikesOwner(c *gin.Context) (*models.User, bool) {
+	var user models.User
+	if err := requestDB(c, uc.DB).First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "User not found",
+			Code:    http.StatusNotFound,
+		})
+		return nil, false
+	}
+	if !canSeeUserLikes(c, &user) {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "User's likes are private",
+			Code:    http.StatusForbidden,
+		})
+		return nil, false
+	}
+	return &user, true
+}
+
+// GetUserLikedTracks retrieves tracks liked by a user, newest likes first.
+func (uc *UserController) GetUserLikedTracks(c *gin.Context) {
+	user, ok := uc.loadLikesOwner(c)
+	if !ok {
+		return
+	}
+
+	page := utils.ParsePage(c)
+
+	// Лайки на удалённые треки не показываем и не считаем.
+	query := requestDB(c, uc.DB).Model(&models.TrackLike{}).
+		Where("user_id = ?", user.ID).
+		Where("EXISTS (SELECT 1 FROM tracks WHERE tracks.id = track_likes.track_id AND tracks.deleted_at IS NULL)")
+
+	var total int64
+	query.Count(&total)
+
+	var likes []models.TrackLike
+	if err := query.
+		Preload("Track").
+		Preload("Track.Album").
+		Preload("Track.Album.Genre").
+		Preload("Track.Genres").
+		Preload("Track.Likes").
+		Order("created_at desc").
+		Offset(page.Offset()).Limit(page.Size).
+		Find(&likes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch liked tracks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	tracks := make([]models.Track, 0, len(likes))
+	for _, like := range likes {
+		if like.Track.ID == 0 {
+			continue
+		}
+		track := like.Track
+		likedAt := like.CreatedAt
+		track.LikedAt = &likedAt
+		tracks = append(tracks, track)
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("tracks", tracks, total, page))
+}
+
+// GetUserLikedAlbums retrieves albums liked by a user, newest likes first.
+func (uc *UserController) GetUserLikedAlbums(c *gin.Context) {
+	user, ok := uc.loadLikesOwner(c)
+	if !ok {
+		return
+	}
+
+	page := utils.ParsePage(c)
+
+	// Лайки на удалённые альбомы не показываем и не считаем.
+	query := requestDB(c, uc.DB).Model(&models.AlbumLike{}).
+		Where("user_id = ?", user.ID).
+		Where("EXISTS (SELECT 1 FROM albums WHERE albums.id = album_likes.album_id AND albums.deleted_at IS NULL)")
+
+	var total int64
+	query.Count(&total)
+
+	var likes []models.AlbumLike
+	if err := query.
+		Preload("Album").
+		Preload("Album.Genre").
+		Preload("Album.Likes").
+		Order("created_at desc").
+		Offset(page.Offset()).Limit(page.Size).
+		Find(&likes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch liked albums",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	albums := make([]models.Album, 0, len(likes))
+	for _, like := range likes {
+		if like.Album.ID == 0 {
+			continue
+		}
+		album := like.Album
+		likedAt := like.CreatedAt
+		album.LikedAt = &likedAt
+		albums = append(albums, album)
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("albums", albums, total, page))
+}
+
+// GetUserLikedReviews retrieves reviews liked by a user, newest likes first.
 func (uc *UserController) GetUserLikedReviews(c *gin.Context) {
-	id := c.Param("id")
-	var likes []models.ReviewLike
+	user, ok := uc.loadLikesOwner(c)
+	if !ok {
+		return
+	}
+
+	page := utils.ParsePage(c)
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
+	// Лайки на удалённые рецензии не показываем и не считаем.
+	query := requestDB(c, uc.DB).Model(&models.ReviewLike{}).
+		Where("user_id = ?", user.ID).
+		Where("EXISTS (SELECT 1 FROM reviews WHERE reviews.id = review_likes.review_id AND reviews.deleted_at IS NULL)")
+
+	var total int64
+	query.Count(&total)
 
-	query := uc.DB.
+	var likes []models.ReviewLike
+	if err := query.
 		Preload("Review.User").
 		Preload("Review.Album").
 		Preload("Review.Album.Genre").
@@ -521,13 +889,9 @@ func (uc *UserController) GetUserLikedReviews(c *gin.Context) {
 		Preload("Review.Track.Album").
 		Preload("Review.Likes").
 		Preload("Review.Likes.User").
-		Where("user_id = ?", id).
-		Order("created_at desc")
-
-	var total int64
-	query.Model(&models.ReviewLike{}).Count(&total)
-
-	if err := query.Offset(offset).Limit(pageSize).Find(&likes).Error; err != nil {
+		Order("created_at desc").
+		Offset(page.Offset()).Limit(page.Size).
+		Find(&likes).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch liked reviews",
@@ -542,14 +906,9 @@ func (uc *UserController) GetUserLikedReviews(c *gin.Context) {
 			reviews = append(reviews, like.Review)
 		}
 	}
-	annotateArtistMarks(uc.DB, reviews)
+	annotateArtistMarks(requestDB(c, uc.DB), reviews)
 
-	c.JSON(http.StatusOK, gin.H{
-		"reviews":   reviews,
-		"total":     total,
-		"page":      page,
-		"page_size": pageSize,
-	})
+	c.JSON(http.StatusOK, utils.NewPaginated("reviews", reviews, total, page))
 }
 
 // GetUserReviews retrieves reviews by user ID
@@ -557,7 +916,7 @@ func (uc *UserController) GetUserReviews(c *gin.Context) {
 	id := c.Param("id")
 	var reviews []models.Review
 
-	query := uc.DB.Preload("User").Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Album").Preload("Likes").Preload("Likes.User").Where("user_id = ?", id)
+	query := requestDB(c, uc.DB).Preload("User", withDeletedAuthor).Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Album").Preload("Likes").Preload("Likes.User").Where("user_id = ?", id)
 
 	// Чужие непубличные рецензии (pending/rejected) показываем только владельцу
 	// или администратору. Иначе принудительно фильтруем по approved.
@@ -572,14 +931,12 @@ func (uc *UserController) GetUserReviews(c *gin.Context) {
 	query = query.Order(utils.SafeOrderClause(c.Query("sort_by"), c.Query("sort_order"), reviewSortColumns, "created_at"))
 
 	// Pagination
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	offset := (page - 1) * pageSize
+	page := utils.ParsePage(c)
 
 	var total int64
 	query.Model(&models.Review{}).Count(&total)
 
-	if err := query.Offset(offset).Limit(pageSize).Find(&reviews).Error; err != nil {
+	if err := query.Offset(page.Offset()).Limit(page.Size).Find(&reviews).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to fetch reviews",
@@ -587,85 +944,323 @@ func (uc *UserController) GetUserReviews(c *gin.Context) {
 		})
 		return
 	}
-	annotateArtistMarks(uc.DB, reviews)
+	annotateArtistMarks(requestDB(c, uc.DB), reviews)
 
-	c.JSON(http.StatusOK, gin.H{
-		"reviews":   reviews,
-		"total":     total,
-		"page":      page,
-		"page_size": pageSize,
-	})
+	c.JSON(http.StatusOK, utils.NewPaginated("reviews", reviews, total, page))
 }
 
-// UpdateUser updates user profile
-func (uc *UserController) UpdateUser(c *gin.Context) {
-	id := c.Param("id")
-	var user models.User
+// leaderboardMax — лидерборд отдаёт не больше топ-100 пользователей.
+const leaderboardMax = 100
+
+// LeaderboardEntry is a row of the critics leaderboard
+type LeaderboardEntry struct {
+	Rank        int64  `json:"rank"`
+	UserID      uint   `json:"user_id"`
+	Username    string `json:"username"`
+	AvatarPath  string `json:"avatar_path"`
+	ReviewCount int64  `json:"review_count"`
+	Karma       int64  `json:"karma"`
+}
 
-	if err := uc.DB.First(&user, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, utils.ErrorResponse{
-			Error:   "Not Found",
-			Message: "User not found",
-			Code:    http.StatusNotFound,
-		})
-		return
+// GetLeaderboard ranks users by karma: likes received on their approved reviews
+func (uc *UserController) GetLeaderboard(c *gin.Context) {
+	// Без window карма считается за всё время; иначе — только лайки за окно (1h–30d).
+	likesFilter := ""
+	args := []interface{}{models.ReviewStatusApproved}
+	if raw := c.Query("window"); raw != "" {
+		window, err := parsePopularWindow(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Bad Request",
+				Message: err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		likesFilter = " AND review_likes.created_at >= ?"
+		args = append(args, time.Now().Add(-window))
+	}
+	args = append(args, models.ReviewStatusApproved)
+
+	ranked := `SELECT ROW_NUMBER() OVER (ORDER BY k.karma DESC, users.id ASC) AS rank,
+			users.id AS user_id, users.username, users.avatar_path,
+			COALESCE(rc.review_count, 0) AS review_count, k.karma
+		FROM (
+			SELECT reviews.user_id, COUNT(*) AS karma
+			FROM review_likes
+			JOIN reviews ON reviews.id = review_likes.review_id
+			WHERE reviews.status = ? AND reviews.deleted_at IS NULL AND review_likes.deleted_at IS NULL` + likesFilter + `
+			GROUP BY reviews.user_id
+		) k
+		JOIN users ON users.id = k.user_id AND users.deleted_at IS NULL
+		LEFT JOIN (
+			SELECT user_id, COUNT(*) AS review_count
+			FROM reviews
+			WHERE status = ? AND deleted_at IS NULL
+			GROUP BY user_id
+		) rc ON rc.user_id = users.id`
+
+	page := utils.ParsePage(c)
+	if page.Size > leaderboardMax {
+		page.Size = leaderboardMax
+	}
+	from := page.Offset()
+	to := from + page.Size
+	if to > leaderboardMax {
+		to = leaderboardMax
 	}
 
-	userID, exists := middleware.GetUserIDFromContext(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
-			Error:   "Unauthorized",
-			Message: "User not authenticated",
-			Code:    http.StatusUnauthorized,
+	var total int64
+	if err := requestDB(c, uc.DB).Raw("SELECT COUNT(*) FROM ("+ranked+") ranked", args...).Scan(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch leaderboard",
+			Code:    http.StatusInternalServerError,
 		})
 		return
 	}
+	if total > leaderboardMax {
+		total = leaderboardMax
+	}
 
-	// Check if user is updating their own profile or is admin
-	userModel, _ := middleware.GetUserFromContext(c)
-	if user.ID != userID && !userModel.IsAdmin {
-		c.JSON(http.StatusForbidden, utils.ErrorResponse{
-			Error:   "Forbidden",
-			Message: "You don't have permission to update this user",
-			Code:    http.StatusForbidden,
-		})
-		return
+	entries := []LeaderboardEntry{}
+	if from < to {
+		pageArgs := append(append([]interface{}{}, args...), from, to)
+		if err := requestDB(c, uc.DB).Raw("SELECT * FROM ("+ranked+") ranked WHERE rank > ? AND rank <= ? ORDER BY rank", pageArgs...).
+			Scan(&entries).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to fetch leaderboard",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
 	}
 
-	var req struct {
-		Username    string            `json:"username"`
-		Email       string            `json:"email"`
-		AvatarPath  string            `json:"avatar_path"`
-		Bio         string            `json:"bio"`
-		SocialLinks map[string]string `json:"social_links"` // {"vk": "", "telegram": "", "instagram": ""}
-		Password    string            `json:"password"`     // For password change
+	// Место вызывающего считается по всему рейтингу, а не только по топ-100.
+	var me *LeaderboardEntry
+	if userID, ok := middleware.GetUserIDFromContext(c); ok {
+		var mine []LeaderboardEntry
+		meArgs := append(append([]interface{}{}, args...), userID)
+		if err := requestDB(c, uc.DB).Raw("SELECT * FROM ("+ranked+") ranked WHERE user_id = ?", meArgs...).Scan(&mine).Error; err == nil && len(mine) > 0 {
+			me = &mine[0]
+		}
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	body := utils.NewPaginated("users", entries, total, page).Fields()
+	body["me"] = me
+	c.JSON(http.StatusOK, body)
+}
+
+// AdminUserRow is a row of the admin users list with review aggregates
+type AdminUserRow struct {
+	ID               uint       `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	AvatarPath       string     `json:"avatar_path"`
+	IsAdmin          bool       `json:"is_admin"`
+	IsVerifiedArtist bool       `json:"is_verified_artist"`
+	ArtistName       string     `json:"artist_name,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ReviewCount      int64      `json:"review_count"`
+	LastReviewAt     *time.Time `json:"last_review_at"`
+}
+
+// adminUserSortColumns — белый список сортировок админского списка пользователей.
+var adminUserSortColumns = map[string]string{
+	"created_at":     "users.created_at",
+	"review_count":   "review_count",
+	"last_review_at": "last_review_at",
+}
+
+// optionalBoolQuery parses an optional boolean query parameter; on a malformed
+// value it responds 400 and returns ok=false.
+func optionalBoolQuery(c *gin.Context, name string) (*bool, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 			Error:   "Bad Request",
-			Message: err.Error(),
+			Message: name + " must be true or false",
 			Code:    http.StatusBadRequest,
 		})
-		return
+		return nil, false
 	}
+	return &value, true
+}
 
-	// Update username if provided
-	if req.Username != "" {
-		if err := utils.ValidateUsername(req.Username); err != nil {
-			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+// AdminListUsers lists users for admins with search, filters and review aggregates
+func (uc *UserController) AdminListUsers(c *gin.Context) {
+	isAdmin, ok := optionalBoolQuery(c, "is_admin")
+	if !ok {
+		return
+	}
+	verified, ok := optionalBoolQuery(c, "verified")
+	if !ok {
+		return
+	}
+	search := strings.TrimSpace(c.Query("search"))
+	filters := func(db *gorm.DB) *gorm.DB {
+		if search != "" {
+			db = db.Where("users.username ILIKE ? OR users.email ILIKE ?", "%"+search+"%", "%"+search+"%")
+		}
+		if isAdmin != nil {
+			db = db.Where("users.is_admin = ?", *isAdmin)
+		}
+		if verified != nil {
+			db = db.Where("users.is_verified_artist = ?", *verified)
+		}
+		return db
+	}
+
+	page := utils.ParsePage(c)
+
+	var total int64
+	requestDB(c, uc.DB).Model(&models.User{}).Scopes(filters).Count(&total)
+
+	// Агрегаты по рецензиям одним подзапросом, а не по запросу на пользователя.
+	reviewStats := requestDB(c, uc.DB).Model(&models.Review{}).
+		Select("user_id, COUNT(*) AS review_count, MAX(created_at) AS last_review_at").
+		Group("user_id")
+
+	rows := []AdminUserRow{}
+	if err := requestDB(c, uc.DB).Model(&models.User{}).
+		Select(`users.id, users.username, users.email, users.avatar_path, users.is_admin,
+			users.is_verified_artist, users.artist_name, users.created_at,
+			COALESCE(rs.review_count, 0) AS review_count, rs.last_review_at`).
+		Joins("LEFT JOIN (?) AS rs ON rs.user_id = users.id", reviewStats).
+		Scopes(filters).
+		Order(utils.SafeOrderClause(c.Query("sort_by"), c.DefaultQuery("sort_order", "desc"), adminUserSortColumns, "created_at") + " NULLS LAST, users.id DESC").
+		Offset(page.Offset()).Limit(page.Size).
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to fetch users",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.NewPaginated("users", rows, total, page))
+}
+
+// UpdateUser updates user profile
+func (uc *UserController) UpdateUser(c *gin.Context) {
+	id := c.Param("id")
+	var user models.User
+
+	if err := requestDB(c, uc.DB).First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "User not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// Check if user is updating their own profile or is admin
+	userModel, _ := middleware.GetUserFromContext(c)
+	if user.ID != userID && !userModel.IsAdmin {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "You don't have permission to update this user",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	var req struct {
+		Username     string            `json:"username"`
+		Email        string            `json:"email"`
+		Bio          string            `json:"bio"`
+		SocialLinks  map[string]string `json:"social_links"` // vk, telegram, instagram, youtube, max, site
+		Password     string            `json:"password"`     // For password change
+		LikesPrivate *bool             `json:"likes_private"`
+		EmailPublic  *bool             `json:"email_public"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondBindingError(c, err)
+		return
+	}
+
+	// Update username if provided
+	previousUsername := ""
+	if req.Username != "" && req.Username != user.Username {
+		if err := utils.ValidateUsername(req.Username); err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 				Error:   "Validation Error",
 				Message: err.Error(),
 				Code:    http.StatusBadRequest,
 			})
 			return
 		}
+		var taken int64
+		requestDB(c, uc.DB).Model(&models.User{}).Where("LOWER(username) = LOWER(?) AND id <> ?", req.Username, user.ID).Count(&taken)
+		if taken > 0 {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: "Username is already taken",
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		if reserved, err := usernameReserved(requestDB(c, uc.DB), req.Username, user.ID); err != nil || reserved {
+			if err != nil {
+				middleware.Logger(c).Error("failed to check username reservation", "error", err)
+			}
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: "Username is already taken",
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		// Регистр той же буквы не считается сменой имени: упоминания и поиск его не различают.
+		if !strings.EqualFold(req.Username, user.Username) {
+			if !userModel.IsAdmin {
+				nextAllowed, err := nextUsernameChangeAt(requestDB(c, uc.DB), user.ID, uc.UsernameChangeCooldown)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+						Error:   "Internal Server Error",
+						Message: "Failed to check username change cooldown",
+						Code:    http.StatusInternalServerError,
+					})
+					return
+				}
+				if wait := time.Until(nextAllowed); wait > 0 {
+					c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+					c.JSON(http.StatusTooManyRequests, utils.ErrorResponse{
+						Error:   "Too Many Requests",
+						Message: fmt.Sprintf("Username can be changed again after %s", nextAllowed.UTC().Format(time.RFC3339)),
+						Code:    http.StatusTooManyRequests,
+					})
+					return
+				}
+			}
+			previousUsername = user.Username
+		}
 		user.Username = req.Username
 	}
 
 	// Update email if provided
+	emailConfirmToken := ""
 	if req.Email != "" {
-		if !utils.ValidateEmail(req.Email) {
+		email := utils.NormalizeEmail(req.Email)
+		if !utils.ValidateEmail(email) {
 			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
 				Error:   "Validation Error",
 				Message: "Invalid email format",
@@ -673,12 +1268,44 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 			})
 			return
 		}
-		user.Email = req.Email
-	}
-
-	// Update avatar path if provided
-	if req.AvatarPath != "" {
-		user.AvatarPath = req.AvatarPath
+		var taken int64
+		requestDB(c, uc.DB).Model(&models.User{}).Where("LOWER(email) = ? AND id <> ?", email, user.ID).Count(&taken)
+		if taken > 0 {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: "Email is already in use",
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		// Адрес меняется только после перехода по ссылке из письма: опечатка не
+		// отрезает от аккаунта, а угнанная сессия не подменяет email молча.
+		if email != user.Email {
+			// Без почты и без токена в ответе ссылку подтверждения не получить,
+			// и pending_email навсегда остался бы неподтверждённым.
+			if uc.confirmationMailer() == nil {
+				c.JSON(http.StatusServiceUnavailable, utils.ErrorResponse{
+					Error:   "Service Unavailable",
+					Message: "Смена email недоступна: отправка писем не настроена",
+					Code:    http.StatusServiceUnavailable,
+				})
+				return
+			}
+			confirmToken, tokenHash, err := utils.NewConfirmToken()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+					Error:   "Internal Server Error",
+					Message: "Failed to create confirmation token",
+					Code:    http.StatusInternalServerError,
+				})
+				return
+			}
+			expires := time.Now().Add(emailConfirmTTL)
+			user.PendingEmail = email
+			user.EmailTokenHash = tokenHash
+			user.EmailTokenExpires = &expires
+			emailConfirmToken = confirmToken
+		}
 	}
 
 	// Update bio if provided
@@ -686,12 +1313,29 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 
 	// Update social links if provided
 	if req.SocialLinks != nil {
-		socialLinksJSON, err := json.Marshal(req.SocialLinks)
+		socialLinks, fieldErrors := utils.NormalizeSocialLinks(req.SocialLinks)
+		if len(fieldErrors) > 0 {
+			c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+				Error:   "Validation Error",
+				Message: "Invalid social links",
+				Code:    http.StatusBadRequest,
+				Fields:  fieldErrors,
+			})
+			return
+		}
+		socialLinksJSON, err := json.Marshal(socialLinks)
 		if err == nil {
 			user.SocialLinks = string(socialLinksJSON)
 		}
 	}
 
+	if req.LikesPrivate != nil {
+		user.LikesPrivate = *req.LikesPrivate
+	}
+	if req.EmailPublic != nil {
+		user.EmailPublic = *req.EmailPublic
+	}
+
 	// Update password if provided
 	if req.Password != "" {
 		if len(req.Password) < 6 {
@@ -714,7 +1358,28 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		user.Password = hashedPassword
 	}
 
-	if err := uc.DB.Save(&user).Error; err != nil {
+	err := requestDB(c, uc.DB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if previousUsername == "" {
+			return nil
+		}
+		return tx.Create(&models.UsernameChange{
+			UserID:      user.ID,
+			OldUsername: previousUsername,
+			NewUsername: user.Username,
+		}).Error
+	})
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: "Username or email is already in use",
+				Code:    http.StatusConflict,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to update user",
@@ -725,13 +1390,10 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 
 	user.Password = ""
 
-	badges := uc.CalculateUserBadges(user.ID)
-	stats := uc.CalculateUserStats(user.ID)
-	profileRank := uc.CalculateProfileRank(user.ID, stats)
-	genreStats := uc.CalculateGenreStats(user.ID)
-	favoriteAlbums := uc.GetFavoriteAlbums(user.FavoriteAlbumIDs)
-	favoriteArtists := uc.GetFavoriteArtists(user.FavoriteArtists)
-	favoriteTracks := uc.GetFavoriteTracks(user.FavoriteTrackIDs)
+	profile := uc.withRequest(c).profileSummary(user.ID)
+	favoriteAlbums := uc.withRequest(c).GetFavoriteAlbums(user.FavoriteAlbumIDs)
+	favoriteArtists := uc.withRequest(c).GetFavoriteArtists(user.FavoriteArtists)
+	favoriteTracks := uc.withRequest(c).GetFavoriteTracks(user.FavoriteTrackIDs)
 
 	userResponse := gin.H{
 		"id":                 user.ID,
@@ -739,7 +1401,7 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		"email":              user.Email,
 		"avatar_path":        user.AvatarPath,
 		"bio":                user.Bio,
-		"social_links":       user.SocialLinks,
+		"social_links":       utils.ParseSocialLinks(user.SocialLinks),
 		"is_admin":           user.IsAdmin,
 		"is_verified_artist": user.IsVerifiedArtist,
 		"artist_name":        user.ArtistName,
@@ -747,25 +1409,159 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 		"favorite_artists":   favoriteArtists,
 		"favorite_track_ids": user.FavoriteTrackIDs,
 		"preferences_manual": user.PreferencesManual,
+		"likes_private":      user.LikesPrivate,
+		"email_public":       user.EmailPublic,
 		"created_at":         user.CreatedAt,
 		"updated_at":         user.UpdatedAt,
-		"badges":             badges,
-		"stats":              stats,
-		"profile_rank":       profileRank,
-		"genre_stats":        genreStats,
+		"badges":             profile.Badges,
+		"stats":              profile.Stats,
+		"profile_rank":       profile.Rank,
+		"genre_stats":        profile.GenreStats,
 		"favorite_albums":    favoriteAlbums,
 		"favorite_tracks":    favoriteTracks,
 	}
+	if user.PendingEmail != "" {
+		userResponse["pending_email"] = user.PendingEmail
+	}
+	if emailConfirmToken != "" {
+		uc.sendEmailConfirmation(user.PendingEmail, emailConfirmToken)
+		// Без почтового сервера токен иначе не получить: отдаём его, только если
+		// это явно включено (config не допускает флаг вне dev).
+		if uc.ExposeConfirmToken {
+			userResponse["email_confirm_token"] = emailConfirmToken
+		}
+	}
 
 	c.JSON(http.StatusOK, userResponse)
 }
 
+// usernameReserveWindow — сколько старый username закреплён за прежним владельцем,
+// чтобы под ним не мог зарегистрироваться или переименоваться кто-то другой.
+const usernameReserveWindow = 30 * 24 * time.Hour
+
+// nextUsernameChangeAt returns when the user may rename again (zero time if right now).
+func nextUsernameChangeAt(db *gorm.DB, userID uint, cooldown time.Duration) (time.Time, error) {
+	if cooldown <= 0 {
+		return time.Time{}, nil
+	}
+	var last models.UsernameChange
+	result := db.Where("user_id = ?", userID).Order("created_at DESC").Limit(1).Find(&last)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return time.Time{}, result.Error
+	}
+	return last.CreatedAt.Add(cooldown), nil
+}
+
+// usernameReserved reports whether another user gave up this username within
+// usernameReserveWindow. Сам прежний владелец может вернуть себе старое имя.
+func usernameReserved(db *gorm.DB, username string, exceptUserID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.UsernameChange{}).
+		Where("LOWER(old_username) = LOWER(?) AND user_id <> ? AND created_at >= ?",
+			username, exceptUserID, time.Now().Add(-usernameReserveWindow)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// emailConfirmTTL — сколько живёт ссылка подтверждения нового email.
+const emailConfirmTTL = 24 * time.Hour
+
+// confirmationMailer returns the mailer for confirmation links: Mailer, а без
+// почты — LogMailer, если токен и так отдаётся в ответе (dev). nil — смену
+// email подтвердить нечем.
+func (uc *UserController) confirmationMailer() utils.Mailer {
+	if uc.Mailer != nil {
+		return uc.Mailer
+	}
+	if uc.ExposeConfirmToken {
+		return utils.LogMailer{}
+	}
+	return nil
+}
+
+// sendEmailConfirmation отправляет ссылку подтверждения на новый адрес.
+// Ошибка отправки не откатывает запрос: пользователь может запросить смену ещё раз.
+func (uc *UserController) sendEmailConfirmation(email, token string) {
+	mailer := uc.confirmationMailer()
+	// В prod nginx проксирует /api с того же домена, что и фронтенд.
+	link := uc.PublicSiteURL + "/api/users/confirm-email?token=" + token
+	body := "Чтобы подтвердить новый адрес, перейдите по ссылке:\n" + link +
+		"\n\nСсылка действует 24 часа. Если вы не меняли email, просто проигнорируйте письмо."
+	if err := mailer.Send(email, "Подтверждение email", body); err != nil {
+		slog.Warn("failed to send email confirmation", "error", err)
+	}
+}
+
+// ConfirmEmail swaps the user's email for the pending one by confirmation token
+func (uc *UserController) ConfirmEmail(c *gin.Context) {
+	token := strings.TrimSpace(c.Query("token"))
+	if token == "" {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "token is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var user models.User
+	if err := requestDB(c, uc.DB).Where("email_token_hash = ? AND pending_email <> ''", utils.HashConfirmToken(token)).
+		First(&user).Error; err != nil || user.EmailTokenExpires == nil || time.Now().After(*user.EmailTokenExpires) {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Confirmation link is invalid or expired",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// Пока письмо шло, адрес мог занять другой пользователь — проверяем заново.
+	var taken int64
+	requestDB(c, uc.DB).Model(&models.User{}).Where("LOWER(email) = ? AND id <> ?", user.PendingEmail, user.ID).Count(&taken)
+	if taken > 0 {
+		c.JSON(http.StatusConflict, utils.ErrorResponse{
+			Error:   "Conflict",
+			Message: "Email is already in use",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	newEmail := user.PendingEmail
+	if err := requestDB(c, uc.DB).Model(&user).Updates(map[string]interface{}{
+		"email":               newEmail,
+		"pending_email":       "",
+		"email_token_hash":    "",
+		"email_token_expires": nil,
+	}).Error; err != nil {
+		if utils.IsUniqueViolation(err) {
+			c.JSON(http.StatusConflict, utils.ErrorResponse{
+				Error:   "Conflict",
+				Message: "Email is already in use",
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to confirm email",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email confirmed",
+		"email":   newEmail,
+	})
+}
+
 // DeleteUser deletes a user
 func (uc *UserController) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 
-	if err := uc.DB.First(&user, id).Error; err != nil {
+	if err := requestDB(c, uc.DB).First(&user, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "User not found",
@@ -795,7 +1591,38 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := uc.DB.Delete(&user).Error; err != nil {
+	strategy := c.DefaultQuery("strategy", userDeleteAnonymize)
+	if strategy != userDeleteAnonymize && strategy != userDeleteCascade {
+		c.JSON(http.StatusBadRequest, utils.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "strategy must be anonymize or cascade",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if strategy == userDeleteCascade && !userModel.IsAdmin {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "Only admins can delete user content",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	oldAvatarPath := user.AvatarPath
+	// При анонимизации одобренные рецензии остаются под анонимным автором,
+	// ожидающие и отклонённые удаляются.
+	if err := requestDB(c, uc.DB).Transaction(func(tx *gorm.DB) error {
+		if strategy == userDeleteCascade {
+			if err := deleteUserContent(tx, user.ID); err != nil {
+				return err
+			}
+		} else if err := tx.Where("user_id = ? AND status <> ?", user.ID, models.ReviewStatusApproved).
+			Delete(&models.Review{}).Error; err != nil {
+			return err
+		}
+		return anonymizeUser(tx, &user)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
 			Message: "Failed to delete user",
@@ -804,62 +1631,321 @@ func (uc *UserController) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	if oldPath, ok := utils.ResolveUserAvatarPath(uc.UploadsDir, oldAvatarPath, user.ID); ok {
+		if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+			middleware.Logger(c).Warn("failed to remove avatar", "path", oldPath, "error", err)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "User deleted successfully",
+		"message":  "User deleted successfully",
+		"strategy": strategy,
 	})
 }
 
-// Badge represents a user badge/achievement
-type Badge struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Criteria    string `json:"criteria"` // как получить звание (для подсказки в UI)
-	Icon        string `json:"icon"`
-	Priority    int    `json:"priority"`
+// Стратегии удаления аккаунта. anonymize — по умолчанию и единственная доступная
+// самому пользователю: одобренные рецензии остаются, автор становится
+// deleted_user_<id>. cascade (только админ) удаляет рецензии и лайки пользователя.
+const (
+	userDeleteAnonymize = "anonymize"
+	userDeleteCascade   = "cascade"
+)
+
+// anonymizeUser стирает личные данные и soft-удаляет пользователя. Username и
+// email заменяются на заглушки, чтобы освободить их под повторную регистрацию:
+// уникальные индексы смотрят и на удалённые строки.
+func anonymizeUser(tx *gorm.DB, user *models.User) error {
+	placeholder := fmt.Sprintf("deleted_user_%d", user.ID)
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"username":           placeholder,
+		"email":              placeholder + "@deleted.invalid",
+		"password":           "!", // не bcrypt-хеш: вход по паролю невозможен
+		"avatar_path":        "",
+		"bio":                "",
+		"social_links":       "{}",
+		"favorite_album_ids": "[]",
+		"favorite_artists":   "[]",
+		"favorite_track_ids": "[]",
+		"preferences_manual": false,
+		"is_verified_artist": false,
+		"artist_name":        "",
+		"is_admin":           false,
+		"likes_private":      true,
+	}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("follower_id = ? OR following_id = ?", user.ID, user.ID).
+		Delete(&models.UserFollow{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(user).Error
 }
 
-// CalculateUserBadges calculates badges for a user based on their reviews
-func (uc *UserController) CalculateUserBadges(userID uint) []Badge {
-	var reviews []models.Review
-	// Get all approved reviews with genre information
-	if err := uc.DB.Preload("Album").Preload("Album.Genre").Preload("Track").Preload("Track.Genres").
-		Where("user_id = ? AND status = ?", userID, models.ReviewStatusApproved).
-		Find(&reviews).Error; err != nil {
-		return []Badge{}
+// deleteUserContent soft-удаляет рецензии и лайки пользователя и пересчитывает
+// рейтинги альбомов и треков, на которые влияли его одобренные рецензии.
+func deleteUserContent(tx *gorm.DB, userID uint) error {
+	var albumIDs, trackIDs []uint
+	if err := tx.Model(&models.Review{}).
+		Where("user_id = ? AND status = ? AND album_id IS NOT NULL", userID, models.ReviewStatusApproved).
+		Distinct().Pluck("album_id", &albumIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Review{}).
+		Where("user_id = ? AND status = ? AND track_id IS NOT NULL", userID, models.ReviewStatusApproved).
+		Distinct().Pluck("track_id", &trackIDs).Error; err != nil {
+		return err
 	}
 
-	if len(reviews) == 0 {
-		return []Badge{}
+	if err := tx.Where("review_id IN (?)", tx.Model(&models.Review{}).Select("id").Where("user_id = ?", userID)).
+		Delete(&models.ReviewLike{}).Error; err != nil {
+		return err
+	}
+	for _, model := range []interface{}{&models.Review{}, &models.ReviewLike{}, &models.AlbumLike{}, &models.TrackLike{}} {
+		if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+			return err
+		}
 	}
 
-	// Count reviews by genre
-	genreCounts := make(map[string]int)
-	totalReviews := len(reviews)
-	uniqueGenres := make(map[string]bool)
+	for _, albumID := range albumIDs {
+		if err := (&AlbumController{DB: tx}).CalculateAverageRating(albumID); err != nil {
+			return err
+		}
+	}
+	for _, trackID := range trackIDs {
+		if err := (&TrackController{DB: tx}).CalculateAverageRating(trackID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	for _, review := range reviews {
-		var genres []string
+// exportedReview — рецензия в выгрузке: без вложенных пользователей и чужих лайков.
+type exportedReview struct {
+	ID                   uint                `json:"id"`
+	AlbumID              *uint               `json:"album_id"`
+	TrackID              *uint               `json:"track_id"`
+	Text                 string              `json:"text"`
+	HasSpoilers          bool                `json:"has_spoilers"`
+	ContentWarning       string              `json:"content_warning"`
+	RatingRhymes         int                 `json:"rating_rhymes"`
+	RatingStructure      int                 `json:"rating_structure"`
+	RatingImplementation int                 `json:"rating_implementation"`
+	RatingIndividuality  int                 `json:"rating_individuality"`
+	AtmosphereMultiplier float64             `json:"atmosphere_multiplier"`
+	FinalScore           float64             `json:"final_score"`
+	Status               models.ReviewStatus `json:"status"`
+	LikesCount           int64               `json:"likes_count"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+// exportedLike — лайк в выгрузке: на что поставлен и когда.
+type exportedLike struct {
+	EntityID  uint      `json:"entity_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
 
-		// Get genres from album or track
-		if review.AlbumID != nil && review.Album != nil && review.Album.Genre.ID > 0 {
-			genres = append(genres, review.Album.Genre.Name)
-			uniqueGenres[review.Album.Genre.Name] = true
+// ExportUser returns all data of a user as a downloadable JSON file (owner or admin only).
+func (uc *UserController) ExportUser(c *gin.Context) {
+	id := c.Param("id")
+	var user models.User
+
+	if err := requestDB(c, uc.DB).First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "User not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	userModel, _ := middleware.GetUserFromContext(c)
+	if user.ID != userID && !userModel.IsAdmin {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "You don't have permission to export this user",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	// Рецензии во всех статусах — это данные самого пользователя.
+	var reviews []models.Review
+	if err := requestDB(c, uc.DB).Where("user_id = ?", user.ID).Order("created_at ASC").Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to export reviews",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	likesCounts := make(map[uint]int64, len(reviews))
+	if len(reviews) > 0 {
+		reviewIDs := make([]uint, 0, len(reviews))
+		for _, review := range reviews {
+			reviewIDs = append(reviewIDs, review.ID)
 		}
-		if review.TrackID != nil && review.Track != nil {
-			for _, genre := range review.Track.Genres {
-				if genre.ID > 0 {
-					genres = append(genres, genre.Name)
-					uniqueGenres[genre.Name] = true
-				}
-			}
+		var counts []struct {
+			ReviewID uint
+			N        int64
 		}
-
-		// Count each genre (if review has multiple genres, count each)
-		for _, genreName := range genres {
-			genreCounts[genreName]++
+		if err := requestDB(c, uc.DB).Model(&models.ReviewLike{}).
+			Select("review_id, COUNT(*) AS n").
+			Where("review_id IN ?", reviewIDs).
+			Group("review_id").Scan(&counts).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to export reviews",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		for _, row := range counts {
+			likesCounts[row.ReviewID] = row.N
 		}
 	}
 
+	exportedReviews := make([]exportedReview, 0, len(reviews))
+	for _, review := range reviews {
+		exportedReviews = append(exportedReviews, exportedReview{
+			ID:                   review.ID,
+			AlbumID:              review.AlbumID,
+			TrackID:              review.TrackID,
+			Text:                 review.Text,
+			HasSpoilers:          review.HasSpoilers,
+			ContentWarning:       review.ContentWarning,
+			RatingRhymes:         review.RatingRhymes,
+			RatingStructure:      review.RatingStructure,
+			RatingImplementation: review.RatingImplementation,
+			RatingIndividuality:  review.RatingIndividuality,
+			AtmosphereMultiplier: review.AtmosphereMultiplier,
+			FinalScore:           review.FinalScore,
+			Status:               review.Status,
+			LikesCount:           likesCounts[review.ID],
+			CreatedAt:            review.CreatedAt,
+			UpdatedAt:            review.UpdatedAt,
+		})
+	}
+
+	reviewLikes := []exportedLike{}
+	if err := requestDB(c, uc.DB).Model(&models.ReviewLike{}).Select("review_id AS entity_id, created_at").
+		Where("user_id = ?", user.ID).Order("created_at ASC").Scan(&reviewLikes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to export likes",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	albumLikes := []exportedLike{}
+	if err := requestDB(c, uc.DB).Model(&models.AlbumLike{}).Select("album_id AS entity_id, created_at").
+		Where("user_id = ?", user.ID).Order("created_at ASC").Scan(&albumLikes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to export likes",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	trackLikes := []exportedLike{}
+	if err := requestDB(c, uc.DB).Model(&models.TrackLike{}).Select("track_id AS entity_id, created_at").
+		Where("user_id = ?", user.ID).Order("created_at ASC").Scan(&trackLikes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to export likes",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// Подписки: только публичные ник и ID тех, на кого подписан пользователь.
+	following := []struct {
+		UserID    uint      `json:"user_id"`
+		Username  string    `json:"username"`
+		CreatedAt time.Time `json:"created_at"`
+	}{}
+	if err := requestDB(c, uc.DB).Table("user_follows").
+		Select("users.id AS user_id, users.username, user_follows.created_at").
+		Joins("JOIN users ON users.id = user_follows.following_id AND users.deleted_at IS NULL").
+		Where("user_follows.follower_id = ?", user.ID).
+		Order("user_follows.created_at ASC").
+		Scan(&following).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to export follows",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	bundle := gin.H{
+		"exported_at": time.Now().UTC(),
+		"profile": gin.H{
+			"id":                 user.ID,
+			"username":           user.Username,
+			"email":              user.Email,
+			"avatar_path":        user.AvatarPath,
+			"bio":                user.Bio,
+			"social_links":       utils.ParseSocialLinks(user.SocialLinks),
+			"is_admin":           user.IsAdmin,
+			"is_verified_artist": user.IsVerifiedArtist,
+			"artist_name":        user.ArtistName,
+			"favorite_album_ids": user.FavoriteAlbumIDs,
+			"favorite_artists":   user.FavoriteArtists,
+			"favorite_track_ids": user.FavoriteTrackIDs,
+			"preferences_manual": user.PreferencesManual,
+			"created_at":         user.CreatedAt,
+			"updated_at":         user.UpdatedAt,
+		},
+		"reviews": exportedReviews,
+		"likes": gin.H{
+			"reviews": reviewLikes,
+			"albums":  albumLikes,
+			"tracks":  trackLikes,
+		},
+		"following": following,
+	}
+
+	filename := fmt.Sprintf("user-%d-export-%s.json", user.ID, time.Now().UTC().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		middleware.Logger(c).Error("failed to write user export", "export_user_id", user.ID, "error", err)
+	}
+}
+
+// Badge represents a user badge/achievement
+type Badge struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Criteria    string `json:"criteria"` // как получить звание (для подсказки в UI)
+	Icon        string `json:"icon"`
+	Priority    int    `json:"priority"`
+}
+
+// calculateUserBadges calculates badges from the number of approved reviews and
+// their counts by genre (reviewedGenreCounts): раньше здесь грузились все
+// одобренные рецензии с четырьмя preload'ами на каждый просмотр профиля.
+func calculateUserBadges(totalReviews int, genreCounts []GenreStat) []Badge {
+	if totalReviews == 0 {
+		return []Badge{}
+	}
+
 	var badges []Badge
 
 	// Badges by total count
@@ -916,7 +2002,8 @@ func (uc *UserController) CalculateUserBadges(userID uint) []Badge {
 		"Классическая": "Классический знаток",
 	}
 
-	for genreName, count := range genreCounts {
+	for _, genre := range genreCounts {
+		genreName, count := genre.Name, genre.Count
 		if count >= 5 {
 			icon := genreIcons[genreName]
 			if icon == "" {
@@ -937,10 +2024,10 @@ func (uc *UserController) CalculateUserBadges(userID uint) []Badge {
 	}
 
 	// Badge for diversity (5+ different genres)
-	if len(uniqueGenres) >= 5 {
+	if len(genreCounts) >= 5 {
 		badges = append(badges, Badge{
 			Name:        "Универсал",
-			Description: fmt.Sprintf("Рецензии на %d разных жанров", len(uniqueGenres)),
+			Description: fmt.Sprintf("Рецензии на %d разных жанров", len(genreCounts)),
 			Criteria:    "В одобренных рецензиях встречается не менее 5 разных жанров (по данным альбомов и треков).",
 			Icon:        "🌈",
 			Priority:    3,
@@ -949,7 +2036,8 @@ func (uc *UserController) CalculateUserBadges(userID uint) []Badge {
 
 	// Badge for specialization (80%+ reviews in one genre)
 	if totalReviews > 0 {
-		for genreName, count := range genreCounts {
+		for _, genre := range genreCounts {
+			genreName, count := genre.Name, genre.Count
 			percentage := float64(count) / float64(totalReviews) * 100
 			if percentage >= 80 {
 				icon := genreIcons[genreName]
@@ -985,7 +2073,7 @@ func (uc *UserController) UploadAvatar(c *gin.Context) {
 	id := c.Param("id")
 	var user models.User
 
-	if err := uc.DB.First(&user, id).Error; err != nil {
+	if err := requestDB(c, uc.DB).First(&user, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, utils.ErrorResponse{
 			Error:   "Not Found",
 			Message: "User not found",
@@ -1056,7 +2144,7 @@ func (uc *UserController) UploadAvatar(c *gin.Context) {
 	}
 
 	// Create avatars directory if it doesn't exist
-	avatarsDir := "../frontend/public/avatars"
+	avatarsDir := filepath.Join(uc.UploadsDir, utils.UploadsAvatarsDir)
 	if err := os.MkdirAll(avatarsDir, 0755); err != nil {
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -1070,14 +2158,7 @@ func (uc *UserController) UploadAvatar(c *gin.Context) {
 	timestamp := time.Now().Unix()
 	filename := fmt.Sprintf("user_%d_%d%s", user.ID, timestamp, ext)
 	filePath := filepath.Join(avatarsDir, filename)
-
-	// Delete old avatar if exists
-	if user.AvatarPath != "" && strings.HasPrefix(user.AvatarPath, "/avatars/") {
-		oldFilePath := filepath.Join(avatarsDir, filepath.Base(user.AvatarPath))
-		if _, err := os.Stat(oldFilePath); err == nil {
-			os.Remove(oldFilePath)
-		}
-	}
+	oldAvatarPath := user.AvatarPath
 
 	// Save file
 	if err := c.SaveUploadedFile(file, filePath); err != nil {
@@ -1090,8 +2171,8 @@ func (uc *UserController) UploadAvatar(c *gin.Context) {
 	}
 
 	// Update user avatar path
-	user.AvatarPath = "/avatars/" + filename
-	if err := uc.DB.Save(&user).Error; err != nil {
+	user.AvatarPath = utils.UploadPublicPath(utils.UploadsAvatarsDir, filename)
+	if err := requestDB(c, uc.DB).Save(&user).Error; err != nil {
 		// Try to delete uploaded file if DB update fails
 		os.Remove(filePath)
 		c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
@@ -1102,6 +2183,75 @@ func (uc *UserController) UploadAvatar(c *gin.Context) {
 		return
 	}
 
+	// Старый файл удаляем только после успешного сохранения и только если путь
+	// из БД указывает на прежний загруженный аватар этого же пользователя.
+	if oldPath, ok := utils.ResolveUserAvatarPath(uc.UploadsDir, oldAvatarPath, user.ID); ok && oldPath != filePath {
+		if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+			middleware.Logger(c).Warn("failed to remove old avatar", "path", oldPath, "error", err)
+		}
+	}
+
+	user.Password = ""
+	user.ShowEmail = true
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteAvatar clears the user's avatar (owner or admin) so the default one is shown.
+// Файл удаляется с диска, только если путь ведёт в каталог аватаров внутри UPLOADS_DIR.
+func (uc *UserController) DeleteAvatar(c *gin.Context) {
+	id := c.Param("id")
+	var user models.User
+
+	if err := requestDB(c, uc.DB).First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, utils.ErrorResponse{
+			Error:   "Not Found",
+			Message: "User not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	userModel, _ := middleware.GetUserFromContext(c)
+	if user.ID != userID && !userModel.IsAdmin {
+		c.JSON(http.StatusForbidden, utils.ErrorResponse{
+			Error:   "Forbidden",
+			Message: "You don't have permission to update this user",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	oldAvatarPath := user.AvatarPath
+	if oldAvatarPath != "" {
+		if err := requestDB(c, uc.DB).Model(&user).Update("avatar_path", "").Error; err != nil {
+			c.JSON(http.StatusInternalServerError, utils.ErrorResponse{
+				Error:   "Internal Server Error",
+				Message: "Failed to clear user avatar",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		user.AvatarPath = ""
+
+		// Как и в UploadAvatar: файл удаляем после успешного обновления БД.
+		if oldPath, ok := utils.ResolveUserAvatarPath(uc.UploadsDir, oldAvatarPath, user.ID); ok {
+			if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+				middleware.Logger(c).Warn("failed to remove avatar", "path", oldPath, "error", err)
+			}
+		}
+	}
+
 	user.Password = ""
+	user.ShowEmail = true
 	c.JSON(http.StatusOK, user)
 }
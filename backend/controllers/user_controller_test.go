@@ -0,0 +1,427 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"music-review-site/backend/models"
+	"music-review-site/backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCalculateUserBadges(t *testing.T) {
+	if badges := calculateUserBadges(0, nil); badges == nil || len(badges) != 0 {
+		t.Errorf("no reviews: want empty list, got %#v", badges)
+	}
+
+	badges := calculateUserBadges(25, []GenreStat{{Name: "Рок", Count: 21}, {Name: "Джаз", Count: 4}})
+	var names []string
+	for _, badge := range badges {
+		names = append(names, badge.Name)
+	}
+	want := []string{"Рок-ценитель (Специалист)", "Мастер рецензий", "Рок-ценитель"}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("badges = %v, want %v", names, want)
+	}
+}
+
+// Рецензия на удалённый трек снимается вместе с ним и больше не даёт звания.
+func TestDeleteTrackDropsAuthorBadges(t *testing.T) {
+	db := testDB(t)
+	uc := &UserController{DB: db, Ranks: NewProfileRanks(time.Hour)}
+	tc := &TrackController{DB: db, Scoring: models.DefaultScoring()}
+	admin := seedUser(t, db, "badge-admin", true)
+	author := seedUser(t, db, "badge-author", false)
+	track := seedTrack(t, db, seedAlbum(t, db, "badge-album", models.AlbumStatusApproved).ID, "Badge", 1)
+	trackID := track.ID
+	mustCreate(t, db, &models.Review{
+		UserID: author.ID, TrackID: &trackID,
+		RatingRhymes: 5, RatingStructure: 5, RatingImplementation: 5, RatingIndividuality: 5,
+		AtmosphereMultiplier: 1, FinalScore: 40, Status: models.ReviewStatusApproved,
+	})
+	if badges := uc.profileSummary(author.ID).Badges; len(badges) != 1 {
+		t.Fatalf("before delete: badges %+v, want one", badges)
+	}
+
+	target := fmt.Sprintf("/tracks/%d", track.ID)
+	if w := serve(tc.DeleteTrack, http.MethodDelete, "/tracks/:id", target, "", &admin); w.Code != http.StatusOK {
+		t.Fatalf("DELETE %s: %d %s", target, w.Code, w.Body.String())
+	}
+	if badges := uc.profileSummary(author.ID).Badges; len(badges) != 0 {
+		t.Errorf("after delete: badges %+v, want none", badges)
+	}
+}
+
+// TestGetUserQueriesDoNotGrowWithReviews: профиль считается агрегатами, поэтому
+// число запросов не зависит от того, сколько у автора рецензий, а очки остальных
+// пользователей для ранга берутся из ProfileRanks.
+func TestGetUserQueriesDoNotGrowWithReviews(t *testing.T) {
+	db := testDB(t)
+	uc := &UserController{DB: db, Ranks: NewProfileRanks(time.Hour)}
+	user := seedUser(t, db, "profile-queries", false)
+	album := seedAlbum(t, db, "profile-queries-0", models.AlbumStatusApproved)
+	seedAlbumReview(t, db, user.ID, album.ID, 50)
+
+	target := fmt.Sprintf("/users/%d", user.ID)
+	view := func() (int, map[string]interface{}) {
+		var resp map[string]interface{}
+		queries := countQueries(t, db, func() {
+			w := serve(uc.GetUser, http.MethodGet, "/users/:id", target, "", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("get user: %d %s", w.Code, w.Body.String())
+			}
+			decode(t, w, &resp)
+		})
+		return queries, resp
+	}
+
+	cold, _ := view()
+	warm, _ := view()
+	if cold <= warm {
+		t.Errorf("ranks cache not used: %d queries on first view, %d on second", cold, warm)
+	}
+
+	for i := 1; i <= 24; i++ {
+		album := seedAlbum(t, db, fmt.Sprintf("profile-queries-%d", i), models.AlbumStatusApproved)
+		seedAlbumReview(t, db, user.ID, album.ID, 50)
+	}
+	many, resp := view()
+	if many != warm {
+		t.Errorf("queries grow with reviews: %d with 1 review, %d with 25", warm, many)
+	}
+	stats := resp["stats"].(map[string]interface{})
+	if stats["total_reviews"] != float64(25) || stats["avg_score"] != float64(50) {
+		t.Errorf("stats = %v", stats)
+	}
+	badges := resp["badges"].([]interface{})
+	if len(badges) == 0 || badges[0].(map[string]interface{})["name"] != "Мастер рецензий" {
+		t.Errorf("badges = %v", badges)
+	}
+}
+
+func TestRecomputeProfileRanks(t *testing.T) {
+	db := testDB(t)
+	uc := &UserController{DB: db, Ranks: NewProfileRanks(time.Hour)}
+	author := seedUser(t, db, "ranks-author", false)
+	album := seedAlbum(t, db, "ranks-0", models.AlbumStatusApproved)
+	seedAlbumReview(t, db, author.ID, album.ID, 50)
+
+	rank := func() float64 {
+		w := serve(uc.GetUser, http.MethodGet, "/users/:id", fmt.Sprintf("/users/%d", author.ID), "", nil)
+		var resp struct {
+			ProfileRank float64 `json:"profile_rank"`
+		}
+		decode(t, w, &resp)
+		return resp.ProfileRank
+	}
+	before := rank()
+
+	// Новый лидер появляется в рангах только после пересчёта кэша.
+	leader := seedUser(t, db, "ranks-leader", false)
+	for i := 1; i <= 3; i++ {
+		album := seedAlbum(t, db, fmt.Sprintf("ranks-%d", i), models.AlbumStatusApproved)
+		seedAlbumReview(t, db, leader.ID, album.ID, 50)
+	}
+	if cached := rank(); cached != before {
+		t.Fatalf("rank changed before recompute: %v -> %v", before, cached)
+	}
+
+	admin := seedUser(t, db, "ranks-admin", true)
+	w := serve(uc.RecomputeProfileRanks, http.MethodPost, "/admin/users/recompute-ranks", "/admin/users/recompute-ranks", "", &admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("recompute: %d %s", w.Code, w.Body.String())
+	}
+	if after := rank(); after != before+1 {
+		t.Errorf("rank after recompute = %v, want %v", after, before+1)
+	}
+}
+
+// avatar_path из PUT /users/:id не принимается, а удаление аватара стирает с
+// диска только собственные загрузки пользователя, но не обложку, на которую
+// указывает старое значение avatar_path.
+func TestAvatarRemovalLimitedToOwnUploads(t *testing.T) {
+	db := testDB(t)
+	uploads := t.TempDir()
+	uc := &UserController{DB: db, UploadsDir: uploads}
+	user := seedUser(t, db, "avatar-owner", false)
+
+	cover := filepath.Join(uploads, utils.UploadsCoversDir, "album_1.jpg")
+	own := filepath.Join(uploads, utils.UploadsAvatarsDir, fmt.Sprintf("user_%d_1.png", user.ID))
+	for _, path := range []string{cover, own} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("img"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coverPath := utils.UploadPublicPath(utils.UploadsCoversDir, "album_1.jpg")
+
+	target := fmt.Sprintf("/users/%d", user.ID)
+	body := fmt.Sprintf(`{"bio": "hi", "avatar_path": %q}`, coverPath)
+	if w := serve(uc.UpdateUser, http.MethodPut, "/users/:id", target, body, &user); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	var stored models.User
+	if err := db.First(&stored, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.AvatarPath != "" {
+		t.Errorf("avatar_path taken from request: %q", stored.AvatarPath)
+	}
+
+	// Старые данные могли успеть сослаться на чужой файл.
+	if err := db.Model(&stored).Update("avatar_path", coverPath).Error; err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(uc.DeleteAvatar, http.MethodDelete, "/users/:id/avatar", target+"/avatar", "", &user); w.Code != http.StatusOK {
+		t.Fatalf("delete avatar: %d %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(cover); err != nil {
+		t.Errorf("cover removed through avatar_path: %v", err)
+	}
+
+	ownPath := utils.UploadPublicPath(utils.UploadsAvatarsDir, filepath.Base(own))
+	if err := db.Model(&stored).Update("avatar_path", ownPath).Error; err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(uc.DeleteAvatar, http.MethodDelete, "/users/:id/avatar", target+"/avatar", "", &user); w.Code != http.StatusOK {
+		t.Fatalf("delete own avatar: %d %s", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(own); !os.IsNotExist(err) {
+		t.Errorf("own avatar should be removed, stat: %v", err)
+	}
+}
+
+// Поиск и фильтр is_admin в админском списке применяются вместе, а пароль в
+// ответ не попадает.
+func TestAdminListUsersSearchWithAdminFilter(t *testing.T) {
+	db := testDB(t)
+	uc := &UserController{DB: db}
+	admin := seedUser(t, db, "userlist-admin", true)
+	seedUser(t, db, "userlist-member", false)
+	seedUser(t, db, "other-admin", true)
+
+	w := serve(uc.AdminListUsers, http.MethodGet, "/admin/users", "/admin/users?search=USERLIST&is_admin=true", "", &admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list users: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "password") {
+		t.Errorf("password leaked: %s", w.Body.String())
+	}
+	var body struct {
+		Users []AdminUserRow `json:"users"`
+		Total int64          `json:"total"`
+	}
+	decode(t, w, &body)
+	if body.Total != 1 || len(body.Users) != 1 || body.Users[0].ID != admin.ID {
+		t.Errorf("want only %q, got %+v (total %d)", admin.Username, body.Users, body.Total)
+	}
+
+	if code := serve(uc.AdminListUsers, http.MethodGet, "/admin/users", "/admin/users?is_admin=maybe", "", &admin).Code; code != http.StatusBadRequest {
+		t.Errorf("is_admin=maybe: want 400, got %d", code)
+	}
+}
+
+// Email и лайки с учётом email_public / likes_private: владелец и админ видят
+// всё, остальные — только то, что пользователь открыл сам.
+func TestUserPrivacyVisibility(t *testing.T) {
+	db := testDB(t)
+	uc := &UserController{DB: db, Ranks: NewProfileRanks(time.Hour)}
+	hidden := seedUser(t, db, "privacy-hidden", false)
+	db.Model(&hidden).Update("likes_private", true)
+	open := seedUser(t, db, "privacy-open", false)
+	db.Model(&open).Update("email_public", true)
+	other := seedUser(t, db, "privacy-other", false)
+	admin := seedUser(t, db, "privacy-admin", true)
+	likesHandlers := map[string]gin.HandlerFunc{
+		"/users/:id/likes/tracks":  uc.GetUserLikedTracks,
+		"/users/:id/likes/albums":  uc.GetUserLikedAlbums,
+		"/users/:id/liked-reviews": uc.GetUserLikedReviews,
+	}
+
+	cases := []struct {
+		name        string
+		target      models.User
+		viewer      *models.User
+		email, like bool
+	}{
+		{"owner of private profile", hidden, &hidden, true, true},
+		{"admin on private profile", hidden, &admin, true, true},
+		{"other user on private profile", hidden, &other, false, false},
+		{"guest on private profile", hidden, nil, false, false},
+		{"other user on public profile", open, &other, true, true},
+		{"guest on public profile", open, nil, true, true},
+	}
+	for _, tc := range cases {
+		profile := fmt.Sprintf("/users/%d", tc.target.ID)
+		w := serve(uc.GetUser, http.MethodGet, "/users/:id", profile, "", tc.viewer)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: GET %s: %d %s", tc.name, profile, w.Code, w.Body.String())
+		}
+		var body map[string]interface{}
+		decode(t, w, &body)
+		if email, ok := body["email"]; ok != tc.email || (ok && email != tc.target.Email) {
+			t.Errorf("%s: email %v, want visible=%v", tc.name, body["email"], tc.email)
+		}
+
+		wantCode := http.StatusForbidden
+		if tc.like {
+			wantCode = http.StatusOK
+		}
+		for pattern, handler := range likesHandlers {
+			target := strings.Replace(pattern, ":id", fmt.Sprint(tc.target.ID), 1)
+			if w := serve(handler, http.MethodGet, pattern, target, "", tc.viewer); w.Code != wantCode {
+				t.Errorf("%s: GET %s: want %d, got %d", tc.name, target, wantCode, w.Code)
+			}
+		}
+	}
+
+	// Админский список показывает email всех пользователей.
+	w := serve(uc.AdminListUsers, http.MethodGet, "/admin/users", "/admin/users?search=privacy-hidden", "", &admin)
+	var list struct {
+		Users []AdminUserRow `json:"users"`
+	}
+	decode(t, w, &list)
+	if len(list.Users) != 1 || list.Users[0].Email != hidden.Email {
+		t.Errorf("admin list: %+v, want %s with email", list.Users, hidden.Email)
+	}
+}
+
+// Выгрузка содержит все рецензии пользователя (в любом статусе) и ни одной
+// чужой, а хэш пароля в неё не попадает. Чужую выгрузку получить нельзя.
+func TestExportUser(t *testing.T) {
+	db := testDB(t)
+	uc := &UserController{DB: db}
+	const passwordHash = "$2a$10$export-test-password-hash"
+	owner := models.User{Username: "export-owner", Email: "export-owner@example.test", Password: passwordHash}
+	mustCreate(t, db, &owner)
+	other := seedUser(t, db, "export-other", false)
+	approved := seedAlbumReview(t, db, owner.ID, seedAlbum(t, db, "export-1", models.AlbumStatusApproved).ID, 40)
+	pending := seedAlbumReview(t, db, owner.ID, seedAlbum(t, db, "export-2", models.AlbumStatusApproved).ID, 30)
+	db.Model(&pending).Updates(map[string]interface{}{"status": models.ReviewStatusPending, "text": "на модерации"})
+	foreign := seedAlbumReview(t, db, other.ID, seedAlbum(t, db, "export-3", models.AlbumStatusApproved).ID, 50)
+
+	target := fmt.Sprintf("/users/%d/export", owner.ID)
+	w := serve(uc.ExportUser, http.MethodGet, "/users/:id/export", target, "", &owner)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: %d %s", target, w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment;") {
+		t.Errorf("Content-Disposition = %q", w.Header().Get("Content-Disposition"))
+	}
+	if strings.Contains(w.Body.String(), passwordHash) || strings.Contains(w.Body.String(), `"password"`) {
+		t.Errorf("password hash leaked: %s", w.Body.String())
+	}
+	var bundle struct {
+		Profile struct {
+			Username string `json:"username"`
+		} `json:"profile"`
+		Reviews []exportedReview `json:"reviews"`
+	}
+	decode(t, w, &bundle)
+	if bundle.Profile.Username != owner.Username {
+		t.Errorf("profile username %q", bundle.Profile.Username)
+	}
+	got := map[uint]bool{}
+	for _, review := range bundle.Reviews {
+		got[review.ID] = true
+	}
+	if len(got) != 2 || !got[approved.ID] || !got[pending.ID] || got[foreign.ID] {
+		t.Errorf("exported reviews %v, want %d and %d", got, approved.ID, pending.ID)
+	}
+
+	if code := serve(uc.ExportUser, http.MethodGet, "/users/:id/export", target, "", &other).Code; code != http.StatusForbidden {
+		t.Errorf("export by another user: want 403, got %d", code)
+	}
+}
+
+// recordingMailer запоминает отправленные письма.
+type recordingMailer struct {
+	sent []string
+}
+
+func (m *recordingMailer) Send(to, subject, body string) error {
+	m.sent = append(m.sent, to+"\n"+body)
+	return nil
+}
+
+// Новый email приходит письмом со ссылкой и меняется только по ней; без почты
+// смена email отклоняется, а не оставляет неподтверждаемый pending_email.
+func TestEmailChangeConfirmedByMailedToken(t *testing.T) {
+	db := testDB(t)
+	user := seedUser(t, db, "email-change", false)
+	target := fmt.Sprintf("/users/%d", user.ID)
+	body := `{"email": "New-Address@Example.test"}`
+
+	disabled := &UserController{DB: db}
+	if w := serve(disabled.UpdateUser, http.MethodPut, "/users/:id", target, body, &user); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("without mailer: want 503, got %d %s", w.Code, w.Body.String())
+	}
+
+	mailer := &recordingMailer{}
+	uc := &UserController{DB: db, Mailer: mailer, PublicSiteURL: "https://example.test"}
+	w := serve(uc.UpdateUser, http.MethodPut, "/users/:id", target, body, &user)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "email_confirm_token") {
+		t.Errorf("token exposed without EXPOSE_EMAIL_CONFIRM_TOKEN: %s", w.Body.String())
+	}
+	if len(mailer.sent) != 1 || !strings.HasPrefix(mailer.sent[0], "new-address@example.test\n") {
+		t.Fatalf("sent = %q", mailer.sent)
+	}
+	_, token, found := strings.Cut(mailer.sent[0], "confirm-email?token=")
+	if !found {
+		t.Fatalf("no confirmation link in %q", mailer.sent[0])
+	}
+	token = strings.Fields(token)[0]
+
+	w = serve(uc.ConfirmEmail, http.MethodGet, "/users/confirm-email", "/users/confirm-email?token="+token, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("confirm: %d %s", w.Code, w.Body.String())
+	}
+	var stored models.User
+	db.First(&stored, user.ID)
+	if stored.Email != "new-address@example.test" || stored.PendingEmail != "" {
+		t.Errorf("after confirm: email %q, pending %q", stored.Email, stored.PendingEmail)
+	}
+}
+
+// Лайк удалённой рецензии не попадает ни в список, ни в total.
+func TestGetUserLikedReviewsSkipsDeleted(t *testing.T) {
+	db := testDB(t)
+	uc := &UserController{DB: db}
+	liker := seedUser(t, db, "liked-reviews", false)
+	author := seedUser(t, db, "liked-reviews-author", false)
+	kept := seedAlbumReview(t, db, author.ID, seedAlbum(t, db, "liked-kept", models.AlbumStatusApproved).ID, 40)
+	deleted := seedAlbumReview(t, db, author.ID, seedAlbum(t, db, "liked-deleted", models.AlbumStatusApproved).ID, 40)
+	mustCreate(t, db, &models.ReviewLike{UserID: liker.ID, ReviewID: kept.ID})
+	mustCreate(t, db, &models.ReviewLike{UserID: liker.ID, ReviewID: deleted.ID})
+	db.Delete(&deleted)
+
+	target := fmt.Sprintf("/users/%d/liked-reviews", liker.ID)
+	w := serve(uc.GetUserLikedReviews, http.MethodGet, "/users/:id/liked-reviews", target+"?page_size=1", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("liked reviews: %d %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Reviews    []models.Review `json:"reviews"`
+		Total      int64           `json:"total"`
+		TotalPages int             `json:"total_pages"`
+		HasNext    bool            `json:"has_next"`
+	}
+	decode(t, w, &body)
+	if len(body.Reviews) != 1 || body.Reviews[0].ID != kept.ID {
+		t.Errorf("reviews = %+v, want only %d", body.Reviews, kept.ID)
+	}
+	if body.Total != 1 || body.TotalPages != 1 || body.HasNext {
+		t.Errorf("envelope: total %d, total_pages %d, has_next %v", body.Total, body.TotalPages, body.HasNext)
+	}
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"fmt"
+	"music-review-site/backend/config"
+	"music-review-site/backend/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	cases := map[string]string{
+		"music_review_db":         `"music_review_db"`,
+		"music-review":            `"music-review"`,
+		`bad"name`:                `"bad""name"`,
+		`x"; DROP DATABASE x; --`: `"x""; DROP DATABASE x; --"`,
+		`""`:                      `""""""`,
+	}
+	for name, want := range cases {
+		if got := quoteIdentifier(name); got != want {
+			t.Errorf("quoteIdentifier(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+// seededDB — пустая схема из migrationDB с накатанными миграциями.
+func seededDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := migrationDB(t)
+	if _, err := ApplyMigrations(db, 0); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// tableCounts считает строки во всех таблицах схемы, кроме schema_migrations.
+func tableCounts(t *testing.T, db *gorm.DB) map[string]int64 {
+	t.Helper()
+	var tables []string
+	if err := db.Raw(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' AND table_name <> 'schema_migrations'`).
+		Scan(&tables).Error; err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var n int64
+		if err := db.Table(table).Count(&n).Error; err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		counts[table] = n
+	}
+	return counts
+}
+
+// Повторный запуск сидера сходится: ни в одной таблице не появляется лишних строк.
+func TestRunSeedsTwiceConverges(t *testing.T) {
+	db := seededDB(t)
+	seed := config.SeedConfig{LikesMin: 1, LikesMax: 3, ReviewLikesMin: 1, ReviewLikesMax: 2, RecentShare: 0.3}
+
+	RunSeeds(db, models.DefaultScoring(), seed)
+	first := tableCounts(t, db)
+	for _, table := range []string{"users", "genres", "albums", "tracks", "track_genres", "reviews", "review_likes", "track_likes", "album_likes"} {
+		if first[table] == 0 {
+			t.Errorf("%s is empty after seeding", table)
+		}
+	}
+
+	RunSeeds(db, models.DefaultScoring(), seed)
+	second := tableCounts(t, db)
+	for table, n := range first {
+		if second[table] != n {
+			t.Errorf("%s: %d rows after the first run, %d after the second", table, n, second[table])
+		}
+	}
+}
+
+// Без SEED_DEMO_DATA InitDB накатывает миграции, но не создаёт демо-данных.
+func TestPrepareDBWithoutSeeding(t *testing.T) {
+	db := migrationDB(t)
+	cfg := &config.Config{DB: config.DBConfig{MigrationsMode: "versioned"}, Scoring: models.DefaultScoring()}
+	if err := prepareDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	list, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := CurrentMigrationVersion(db); err != nil || version != list[len(list)-1].Version {
+		t.Errorf("schema version %04d (%v), want %04d", version, err, list[len(list)-1].Version)
+	}
+	var genres int64
+	if err := db.Model(&models.Genre{}).Count(&genres).Error; err != nil {
+		t.Fatal(err)
+	}
+	if genres != 0 {
+		t.Errorf("%d genres created with seeding disabled", genres)
+	}
+}
+
+// likeTimestamps — created_at всех лайков по таблице и ID.
+func likeTimestamps(t *testing.T, db *gorm.DB) map[string]time.Time {
+	t.Helper()
+	stamps := map[string]time.Time{}
+	for _, table := range []string{"track_likes", "album_likes", "review_likes"} {
+		var rows []struct {
+			ID        uint
+			CreatedAt time.Time
+		}
+		if err := db.Table(table).Select("id, created_at").Scan(&rows).Error; err != nil {
+			t.Fatalf("%s: %v", table, err)
+		}
+		for _, row := range rows {
+			stamps[fmt.Sprintf("%s/%d", table, row.ID)] = row.CreatedAt
+		}
+	}
+	return stamps
+}
+
+// Повторный InitDB без FORCE_RESEED не трогает created_at уже засеянных лайков.
+func TestPrepareDBKeepsLikeTimestamps(t *testing.T) {
+	db := migrationDB(t)
+	cfg := &config.Config{
+		DB:           config.DBConfig{MigrationsMode: "versioned"},
+		SeedDemoData: true,
+		Scoring:      models.DefaultScoring(),
+		Seed:         config.SeedConfig{LikesMin: 1, LikesMax: 3, ReviewLikesMin: 1, ReviewLikesMax: 2, RecentShare: 0.3},
+	}
+	if err := prepareDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	first := likeTimestamps(t, db)
+	if len(first) == 0 {
+		t.Fatal("no likes seeded")
+	}
+
+	if err := prepareDB(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	second := likeTimestamps(t, db)
+	for key, stamp := range first {
+		if got, ok := second[key]; !ok || !got.Equal(stamp) {
+			t.Errorf("%s: created_at %v, was %v", key, got, stamp)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"music-review-site/backend/fixtures"
+	"music-review-site/backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Записи фикстур из backend/fixtures. Друг на друга они ссылаются по
+// естественным ключам (название жанра, альбом и артист, username автора):
+// ID назначает БД, и в разных окружениях они не совпадают.
+
+type genreFixture struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type albumFixture struct {
+	Title          string `json:"title"`
+	Artist         string `json:"artist"`
+	Genre          string `json:"genre"`
+	CoverImagePath string `json:"cover_image_path"`
+	Description    string `json:"description"`
+	ReleaseDate    string `json:"release_date"` // YYYY-MM-DD
+}
+
+type trackFixture struct {
+	Album          string   `json:"album"` // название альбома из albums.json
+	Title          string   `json:"title"`
+	Duration       int      `json:"duration"` // секунды
+	TrackNumber    int      `json:"track_number"`
+	Genres         []string `json:"genres"`
+	CoverImagePath string   `json:"cover_image_path,omitempty"`
+}
+
+// releaseFixture — альбом из catalog_expansion.json вместе со списком треков.
+type releaseFixture struct {
+	Title          string   `json:"title"`
+	Artist         string   `json:"artist"`
+	Genre          string   `json:"genre"`
+	CoverImagePath string   `json:"cover_image_path"`
+	Description    string   `json:"description"`
+	Year           int      `json:"year"`
+	Tracks         []string `json:"tracks"`
+}
+
+// reviewFixture — рецензия на альбом (Album + Artist) или на его трек (ещё и Track).
+type reviewFixture struct {
+	Author  string              `json:"author"` // username
+	Album   string              `json:"album"`
+	Artist  string              `json:"artist"`
+	Track   string              `json:"track,omitempty"`
+	Status  models.ReviewStatus `json:"status"`
+	Text    string              `json:"text"`
+	Ratings struct {
+		Rhymes         int `json:"rhymes"`
+		Structure      int `json:"structure"`
+		Implementation int `json:"implementation"`
+		Individuality  int `json:"individuality"`
+		Atmosphere     int `json:"atmosphere"`
+	} `json:"ratings"`
+}
+
+// loadFixture parses backend/fixtures/<name> into dest. Неизвестные поля —
+// ошибка: опечатка в ключе JSON иначе молча дала бы пустое значение.
+func loadFixture(name string, dest interface{}) error {
+	data, err := fs.ReadFile(fixtures.Files, name)
+	if err != nil {
+		return fmt.Errorf("read fixture %s: %w", name, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		return fmt.Errorf("parse fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+func (f albumFixture) model(genreID uint) (models.Album, error) {
+	releaseDate, err := time.Parse("2006-01-02", f.ReleaseDate)
+	if err != nil {
+		return models.Album{}, fmt.Errorf("album %s: release_date: %w", f.Title, err)
+	}
+	return models.Album{
+		Title:          f.Title,
+		Artist:         f.Artist,
+		GenreID:        genreID,
+		CoverImagePath: f.CoverImagePath,
+		Description:    f.Description,
+		ReleaseDate:    &releaseDate,
+	}, nil
+}
+
+// reviewFromFixture находит автора и цель рецензии и собирает модель с
+// пересчитанной по scoring итоговой оценкой. Одобренные рецензии помечаются модератором.
+func reviewFromFixture(db *gorm.DB, f reviewFixture, moderatorID uint, scoring models.ScoringConfig) (models.Review, error) {
+	r := f.Ratings
+	for _, rating := range []int{r.Rhymes, r.Structure, r.Implementation, r.Individuality, r.Atmosphere} {
+		if rating < 1 || rating > 10 {
+			return models.Review{}, fmt.Errorf("rating %d out of range 1-10", rating)
+		}
+	}
+	if f.Status != models.ReviewStatusApproved && f.Status != models.ReviewStatusPending {
+		return models.Review{}, fmt.Errorf("unsupported status %q", f.Status)
+	}
+
+	var author models.User
+	if err := db.Where("username = ?", f.Author).First(&author).Error; err != nil {
+		return models.Review{}, fmt.Errorf("author %s: %w", f.Author, err)
+	}
+	var album models.Album
+	if err := db.Where("title = ? AND artist = ?", f.Album, f.Artist).First(&album).Error; err != nil {
+		return models.Review{}, fmt.Errorf("album %s by %s: %w", f.Album, f.Artist, err)
+	}
+
+	review := models.Review{
+		UserID:               author.ID,
+		Text:                 f.Text,
+		RatingRhymes:         r.Rhymes,
+		RatingStructure:      r.Structure,
+		RatingImplementation: r.Implementation,
+		RatingIndividuality:  r.Individuality,
+		AtmosphereRating:     r.Atmosphere,
+		AtmosphereMultiplier: scoring.AtmosphereMultiplier(r.Atmosphere),
+		Status:               f.Status,
+	}
+	if f.Track != "" {
+		var track models.Track
+		if err := db.Where("album_id = ? AND title = ?", album.ID, f.Track).First(&track).Error; err != nil {
+			return models.Review{}, fmt.Errorf("track %s on %s: %w", f.Track, f.Album, err)
+		}
+		review.TrackID = &track.ID
+	} else {
+		review.AlbumID = &album.ID
+	}
+	if f.Status == models.ReviewStatusApproved {
+		moderatedAt := time.Now().Add(-2 * time.Hour)
+		review.ModeratedBy = &moderatorID
+		review.ModeratedAt = &moderatedAt
+	}
+	review.CalculateFinalScore(scoring)
+	return review, nil
+}
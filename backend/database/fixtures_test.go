@@ -0,0 +1,87 @@
+package database
+
+import (
+	"testing"
+
+	"music-review-site/backend/models"
+)
+
+// TestFixturesParse разбирает все фикстуры так же, как сидер, и проверяет
+// ссылки между ними: опечатка в названии альбома или жанра иначе всплывёт
+// только строчкой в логе сидера.
+func TestFixturesParse(t *testing.T) {
+	var genres []genreFixture
+	var albums []albumFixture
+	var tracks []trackFixture
+	var releases []releaseFixture
+	var reviews []reviewFixture
+	for name, dest := range map[string]interface{}{
+		"genres.json":            &genres,
+		"albums.json":            &albums,
+		"tracks.json":            &tracks,
+		"catalog_expansion.json": &releases,
+		"reviews.json":           &reviews,
+	} {
+		if err := loadFixture(name, dest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(genres) == 0 || len(albums) == 0 || len(tracks) == 0 || len(releases) == 0 || len(reviews) == 0 {
+		t.Fatalf("empty fixture: genres=%d albums=%d tracks=%d releases=%d reviews=%d",
+			len(genres), len(albums), len(tracks), len(releases), len(reviews))
+	}
+
+	genreNames := map[string]bool{}
+	for _, g := range genres {
+		genreNames[g.Name] = true
+	}
+	albumTitles := map[string]bool{}
+	albumKeys := map[[2]string]bool{}
+	trackKeys := map[[2]string]bool{} // название альбома и трека
+	for _, a := range albums {
+		if !genreNames[a.Genre] {
+			t.Errorf("album %s: unknown genre %q", a.Title, a.Genre)
+		}
+		if _, err := a.model(1); err != nil {
+			t.Error(err)
+		}
+		albumTitles[a.Title] = true
+		albumKeys[[2]string{a.Title, a.Artist}] = true
+	}
+	for _, r := range releases {
+		if !genreNames[r.Genre] {
+			t.Errorf("release %s: unknown genre %q", r.Title, r.Genre)
+		}
+		albumKeys[[2]string{r.Title, r.Artist}] = true
+		for _, title := range r.Tracks {
+			trackKeys[[2]string{r.Title, title}] = true
+		}
+	}
+	for _, tr := range tracks {
+		if !albumTitles[tr.Album] {
+			t.Errorf("track %s: unknown album %q", tr.Title, tr.Album)
+		}
+		trackKeys[[2]string{tr.Album, tr.Title}] = true
+		for _, g := range tr.Genres {
+			if !genreNames[g] {
+				t.Errorf("track %s: unknown genre %q", tr.Title, g)
+			}
+		}
+	}
+	for _, r := range reviews {
+		if !albumKeys[[2]string{r.Album, r.Artist}] {
+			t.Errorf("review by %s: unknown album %q by %q", r.Author, r.Album, r.Artist)
+		}
+		if r.Track != "" && !trackKeys[[2]string{r.Album, r.Track}] {
+			t.Errorf("review by %s: unknown track %q on %q", r.Author, r.Track, r.Album)
+		}
+		if r.Status != models.ReviewStatusApproved && r.Status != models.ReviewStatusPending {
+			t.Errorf("review by %s on %s: unsupported status %q", r.Author, r.Album, r.Status)
+		}
+		for _, rating := range []int{r.Ratings.Rhymes, r.Ratings.Structure, r.Ratings.Implementation, r.Ratings.Individuality, r.Ratings.Atmosphere} {
+			if rating < 1 || rating > 10 {
+				t.Errorf("review by %s on %s: rating %d out of range 1-10", r.Author, r.Album, rating)
+			}
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"music-review-site/backend/migrations"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// migrationFileRegex разбирает имя файла миграции: 0007_user_likes_private.up.sql.
+var migrationFileRegex = regexp.MustCompile(`^(\d{4})_(\w+)\.(up|down)\.sql$`)
+
+// migration is one numbered step from backend/migrations.
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// schemaMigration is a row of schema_migrations (applied_at заполняет БД).
+type schemaMigration struct {
+	Version int    `gorm:"primaryKey;autoIncrement:false"`
+	Name    string `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// loadMigrations reads embedded SQL files ordered by version. Каждой версии
+// нужны и up, и down: без down откатить шаг в разработке нельзя.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.ReadDir(migrations.Files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[int]*migration{}
+	for _, entry := range entries {
+		match := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(migrations.Files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	list := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down files", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// appliedVersions returns versions recorded in schema_migrations, creating the
+// table on first run.
+func appliedVersions(db *gorm.DB) (map[int]bool, error) {
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`).Error; err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	var versions []int
+	if err := db.Model(&schemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	applied := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+// legacySchemaVersion — последняя миграция, которую покрывает схема, созданная
+// до перехода на версионные миграции (AutoMigrate из старых релизов). Всё
+// после неё написано идемпотентно и безопасно докатывается поверх такой схемы.
+const legacySchemaVersion = 5
+
+// baselineExistingSchema помечает миграции применёнными, если схема уже есть,
+// а schema_migrations пуста: БД создана AutoMigrate (MIGRATIONS_MODE=auto) или
+// файлами вручную. По умолчанию базой считается legacySchemaVersion, остальные
+// миграции накатываются как обычно; baseline (MIGRATIONS_BASELINE) больше 0
+// задаёт другую версию.
+// Считать актуальной последнюю версию нельзя: тогда новые миграции, которых
+// в старой схеме нет, молча пропустились бы.
+func baselineExistingSchema(db *gorm.DB, list []migration, baseline int) (map[int]bool, error) {
+	if !db.Migrator().HasTable("users") || len(list) == 0 {
+		return map[int]bool{}, nil
+	}
+	if baseline <= 0 {
+		baseline = legacySchemaVersion
+	}
+	applied := map[int]bool{}
+	for _, m := range list {
+		if m.Version > baseline {
+			break
+		}
+		if err := db.Create(&schemaMigration{Version: m.Version, Name: m.Name}).Error; err != nil {
+			return nil, fmt.Errorf("baseline migration %04d: %w", m.Version, err)
+		}
+		applied[m.Version] = true
+	}
+	log.Printf("Warning: existing schema without schema_migrations, baselined at version %04d (MIGRATIONS_BASELINE to override)", baseline)
+	return applied, nil
+}
+
+// ApplyMigrations applies pending up-migrations in order, each in its own
+// transaction, and returns the resulting schema version. baseline — версия для
+// схемы без schema_migrations (см. baselineExistingSchema), 0 — по умолчанию.
+func ApplyMigrations(db *gorm.DB, baseline int) (int, error) {
+	list, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return 0, err
+	}
+	if len(applied) == 0 {
+		if applied, err = baselineExistingSchema(db, list, baseline); err != nil {
+			return 0, err
+		}
+	}
+
+	version := 0
+	for _, m := range list {
+		if applied[m.Version] {
+			version = m.Version
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(m.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name}).Error
+		})
+		if err != nil {
+			return version, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+		version = m.Version
+	}
+	return version, nil
+}
+
+// MigrateDown reverts the latest applied migration and returns the new version.
+// Для разработки: откатывает ровно один шаг.
+func MigrateDown(db *gorm.DB) (int, error) {
+	list, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := appliedVersions(db); err != nil {
+		return 0, err
+	}
+
+	var latest schemaMigration
+	if err := db.Order("version DESC").Limit(1).Find(&latest).Error; err != nil {
+		return 0, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	if latest.Version == 0 {
+		return 0, fmt.Errorf("no applied migrations to revert")
+	}
+
+	var target *migration
+	for i := range list {
+		if list[i].Version == latest.Version {
+			target = &list[i]
+			break
+		}
+	}
+	if target == nil {
+		return latest.Version, fmt.Errorf("migration %04d is applied but its files are missing", latest.Version)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(target.Down).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, target.Version).Error
+	})
+	if err != nil {
+		return latest.Version, fmt.Errorf("revert %04d_%s: %w", target.Version, target.Name, err)
+	}
+	log.Printf("Reverted migration %04d_%s", target.Version, target.Name)
+
+	return CurrentMigrationVersion(db)
+}
+
+// CurrentMigrationVersion returns the latest version in schema_migrations, or 0
+// if the table does not exist yet (БД ещё не мигрировали или режим auto/manual).
+func CurrentMigrationVersion(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return 0, nil
+	}
+	var latest schemaMigration
+	if err := db.Order("version DESC").Limit(1).Find(&latest).Error; err != nil {
+		return 0, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	return latest.Version, nil
+}
@@ -0,0 +1,297 @@
+package database
+
+import (
+	"music-review-site/backend/config"
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// migrationDB открывает БД из TEST_DATABASE_DSN и возвращает транзакцию с
+// пустой схемой в search_path: миграции накатываются с нуля, а всё созданное
+// откатывается после теста. Без DSN тест пропускается.
+func migrationDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	mustExec(t, tx, "CREATE SCHEMA migration_test")
+	mustExec(t, tx, "SET LOCAL search_path TO migration_test")
+	return tx
+}
+
+// migrateTo накатывает up-миграции с версии from до to включительно, без
+// записи в schema_migrations.
+func migrateTo(t *testing.T, db *gorm.DB, from, to int) {
+	t.Helper()
+	list, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range list {
+		if m.Version < from || m.Version > to {
+			continue
+		}
+		if err := db.Exec(m.Up).Error; err != nil {
+			t.Fatalf("migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+}
+
+func mustExec(t *testing.T, db *gorm.DB, sql string, values ...interface{}) {
+	t.Helper()
+	if err := db.Exec(sql, values...).Error; err != nil {
+		t.Fatalf("%s: %v", sql, err)
+	}
+}
+
+// Повторяющиеся номера треков в альбоме перенумеровываются до создания индекса.
+func TestTrackNumberMigrationRenumbersDuplicates(t *testing.T) {
+	db := migrationDB(t)
+	migrateTo(t, db, 1, 11)
+	mustExec(t, db, "INSERT INTO genres (id, name) VALUES (1, 'Rock')")
+	mustExec(t, db, "INSERT INTO albums (id, title, artist, genre_id) VALUES (1, 'A', 'X', 1)")
+	mustExec(t, db, `INSERT INTO tracks (id, album_id, title, track_number) VALUES
+		(1, 1, 'one', 1), (2, 1, 'two', 2), (3, 1, 'one again', 1), (4, 1, 'one more', 1), (5, 1, 'no number', NULL)`)
+
+	migrateTo(t, db, 12, 12)
+
+	want := map[int]*int{1: intPtr(1), 2: intPtr(2), 3: intPtr(3), 4: intPtr(4), 5: nil}
+	for id, number := range want {
+		var got *int
+		if err := db.Raw("SELECT track_number FROM tracks WHERE id = ?", id).Row().Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if (got == nil) != (number == nil) || (got != nil && *got != *number) {
+			t.Errorf("track %d: track_number %v, want %v", id, deref(got), deref(number))
+		}
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func deref(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// Username, отличающиеся регистром, переименовываются; одинаковые без учёта
+// регистра email останавливают миграцию с подсказкой, что исправить.
+func TestUsersCaseInsensitiveMigration(t *testing.T) {
+	db := migrationDB(t)
+	migrateTo(t, db, 1, 10)
+	mustExec(t, db, `INSERT INTO users (id, username, email, password) VALUES
+		(1, 'Alice', 'alice@example.test', 'x'), (2, 'alice', 'alice2@example.test', 'x'), (3, 'Bob', ' Bob@Example.test', 'x')`)
+
+	migrateTo(t, db, 11, 11)
+	var users []struct {
+		ID       int
+		Username string
+		Email    string
+	}
+	if err := db.Raw("SELECT id, username, email FROM users ORDER BY id").Scan(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Alice alice@example.test", "alice_2 alice2@example.test", "Bob bob@example.test"}
+	for i, user := range users {
+		if got := user.Username + " " + user.Email; got != want[i] {
+			t.Errorf("user %d: got %q, want %q", user.ID, got, want[i])
+		}
+	}
+}
+
+func TestUsersCaseInsensitiveMigrationRejectsSharedEmail(t *testing.T) {
+	db := migrationDB(t)
+	migrateTo(t, db, 1, 10)
+	mustExec(t, db, `INSERT INTO users (id, username, email, password) VALUES
+		(1, 'first', 'Same@example.test', 'x'), (2, 'second', 'same@example.test', 'x')`)
+
+	list, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range list {
+		if m.Version != 11 {
+			continue
+		}
+		err := db.Exec(m.Up).Error
+		if err == nil || !strings.Contains(err.Error(), "same@example.test (ids 1, 2)") {
+			t.Errorf("want error naming the shared email and both ids, got %v", err)
+		}
+	}
+}
+
+// Жанры, отличающиеся регистром и пробелами, сливаются в старший до создания
+// индекса; альбомы и связи треков переезжают на него без дублей.
+func TestGenresNameLowerMigrationMergesDuplicates(t *testing.T) {
+	db := migrationDB(t)
+	migrateTo(t, db, 1, 20)
+	mustExec(t, db, "INSERT INTO genres (id, name) VALUES (1, 'Rock'), (2, 'rock'), (3, ' ROCK '), (4, 'Jazz')")
+	mustExec(t, db, "INSERT INTO albums (id, title, artist, genre_id) VALUES (1, 'A', 'X', 2), (2, 'B', 'Y', 4)")
+	mustExec(t, db, "INSERT INTO tracks (id, album_id, title, track_number) VALUES (1, 1, 'one', 1), (2, 1, 'two', 2)")
+	mustExec(t, db, "INSERT INTO track_genres (track_id, genre_id) VALUES (1, 1), (1, 2), (2, 2), (2, 3), (2, 4)")
+
+	migrateTo(t, db, 21, 21)
+
+	var names []string
+	if err := db.Raw("SELECT name FROM genres ORDER BY id").Scan(&names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "Rock,Jazz" {
+		t.Errorf("genres after merge: %v", names)
+	}
+	var albumGenre int
+	if err := db.Raw("SELECT genre_id FROM albums WHERE id = 1").Row().Scan(&albumGenre); err != nil || albumGenre != 1 {
+		t.Errorf("album 1 genre: %d, %v", albumGenre, err)
+	}
+	var links []string
+	if err := db.Raw("SELECT track_id || ':' || genre_id FROM track_genres ORDER BY track_id, genre_id").Scan(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(links, ",") != "1:1,2:1,2:4" {
+		t.Errorf("track_genres after merge: %v", links)
+	}
+}
+
+// Повторные рецензии автора на одну цель мягко удаляются до создания индекса:
+// остаётся одобренная, средняя альбома пересчитывается.
+func TestReviewsUserTargetMigrationDropsDuplicates(t *testing.T) {
+	db := migrationDB(t)
+	migrateTo(t, db, 1, 23)
+	mustExec(t, db, "INSERT INTO users (id, username, email, password) VALUES (1, 'a', 'a@example.test', 'x'), (2, 'b', 'b@example.test', 'x')")
+	mustExec(t, db, "INSERT INTO genres (id, name) VALUES (1, 'Rock')")
+	mustExec(t, db, "INSERT INTO albums (id, title, artist, genre_id, average_rating) VALUES (1, 'A', 'X', 1, 40)")
+	review := `INSERT INTO reviews (id, user_id, album_id, rating_rhymes, rating_structure, rating_implementation,
+		rating_individuality, atmosphere_multiplier, final_score, status, created_at)
+		VALUES (?, ?, 1, 5, 5, 5, 5, 1, ?, ?, NOW() - ? * INTERVAL '1 day')`
+	mustExec(t, db, review, 1, 1, 20, "approved", 3)
+	mustExec(t, db, review, 2, 1, 60, "approved", 2)
+	mustExec(t, db, review, 3, 1, 90, "pending", 1)
+	mustExec(t, db, review, 4, 2, 30, "approved", 1)
+
+	migrateTo(t, db, 24, 24)
+
+	var live []int
+	if err := db.Raw("SELECT id FROM reviews WHERE deleted_at IS NULL ORDER BY id").Scan(&live).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(live) != 2 || live[0] != 2 || live[1] != 4 {
+		t.Errorf("live reviews: %v, want [2 4]", live)
+	}
+	var average float64
+	if err := db.Raw("SELECT average_rating FROM albums WHERE id = 1").Row().Scan(&average); err != nil || average != 45 {
+		t.Errorf("album average: %v, %v; want 45", average, err)
+	}
+}
+
+// MIGRATIONS_MODE=auto — устаревший синоним versioned: пустая схема доходит
+// до последней миграции, а manual не трогает её вовсе.
+func TestMigrateSchemaModes(t *testing.T) {
+	list, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := list[len(list)-1].Version
+
+	for _, mode := range []string{"versioned", "auto"} {
+		t.Run(mode, func(t *testing.T) {
+			db := migrationDB(t)
+			if err := migrateSchema(db, config.DBConfig{MigrationsMode: mode}); err != nil {
+				t.Fatal(err)
+			}
+			version, err := CurrentMigrationVersion(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != latest {
+				t.Errorf("version %04d, want %04d", version, latest)
+			}
+			if !db.Migrator().HasTable("reviews") {
+				t.Error("reviews table not created")
+			}
+		})
+	}
+
+	t.Run("manual", func(t *testing.T) {
+		db := migrationDB(t)
+		if err := migrateSchema(db, config.DBConfig{MigrationsMode: "manual"}); err != nil {
+			t.Fatal(err)
+		}
+		if db.Migrator().HasTable("users") || db.Migrator().HasTable("schema_migrations") {
+			t.Error("manual mode changed the schema")
+		}
+	})
+}
+
+// После миграции (versioned и устаревший auto) на месте индексы и внешние
+// ключи горячих путей из 0026.
+func TestHotPathIndexesAfterMigration(t *testing.T) {
+	indexes := []string{
+		"idx_albums_genre_id", "idx_tracks_album_id",
+		"idx_reviews_user_id", "idx_reviews_album_id", "idx_reviews_track_id", "idx_reviews_status_created_at",
+		"idx_review_likes_review_id", "idx_review_likes_created_at",
+		"idx_track_likes_track_id", "idx_track_likes_created_at",
+		"idx_album_likes_album_id", "idx_album_likes_created_at",
+	}
+	foreignKeys := []string{
+		"albums.genre_id", "tracks.album_id",
+		"reviews.user_id", "reviews.album_id", "reviews.track_id",
+		"review_likes.user_id", "review_likes.review_id",
+		"track_likes.user_id", "track_likes.track_id",
+		"album_likes.user_id", "album_likes.album_id",
+	}
+
+	for _, mode := range []string{"versioned", "auto"} {
+		t.Run(mode, func(t *testing.T) {
+			db := migrationDB(t)
+			if err := migrateSchema(db, config.DBConfig{MigrationsMode: mode}); err != nil {
+				t.Fatal(err)
+			}
+
+			var existing []string
+			if err := db.Raw("SELECT indexname FROM pg_indexes WHERE schemaname = 'migration_test'").Scan(&existing).Error; err != nil {
+				t.Fatal(err)
+			}
+			have := make(map[string]bool, len(existing))
+			for _, name := range existing {
+				have[name] = true
+			}
+			for _, name := range indexes {
+				if !have[name] {
+					t.Errorf("index %s missing", name)
+				}
+			}
+
+			var keyed []string
+			if err := db.Raw(`
+				SELECT con.conrelid::regclass::text || '.' || att.attname
+				FROM pg_constraint con
+				JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = ANY (con.conkey)
+				WHERE con.contype = 'f' AND con.connamespace = 'migration_test'::regnamespace`).Scan(&keyed).Error; err != nil {
+				t.Fatal(err)
+			}
+			have = make(map[string]bool, len(keyed))
+			for _, column := range keyed {
+				have[column] = true
+			}
+			for _, column := range foreignKeys {
+				if !have[column] {
+					t.Errorf("foreign key on %s missing", column)
+				}
+			}
+		})
+	}
+}
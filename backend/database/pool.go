@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"music-review-site/backend/config"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormLogLevel maps DB_LOG_LEVEL (silent, error, warn, info) to the GORM level.
+// Значение по умолчанию (info только в dev) выбирает config.Load.
+func gormLogLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+// connectionParams дописывает к DSN таймаут подключения и statement_timeout:
+// неизвестные ключи pgx передаёт серверу как параметры сессии, поэтому
+// таймаут действует на каждое соединение пула, а не только на первое.
+func connectionParams(cfg config.DBConfig) string {
+	params := ""
+	if seconds := int(cfg.ConnectTimeout.Seconds()); seconds > 0 {
+		params += fmt.Sprintf(" connect_timeout=%d", seconds)
+	}
+	if cfg.StatementTimeout > 0 {
+		params += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeout.Milliseconds())
+	}
+	return params
+}
+
+// openWithRetry opens the connection and checks it with PingContext, retrying
+// with exponential backoff: в docker-compose backend может стартовать раньше,
+// чем postgres начнёт принимать соединения.
+func openWithRetry(dsn string, cfg config.DBConfig, gormConfig *gorm.Config) (*gorm.DB, error) {
+	gormConfig.DisableAutomaticPing = true
+	backoff := time.Second
+	var lastErr error
+	for attempt := 0; attempt <= cfg.ConnectRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("Database is not reachable (%v), retry %d/%d in %s", lastErr, attempt, cfg.ConnectRetries, backoff)
+			time.Sleep(backoff)
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+
+		db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if cfg.ConnectTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		}
+		err = sqlDB.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+		sqlDB.Close()
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// applyPool sets pool limits on the underlying *sql.DB.
+func applyPool(db *gorm.DB, cfg config.DBConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	return nil
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"music-review-site/backend/config"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Настройки пула из конфигурации действуют на *sql.DB: лишние свободные
+// соединения закрываются, а соединения старше ConnMaxLifetime не переиспользуются.
+func TestApplyPool(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	cfg := config.DBConfig{MaxOpenConns: 4, MaxIdleConns: 2, ConnMaxLifetime: 200 * time.Millisecond}
+	if err := applyPool(db, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := sqlDB.Stats().MaxOpenConnections; got != cfg.MaxOpenConns {
+		t.Errorf("MaxOpenConnections = %d, want %d", got, cfg.MaxOpenConns)
+	}
+
+	// Занимаем все четыре соединения и отпускаем: в пуле остаются два свободных.
+	ctx := context.Background()
+	conns := make([]*sql.Conn, 0, cfg.MaxOpenConns)
+	for i := 0; i < cfg.MaxOpenConns; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+	if idle := sqlDB.Stats().Idle; idle != cfg.MaxIdleConns {
+		t.Errorf("idle connections = %d, want %d", idle, cfg.MaxIdleConns)
+	}
+
+	time.Sleep(2 * cfg.ConnMaxLifetime)
+	if err := sqlDB.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if closed := sqlDB.Stats().MaxLifetimeClosed; closed == 0 {
+		t.Error("no connections closed after ConnMaxLifetime")
+	}
+}
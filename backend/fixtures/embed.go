@@ -0,0 +1,9 @@
+// Package fixtures embeds the demo catalog in JSON: жанры, альбомы, треки и
+// рецензии, которые database.RunSeeds разбирает в модели. Добавить альбом или
+// рецензию в демо-данные — правка JSON, а не кода сидера.
+package fixtures
+
+import "embed"
+
+//go:embed *.json
+var Files embed.FS
@@ -3,17 +3,18 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
+	"music-review-site/backend/config"
 	"music-review-site/backend/database"
 	"music-review-site/backend/routes"
+	"music-review-site/backend/utils"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
@@ -22,47 +23,33 @@ func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
+	// Конфигурация читается один раз: ошибки в env останавливают старт сразу
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Структурированный лог: JSON в prod, текст в dev (LOG_FORMAT, LOG_LEVEL)
+	slog.SetDefault(utils.NewLogger(cfg.LogFormat, cfg.LogLevel))
 
 	// Initialize database
-	db, err := database.InitDB()
+	db, err := database.InitDB(cfg)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	// Initialize Gin router
-	r := gin.Default()
-
-	// CORS configuration
-	config := cors.DefaultConfig()
-	allowOriginsEnv := strings.TrimSpace(os.Getenv("CORS_ALLOW_ORIGINS"))
-	if allowOriginsEnv == "" {
-		allowOriginsEnv = "http://localhost:3000"
-	}
-	origins := []string{}
-	for _, origin := range strings.Split(allowOriginsEnv, ",") {
-		o := strings.TrimSpace(origin)
-		if o != "" {
-			origins = append(origins, o)
-		}
-	}
-	config.AllowOrigins = origins
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "X-User-ID"}
-	config.AllowCredentials = true
-	r.Use(cors.New(config))
+	// Router: middleware, CORS и маршруты собираются в routes.NewServer
+	r := routes.NewServer(db, cfg)
+	port := cfg.Port
 
-	// Setup routes
-	routes.SetupRoutes(r, db)
-
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	// Базовый контекст всех запросов: если за время Shutdown запросы не
+	// завершились, его отмена прерывает их запросы к БД.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
 	srv := &http.Server{
 		Addr:              ":" + port,
 		Handler:           r,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
 		ReadHeaderTimeout: 5 * time.Second,
 		ReadTimeout:       15 * time.Second,
 		WriteTimeout:      15 * time.Second,
@@ -86,8 +73,18 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Shutdown перестаёт принимать соединения и ждёт текущие запросы; по
+	// истечении таймаута оставшиеся отменяются и соединения закрываются.
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("Server shutdown error: %v", err)
+		cancelRequests()
+		srv.Close()
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("Database close error: %v", err)
+		}
 	}
 
 	log.Println("Server stopped")
@@ -4,7 +4,6 @@ import (
 	"music-review-site/backend/models"
 	"music-review-site/backend/utils"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 
@@ -12,10 +11,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// AuthOptions — как middleware находит пользователя запроса: токен сессии
+// и, если AllowUserIDHeader (AUTH_ALLOW_USER_ID_HEADER), заголовок X-User-ID.
+type AuthOptions struct {
+	Sessions          *utils.Sessions
+	AllowUserIDHeader bool
+}
+
 // AuthMiddleware checks if user is authenticated
-func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
+func AuthMiddleware(db *gorm.DB, opts AuthOptions) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, ok := resolveAuthenticatedUserID(c)
+		userID, ok := opts.resolveUserID(c)
 		if !ok {
 			c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
 				Error:   "Unauthorized",
@@ -28,7 +34,7 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 
 		// Get user from database
 		var user models.User
-		if err := db.First(&user, userID).Error; err != nil {
+		if err := db.WithContext(c.Request.Context()).First(&user, userID).Error; err != nil {
 			c.JSON(http.StatusUnauthorized, utils.ErrorResponse{
 				Error:   "Unauthorized",
 				Message: "User not found",
@@ -46,12 +52,12 @@ func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 }
 
 // OptionalAuthMiddleware is like AuthMiddleware but doesn't require authentication
-func OptionalAuthMiddleware(db *gorm.DB) gin.HandlerFunc {
+func OptionalAuthMiddleware(db *gorm.DB, opts AuthOptions) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID, ok := resolveAuthenticatedUserID(c)
+		userID, ok := opts.resolveUserID(c)
 		if ok {
 			var user models.User
-			if err := db.First(&user, userID).Error; err == nil {
+			if err := db.WithContext(c.Request.Context()).First(&user, userID).Error; err == nil {
 				c.Set("user", user)
 				c.Set("user_id", user.ID)
 			}
@@ -60,14 +66,14 @@ func OptionalAuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func resolveAuthenticatedUserID(c *gin.Context) (uint, bool) {
+func (opts AuthOptions) resolveUserID(c *gin.Context) (uint, bool) {
 	if token := bearerToken(c.GetHeader("Authorization")); token != "" {
-		if userID, err := utils.ValidateSessionToken(token); err == nil {
+		if userID, err := opts.Sessions.Validate(token); err == nil {
 			return userID, true
 		}
 	}
 
-	if !allowUserIDHeaderFallback() {
+	if !opts.AllowUserIDHeader {
 		return 0, false
 	}
 
@@ -95,19 +101,6 @@ func bearerToken(header string) string {
 	return strings.TrimSpace(parts[1])
 }
 
-func allowUserIDHeaderFallback() bool {
-	val := strings.TrimSpace(os.Getenv("AUTH_ALLOW_USER_ID_HEADER"))
-	if val != "" {
-		switch strings.ToLower(val) {
-		case "1", "true", "yes", "y", "on":
-			return true
-		case "0", "false", "no", "n", "off":
-			return false
-		}
-	}
-	return strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))) != "prod"
-}
-
 // AdminMiddleware checks if user is admin
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sharedCacheMaxEntries ограничивает память SharedCache: при переполнении новые
+// ответы просто не кешируются, пока старые не истекут.
+const sharedCacheMaxEntries = 1000
+
+// ETag buffers a successful GET response, sets ETag from its SHA-256 and answers
+// 304 Not Modified when If-None-Match matches. Анонимным ответам ставится
+// Cache-Control public с max-age; ответам авторизованному пользователю (в них
+// есть liked_by_me и другие персональные поля) — private, no-cache, чтобы прокси
+// и общий кеш браузера их не переиспользовали. maxAge задаётся на маршруте.
+func ETag(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buffer := &bufferedWriter{ResponseWriter: original}
+		c.Writer = buffer
+		// При панике в хендлере Recovery должен писать 500 в исходный writer,
+		// а не в буфер, который уже никто не отправит.
+		defer func() { c.Writer = original }()
+		c.Next()
+		c.Writer = original
+
+		status := buffer.Status()
+		if status != http.StatusOK {
+			buffer.flush()
+			return
+		}
+
+		sum := sha256.Sum256(buffer.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		header := original.Header()
+		header.Set("ETag", etag)
+		header.Add("Vary", "Authorization")
+		if _, personalized := GetUserIDFromContext(c); personalized || hasCredentials(c) {
+			header.Set("Cache-Control", "private, no-cache")
+		} else {
+			header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+		}
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			header.Del("Content-Type")
+			original.WriteHeader(http.StatusNotModified)
+			original.WriteHeaderNow()
+			return
+		}
+		buffer.flush()
+	}
+}
+
+// SharedCache keeps successful anonymous GET responses in process memory for ttl,
+// keyed by path and query. Инвалидации нет — только TTL, поэтому подходит для
+// списков вроде популярного, где отставание на десятки секунд допустимо. Запросы
+// с учётными данными идут мимо кеша: ответ для них может быть персональным.
+// Ставится после ETag, чтобы попадание в кеш тоже отвечало 304.
+func SharedCache(ttl time.Duration) gin.HandlerFunc {
+	cache := &responseCache{entries: map[string]cachedResponse{}}
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || hasCredentials(c) {
+			c.Next()
+			return
+		}
+
+		key := c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()
+		if entry, ok := cache.get(key); ok {
+			c.Header("X-Cache", "HIT")
+			c.Data(http.StatusOK, entry.contentType, entry.body)
+			c.Abort()
+			return
+		}
+
+		original := c.Writer
+		buffer := &bufferedWriter{ResponseWriter: original}
+		c.Writer = buffer
+		defer func() { c.Writer = original }()
+		c.Next()
+		c.Writer = original
+
+		if _, personalized := GetUserIDFromContext(c); !personalized && buffer.Status() == http.StatusOK {
+			cache.put(key, cachedResponse{
+				body:        append([]byte(nil), buffer.body.Bytes()...),
+				contentType: original.Header().Get("Content-Type"),
+				expires:     time.Now().Add(ttl),
+			})
+		}
+		c.Header("X-Cache", "MISS")
+		buffer.flush()
+	}
+}
+
+// hasCredentials сообщает, прислал ли клиент что-то, по чему его можно опознать:
+// такой ответ нельзя отдавать из общего кеша, даже если маршрут без OptionalAuth.
+func hasCredentials(c *gin.Context) bool {
+	return c.GetHeader("Authorization") != "" || c.GetHeader("X-User-ID") != ""
+}
+
+// etagMatches разбирает If-None-Match: список тегов через запятую или "*".
+// Слабые теги (W/"...") сравниваются по значению, как того требует RFC 9110 для GET.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter придерживает статус и тело ответа, чтобы по ним можно было
+// посчитать ETag до отправки клиенту. Заголовки пишутся сразу в исходный writer.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.status == 0 && w.body.Len() == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.status != 0 || w.body.Len() > 0
+}
+
+// flush отправляет придержанный ответ как есть.
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.Status())
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+type cachedResponse struct {
+	body        []byte
+	contentType string
+	expires     time.Time
+}
+
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+func (rc *responseCache) get(key string) (cachedResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+func (rc *responseCache) put(key string, entry cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.entries) >= sharedCacheMaxEntries {
+		now := time.Now()
+		for k, e := range rc.entries {
+			if now.After(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		if len(rc.entries) >= sharedCacheMaxEntries {
+			return
+		}
+	}
+	rc.entries[key] = entry
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Паника под ETag и SharedCache: ответ Recovery доходит до клиента, а не
+// остаётся в буфере с пустым 200.
+func TestCachedRoutePanicReturnsError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Recovery())
+	r.GET("/etag", ETag(time.Minute), func(c *gin.Context) { panic("boom") })
+	r.GET("/shared", ETag(time.Minute), SharedCache(time.Minute), func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("boom")
+	})
+
+	for _, path := range []string{"/etag", "/shared"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Internal server error") {
+			t.Errorf("%s: want 500 ErrorResponse, got %d %q", path, w.Code, w.Body.String())
+		}
+		if w.Header().Get("ETag") != "" {
+			t.Errorf("%s: error response must not carry ETag", path)
+		}
+	}
+}
+
+func TestETagNotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/genres", ETag(time.Minute), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"genres": []string{"rock"}})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/genres", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Body.Len() == 0 {
+		t.Fatalf("first request: %d etag=%q body=%q", w.Code, etag, w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/genres", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("If-None-Match: want empty 304, got %d %q", w.Code, w.Body.String())
+	}
+}
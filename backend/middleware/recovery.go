@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"expvar"
+	"io"
+	"music-review-site/backend/utils"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PanicsTotal считает паники, перехваченные Recovery, с момента старта процесса.
+// Значение видно в /health/ready?verbose=true.
+var PanicsTotal = expvar.NewInt("http_panics_total")
+
+// Recovery перехватывает панику в хендлере: пишет её со стеком в лог запроса
+// (с request_id) и отвечает стандартным ErrorResponse 500, а не пустым телом,
+// как gin.Recovery. Ставится после RequestLogger, чтобы лог знал request_id.
+// Собственный вывод gin (в stderr, без request_id) отключён, иначе каждая
+// паника попадала бы в лог дважды.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		PanicsTotal.Add(1)
+		Logger(c).Error("panic recovered",
+			"panic", recovered,
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"stack", string(debug.Stack()),
+		)
+		utils.RespondError(c, http.StatusInternalServerError, "Internal server error")
+	})
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryLogsPanicOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var ginOut, logOut bytes.Buffer
+	defaultWriter, defaultLogger := gin.DefaultErrorWriter, slog.Default()
+	gin.DefaultErrorWriter = &ginOut
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logOut, nil)))
+	t.Cleanup(func() {
+		gin.DefaultErrorWriter = defaultWriter
+		slog.SetDefault(defaultLogger)
+	})
+
+	r := gin.New()
+	r.Use(Recovery())
+	r.GET("/boom", func(c *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Internal server error") {
+		t.Fatalf("want 500 ErrorResponse, got %d %s", w.Code, w.Body.String())
+	}
+	if ginOut.Len() != 0 {
+		t.Errorf("gin wrote its own panic log: %s", ginOut.String())
+	}
+	if n := strings.Count(logOut.String(), "panic recovered"); n != 1 {
+		t.Errorf("want one panic record, got %d: %s", n, logOut.String())
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader передаёт идентификатор запроса между прокси, backend и логами.
+const RequestIDHeader = "X-Request-ID"
+
+// loggerContextKey — ключ логгера запроса в gin.Context и в context.Context.
+type loggerContextKey struct{}
+
+const loggerKey = "logger"
+
+// RequestLogger assigns a request ID (берёт из X-Request-ID, если его прислал
+// прокси, иначе генерирует), кладёт в контекст логгер с этим ID и после
+// обработки пишет строку access-лога: метод, путь, статус, длительность, user_id.
+// Тела запросов не логируются.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		logger := slog.Default().With("request_id", requestID)
+		c.Set(loggerKey, logger)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), loggerContextKey{}, logger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		if userID, ok := GetUserIDFromContext(c); ok {
+			attrs = append(attrs, "user_id", userID)
+		}
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("request", attrs...)
+		case status >= http.StatusBadRequest:
+			logger.Warn("request", attrs...)
+		default:
+			logger.Info("request", attrs...)
+		}
+	}
+}
+
+// Logger returns the request-scoped logger (с request_id), или логгер по
+// умолчанию вне запроса.
+func Logger(c *gin.Context) *slog.Logger {
+	if value, ok := c.Get(loggerKey); ok {
+		if logger, ok := value.(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// LoggerFromContext returns the request logger stored in ctx by RequestLogger.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"music-review-site/backend/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest — нестандартный код nginx для запроса, который
+// клиент оборвал раньше, чем получил ответ.
+const StatusClientClosedRequest = 499
+
+// RequestTimeout sets a deadline on the request context; 0 отключает таймаут.
+// Контроллеры передают этот контекст в GORM, поэтому запросы к БД прерываются
+// по таймауту или при отключении клиента. Ошибку 5xx, которую хендлер вернул из-за прерванного
+// контекста, middleware заменяет на 503 (таймаут) или 499 (клиент ушёл).
+// Собственный cancel() при выходе из middleware — в том числе при панике, когда
+// Recovery пишет 500 уже после него, — за отмену клиентом не считается.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent := c.Request.Context()
+		ctx, cancel := parent, context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, parent: parent, ctx: ctx}
+		c.Next()
+	}
+}
+
+// LongRunning replaces the REQUEST_TIMEOUT deadline with its own for admin
+// maintenance routes (пересчёт оценок и рейтингов проходит по всем рецензиям).
+// Отключение клиента такой запрос не прерывает: оборванный на середине пересчёт
+// хуже долгого. Дедлайн записи ответа на соединении (WriteTimeout сервера)
+// продлевается на тот же срок.
+func LongRunning(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent := context.WithoutCancel(c.Request.Context())
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		if w, ok := c.Writer.(*timeoutWriter); ok {
+			w.parent, w.ctx = parent, ctx
+		}
+		if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			Logger(c).Warn("failed to extend write deadline", "error", err)
+		}
+		c.Next()
+	}
+}
+
+// timeoutWriter подменяет ответ 5xx, записанный после отмены контекста.
+// parent — контекст запроса до RequestTimeout: его отмена означает, что ушёл
+// клиент; ctx — контекст с дедлайном, который видят хендлеры.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	parent   context.Context
+	ctx      context.Context
+	replaced bool
+}
+
+// interruption возвращает ответ вместо 5xx, если запрос прервал клиент или
+// дедлайн. ctx, отменённый собственным cancel() middleware, прерыванием не считается.
+func (w *timeoutWriter) interruption() (utils.ErrorResponse, bool) {
+	switch {
+	case w.parent.Err() != nil:
+		return utils.ErrorResponse{
+			Error:   "Client Closed Request",
+			Message: "Request canceled by client",
+			Code:    StatusClientClosedRequest,
+		}, true
+	case errors.Is(w.ctx.Err(), context.DeadlineExceeded):
+		return utils.ErrorResponse{
+			Error:   http.StatusText(http.StatusServiceUnavailable),
+			Message: "Request timed out",
+			Code:    http.StatusServiceUnavailable,
+		}, true
+	}
+	return utils.ErrorResponse{}, false
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.replaced {
+		return
+	}
+	if code < http.StatusInternalServerError {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	response, interrupted := w.interruption()
+	if !interrupted {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.replaced = true
+	body, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(response.Code)
+	w.ResponseWriter.Write(body)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.replaced {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.replaced {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap gives http.ResponseController access to the connection (SetWriteDeadline).
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
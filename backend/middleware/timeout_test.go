@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLongRunningOverridesRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestTimeout(20 * time.Millisecond))
+	r.GET("/short", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "too slow"})
+	})
+	r.GET("/long", LongRunning(time.Second), func(c *gin.Context) {
+		time.Sleep(60 * time.Millisecond)
+		if err := c.Request.Context().Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		deadline, ok := c.Request.Context().Deadline()
+		if !ok || time.Until(deadline) < 500*time.Millisecond {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "deadline not extended"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "done"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/short", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("short route: want 503 after REQUEST_TIMEOUT, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/long", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("long route: want 200, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// Цепочка как в routes.NewServer: RequestLogger, Recovery, RequestTimeout.
+// Паника разматывает стек через defer cancel() в RequestTimeout, и Recovery
+// пишет 500 уже в отменённый контекст — это не отмена клиентом.
+func TestRequestTimeoutKeepsPanicStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger(), Recovery(), RequestTimeout(time.Second))
+	r.GET("/boom", func(c *gin.Context) { panic("boom") })
+	r.GET("/gone", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query canceled"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "Internal server error") {
+		t.Errorf("panic: want 500 ErrorResponse, got %d %s", w.Code, w.Body.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/gone", nil).WithContext(ctx))
+	if w.Code != StatusClientClosedRequest {
+		t.Errorf("client gone: want 499, got %d %s", w.Code, w.Body.String())
+	}
+}
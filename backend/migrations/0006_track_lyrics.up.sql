@@ -0,0 +1 @@
+ALTER TABLE tracks ADD COLUMN IF NOT EXISTS lyrics text NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS likes_private boolean NOT NULL DEFAULT false;
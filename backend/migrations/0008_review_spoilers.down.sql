@@ -0,0 +1,2 @@
+ALTER TABLE reviews DROP COLUMN IF EXISTS content_warning;
+ALTER TABLE reviews DROP COLUMN IF EXISTS has_spoilers;
@@ -0,0 +1,2 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS has_spoilers boolean NOT NULL DEFAULT false;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS content_warning varchar(200) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_albums_status;
+ALTER TABLE albums DROP COLUMN IF EXISTS moderated_at;
+ALTER TABLE albums DROP COLUMN IF EXISTS moderated_by;
+ALTER TABLE albums DROP COLUMN IF EXISTS submitted_by;
+ALTER TABLE albums DROP COLUMN IF EXISTS status;
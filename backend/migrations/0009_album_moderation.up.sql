@@ -0,0 +1,6 @@
+-- Существующие альбомы заводились админами и считаются одобренными.
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'approved';
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS submitted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS moderated_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_albums_status ON albums(status);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS track_listens (
+    track_id INTEGER NOT NULL REFERENCES tracks(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (track_id, day)
+);
+CREATE INDEX IF NOT EXISTS idx_track_listens_day ON track_listens(day);
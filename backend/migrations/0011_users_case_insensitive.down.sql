@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ux_users_email_lower;
+DROP INDEX IF EXISTS ux_users_username_lower;
@@ -0,0 +1,36 @@
+-- Email хранится в нижнем регистре; username и email уникальны без учёта регистра.
+UPDATE users SET email = LOWER(TRIM(email)) WHERE email <> LOWER(TRIM(email));
+
+-- Username, отличающиеся только регистром: старший по id аккаунт сохраняет имя,
+-- остальным дописывается _<id>. Вход идёт по email, поэтому переименование
+-- доступ не ломает.
+WITH duplicates AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY LOWER(username) ORDER BY id) AS copy
+    FROM users
+)
+UPDATE users SET username = users.username || '_' || users.id
+FROM duplicates
+WHERE users.id = duplicates.id AND duplicates.copy > 1;
+
+-- Email, совпадающие без учёта регистра, — это разные аккаунты одного адреса;
+-- какой оставить, решает администратор, поэтому миграция останавливается.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(email || ' (ids ' || ids || ')', ', ') INTO conflicts
+    FROM (
+        SELECT LOWER(email) AS email, string_agg(id::TEXT, ', ' ORDER BY id) AS ids
+        FROM users
+        GROUP BY LOWER(email)
+        HAVING COUNT(*) > 1
+    ) AS duplicated;
+    IF conflicts IS NOT NULL THEN
+        -- pgx показывает в ошибке только текст исключения, без HINT, поэтому
+        -- инструкция идёт прямо в нём.
+        RAISE EXCEPTION 'users share an email up to case: %; change the email of all but one account in each group (UPDATE users SET email = ... WHERE id = ...) and restart', conflicts;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_username_lower ON users (LOWER(username));
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email_lower ON users (LOWER(email));
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ux_tracks_album_number;
@@ -0,0 +1,28 @@
+-- Номер трека уникален в пределах альбома среди неудалённых треков.
+-- Сиды и старые правки могли оставить в альбоме повторяющиеся номера: первый
+-- по id трек сохраняет номер, остальные получают номера после последнего
+-- в альбоме, в порядке id. Иначе индекс не создастся и backend не стартует.
+WITH numbered AS (
+    SELECT id, album_id,
+           ROW_NUMBER() OVER (PARTITION BY album_id, track_number ORDER BY id) AS copy
+    FROM tracks
+    WHERE deleted_at IS NULL AND track_number IS NOT NULL
+), last_number AS (
+    SELECT album_id, MAX(track_number) AS max_number
+    FROM tracks
+    WHERE deleted_at IS NULL
+    GROUP BY album_id
+), renumbered AS (
+    SELECT numbered.id,
+           last_number.max_number + ROW_NUMBER() OVER (PARTITION BY numbered.album_id ORDER BY numbered.id) AS track_number
+    FROM numbered
+    JOIN last_number ON last_number.album_id = numbered.album_id
+    WHERE numbered.copy > 1
+)
+UPDATE tracks SET track_number = renumbered.track_number
+FROM renumbered
+WHERE tracks.id = renumbered.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tracks_album_number
+    ON tracks (album_id, track_number)
+    WHERE deleted_at IS NULL AND track_number IS NOT NULL;
@@ -0,0 +1,11 @@
+UPDATE users
+SET avatar_path = '/avatars/' || substr(avatar_path, length('/uploads/avatars/') + 1)
+WHERE avatar_path LIKE '/uploads/avatars/%';
+
+UPDATE albums
+SET cover_image_path = '/preview/uploads/' || substr(cover_image_path, length('/uploads/covers/') + 1)
+WHERE cover_image_path LIKE '/uploads/covers/%';
+
+UPDATE tracks
+SET cover_image_path = '/preview/uploads/' || substr(cover_image_path, length('/uploads/covers/') + 1)
+WHERE cover_image_path LIKE '/uploads/covers/%';
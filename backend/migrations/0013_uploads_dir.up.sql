@@ -0,0 +1,14 @@
+-- Загрузки переехали из дерева frontend в UPLOADS_DIR и раздаются backend по /uploads/.
+-- Файлы из frontend/public/avatars и frontend/public/preview/uploads нужно скопировать
+-- в UPLOADS_DIR/avatars и UPLOADS_DIR/covers соответственно.
+UPDATE users
+SET avatar_path = '/uploads/avatars/' || substr(avatar_path, length('/avatars/') + 1)
+WHERE avatar_path LIKE '/avatars/%';
+
+UPDATE albums
+SET cover_image_path = '/uploads/covers/' || substr(cover_image_path, length('/preview/uploads/') + 1)
+WHERE cover_image_path LIKE '/preview/uploads/%';
+
+UPDATE tracks
+SET cover_image_path = '/uploads/covers/' || substr(cover_image_path, length('/preview/uploads/') + 1)
+WHERE cover_image_path LIKE '/preview/uploads/%';
@@ -0,0 +1,2 @@
+-- Исходные username и email не сохраняются, откатывать нечего.
+SELECT 1;
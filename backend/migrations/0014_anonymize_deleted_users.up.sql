@@ -0,0 +1,8 @@
+-- Пользователи, удалённые до анонимизации, держали username/email в уникальных индексах.
+-- Освобождаем их под повторную регистрацию так же, как это теперь делает DELETE /users/:id.
+UPDATE users
+SET username = 'deleted_user_' || id,
+    email = 'deleted_user_' || id || '@deleted.invalid',
+    password = '!'
+WHERE deleted_at IS NOT NULL
+  AND username <> 'deleted_user_' || id;
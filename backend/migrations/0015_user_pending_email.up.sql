@@ -0,0 +1,5 @@
+-- Смена email через подтверждение: новый адрес ждёт в pending_email, в БД хранится хеш токена.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_token_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_token_expires TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_users_email_token_hash ON users (email_token_hash);
@@ -0,0 +1,4 @@
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS chk_reviews_atmosphere_multiplier;
+ALTER TABLE reviews ADD CONSTRAINT chk_reviews_atmosphere_multiplier
+    CHECK (atmosphere_multiplier >= 1.0000 AND atmosphere_multiplier <= 1.6072);
+ALTER TABLE reviews DROP COLUMN IF EXISTS atmosphere_rating;
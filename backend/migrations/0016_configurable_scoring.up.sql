@@ -0,0 +1,12 @@
+-- Формула итоговой оценки настраивается (SCORE_BASE_WEIGHT, SCORE_ATMOSPHERE_MAX).
+-- Храним исходную оценку атмосферы, чтобы пересчитать множитель при смене формулы,
+-- и снимаем жёсткий верхний предел множителя 1.6072.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS atmosphere_rating INTEGER NOT NULL DEFAULT 0;
+
+UPDATE reviews
+SET atmosphere_rating = ROUND(1 + (atmosphere_multiplier - 1) / (0.6072 / 9))
+WHERE atmosphere_rating = 0;
+
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_atmosphere_multiplier_check;
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS chk_reviews_atmosphere_multiplier;
+ALTER TABLE reviews ADD CONSTRAINT chk_reviews_atmosphere_multiplier CHECK (atmosphere_multiplier >= 1.0000);
@@ -0,0 +1,2 @@
+-- Email скрыт от посторонних по умолчанию; пользователь может открыть его флагом email_public.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_public BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,11 @@
+-- История смен username: кулдаун на смену и резерв старых имён.
+CREATE TABLE IF NOT EXISTS username_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_username TEXT NOT NULL,
+    new_username TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_username_history_user_id ON username_history(user_id);
+CREATE INDEX IF NOT EXISTS idx_username_history_created_at ON username_history(created_at);
+CREATE INDEX IF NOT EXISTS idx_username_history_old_username_lower ON username_history(LOWER(old_username));
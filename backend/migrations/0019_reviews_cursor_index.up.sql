@@ -0,0 +1,2 @@
+-- Индекс под курсорную пагинацию GET /reviews?cursor= (ORDER BY created_at DESC, id DESC).
+CREATE INDEX IF NOT EXISTS idx_reviews_created_id ON reviews(created_at, id);
@@ -0,0 +1,2 @@
+-- Причина отклонения рецензии, которую автор видит в GET /me/reviews.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS rejection_reason TEXT NOT NULL DEFAULT '';
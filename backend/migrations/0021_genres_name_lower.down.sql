@@ -0,0 +1 @@
+DROP INDEX IF EXISTS ux_genres_name_lower;
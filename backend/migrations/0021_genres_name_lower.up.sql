@@ -0,0 +1,30 @@
+-- Названия жанров уникальны без учёта регистра.
+-- Жанры, которые отличаются только регистром или пробелами по краям,
+-- сливаются в один, как при POST /admin/genres/:id/merge-into/:target: остаётся
+-- неудалённый с меньшим id, альбомы и связи треков переносятся на него,
+-- остальные удаляются насовсем (индекс покрывает и мягко удалённые строки).
+CREATE TEMPORARY TABLE genre_duplicates ON COMMIT DROP AS
+SELECT id, keep_id
+FROM (
+    SELECT id, FIRST_VALUE(id) OVER (PARTITION BY LOWER(TRIM(name)) ORDER BY deleted_at IS NOT NULL, id) AS keep_id
+    FROM genres
+) AS grouped
+WHERE id <> keep_id;
+
+UPDATE albums SET genre_id = genre_duplicates.keep_id
+FROM genre_duplicates
+WHERE albums.genre_id = genre_duplicates.id;
+
+INSERT INTO track_genres (track_id, genre_id)
+SELECT DISTINCT track_genres.track_id, genre_duplicates.keep_id
+FROM track_genres
+JOIN genre_duplicates ON genre_duplicates.id = track_genres.genre_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM track_genres kept
+    WHERE kept.track_id = track_genres.track_id AND kept.genre_id = genre_duplicates.keep_id
+);
+DELETE FROM track_genres USING genre_duplicates WHERE track_genres.genre_id = genre_duplicates.id;
+DELETE FROM genres USING genre_duplicates WHERE genres.id = genre_duplicates.id;
+
+UPDATE genres SET name = TRIM(name) WHERE name <> TRIM(name);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_genres_name_lower ON genres (LOWER(name));
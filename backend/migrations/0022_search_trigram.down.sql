@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tracks_title_trgm;
+DROP INDEX IF EXISTS idx_albums_artist_trgm;
+DROP INDEX IF EXISTS idx_albums_title_trgm;
@@ -0,0 +1,12 @@
+-- Триграммные индексы для подсказок поиска (GET /api/search/suggest).
+-- Без прав на CREATE EXTENSION миграция не падает: подсказки просто недоступны.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+    CREATE INDEX IF NOT EXISTS idx_albums_title_trgm ON albums USING gin (title gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_albums_artist_trgm ON albums USING gin (artist gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_tracks_title_trgm ON tracks USING gin (title gin_trgm_ops);
+EXCEPTION WHEN OTHERS THEN
+    RAISE WARNING 'pg_trgm unavailable, search suggestions disabled: %', SQLERRM;
+END
+$$;
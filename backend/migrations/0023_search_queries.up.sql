@@ -0,0 +1,10 @@
+-- Журнал поисковых запросов: популярное за неделю и запросы без результатов.
+CREATE TABLE IF NOT EXISTS search_queries (
+    id SERIAL PRIMARY KEY,
+    query TEXT NOT NULL,
+    results_count INTEGER NOT NULL DEFAULT 0,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_search_queries_query ON search_queries(query);
+CREATE INDEX IF NOT EXISTS idx_search_queries_created_at ON search_queries(created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ux_reviews_user_track;
+DROP INDEX IF EXISTS ux_reviews_user_album;
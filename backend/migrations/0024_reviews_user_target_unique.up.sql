@@ -0,0 +1,37 @@
+-- Одна рецензия пользователя на альбом и одна на трек; рецензии на альбом и его треки не конфликтуют.
+-- Старые повторные рецензии одного автора на ту же цель мягко удаляются:
+-- остаётся одобренная, среди равных — самая свежая. Средние оценки затронутых
+-- альбомов и треков пересчитываются так же, как CalculateAverageRating.
+CREATE TEMPORARY TABLE review_duplicates ON COMMIT DROP AS
+SELECT id, album_id, track_id
+FROM (
+    SELECT id, album_id, track_id,
+           ROW_NUMBER() OVER (
+               PARTITION BY user_id, album_id, track_id
+               ORDER BY status = 'approved' DESC, created_at DESC, id DESC
+           ) AS copy
+    FROM reviews
+    WHERE deleted_at IS NULL
+) AS numbered
+WHERE copy > 1;
+
+UPDATE reviews SET deleted_at = NOW()
+FROM review_duplicates
+WHERE reviews.id = review_duplicates.id;
+
+UPDATE albums SET average_rating = COALESCE((
+    SELECT FLOOR(AVG(final_score) + 0.5) FROM reviews
+    WHERE reviews.album_id = albums.id AND reviews.status = 'approved' AND reviews.deleted_at IS NULL
+), 0)
+WHERE id IN (SELECT album_id FROM review_duplicates);
+
+UPDATE tracks SET average_rating = COALESCE((
+    SELECT FLOOR(AVG(final_score) + 0.5) FROM reviews
+    WHERE reviews.track_id = tracks.id AND reviews.status = 'approved' AND reviews.deleted_at IS NULL
+), 0)
+WHERE id IN (SELECT track_id FROM review_duplicates);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_reviews_user_album ON reviews (user_id, album_id)
+    WHERE deleted_at IS NULL AND album_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_reviews_user_track ON reviews (user_id, track_id)
+    WHERE deleted_at IS NULL AND track_id IS NOT NULL;
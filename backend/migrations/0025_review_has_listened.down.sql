@@ -0,0 +1,2 @@
+ALTER TABLE reviews DROP COLUMN IF EXISTS has_listened;
+DROP TABLE IF EXISTS user_track_listens;
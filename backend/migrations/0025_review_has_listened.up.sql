@@ -0,0 +1,9 @@
+-- Факт прослушивания трека пользователем и отметка «слушал» у рецензии.
+CREATE TABLE IF NOT EXISTS user_track_listens (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    track_id INTEGER NOT NULL REFERENCES tracks(id) ON DELETE CASCADE,
+    first_listened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, track_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_track_listens_track_id ON user_track_listens(track_id);
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS has_listened BOOLEAN NOT NULL DEFAULT FALSE;
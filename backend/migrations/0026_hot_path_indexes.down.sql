@@ -0,0 +1,19 @@
+ALTER TABLE albums DROP CONSTRAINT IF EXISTS fk_albums_genre_id;
+ALTER TABLE tracks DROP CONSTRAINT IF EXISTS fk_tracks_album_id;
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS fk_reviews_user_id;
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS fk_reviews_album_id;
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS fk_reviews_track_id;
+ALTER TABLE review_likes DROP CONSTRAINT IF EXISTS fk_review_likes_user_id;
+ALTER TABLE review_likes DROP CONSTRAINT IF EXISTS fk_review_likes_review_id;
+ALTER TABLE track_likes DROP CONSTRAINT IF EXISTS fk_track_likes_user_id;
+ALTER TABLE track_likes DROP CONSTRAINT IF EXISTS fk_track_likes_track_id;
+ALTER TABLE album_likes DROP CONSTRAINT IF EXISTS fk_album_likes_user_id;
+ALTER TABLE album_likes DROP CONSTRAINT IF EXISTS fk_album_likes_album_id;
+-- Индексы FK-колонок из 0001 не трогаем: их создала начальная схема.
+DROP INDEX IF EXISTS idx_reviews_status_created_at;
+DROP INDEX IF EXISTS idx_review_likes_review_id;
+DROP INDEX IF EXISTS idx_review_likes_created_at;
+DROP INDEX IF EXISTS idx_track_likes_track_id;
+DROP INDEX IF EXISTS idx_track_likes_created_at;
+DROP INDEX IF EXISTS idx_album_likes_album_id;
+DROP INDEX IF EXISTS idx_album_likes_created_at;
@@ -0,0 +1,55 @@
+-- Внешние ключи и индексы для частых JOIN/GROUP BY (популярные треки, пересчёт оценок, лайки).
+-- Записи удаляются мягко (deleted_at), поэтому FK без каскада: физическое удаление
+-- строки, на которую ссылаются, должно падать, а не уносить за собой данные.
+-- Индексы FK-колонок из 0001 повторены с IF NOT EXISTS для схем, созданных AutoMigrate.
+CREATE INDEX IF NOT EXISTS idx_albums_genre_id ON albums (genre_id);
+CREATE INDEX IF NOT EXISTS idx_tracks_album_id ON tracks (album_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_user_id ON reviews (user_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_album_id ON reviews (album_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_track_id ON reviews (track_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_status_created_at ON reviews (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_review_likes_review_id ON review_likes (review_id);
+CREATE INDEX IF NOT EXISTS idx_review_likes_created_at ON review_likes (created_at);
+CREATE INDEX IF NOT EXISTS idx_track_likes_track_id ON track_likes (track_id);
+CREATE INDEX IF NOT EXISTS idx_track_likes_created_at ON track_likes (created_at);
+CREATE INDEX IF NOT EXISTS idx_album_likes_album_id ON album_likes (album_id);
+CREATE INDEX IF NOT EXISTS idx_album_likes_created_at ON album_likes (created_at);
+
+-- FK добавляются только там, где на колонке ещё нет внешнего ключа. NOT VALID не
+-- проверяет старые строки при добавлении; проверка идёт следом, и если в данных
+-- есть висячие ссылки, ключ остаётся непроверенным, а новые строки всё равно проверяются.
+DO $$
+DECLARE
+    fk RECORD;
+    fk_name TEXT;
+BEGIN
+    FOR fk IN SELECT * FROM (VALUES
+        ('albums', 'genre_id', 'genres'),
+        ('tracks', 'album_id', 'albums'),
+        ('reviews', 'user_id', 'users'),
+        ('reviews', 'album_id', 'albums'),
+        ('reviews', 'track_id', 'tracks'),
+        ('review_likes', 'user_id', 'users'),
+        ('review_likes', 'review_id', 'reviews'),
+        ('track_likes', 'user_id', 'users'),
+        ('track_likes', 'track_id', 'tracks'),
+        ('album_likes', 'user_id', 'users'),
+        ('album_likes', 'album_id', 'albums')
+    ) AS t(tbl, col, ref)
+    LOOP
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint con
+            JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = ANY (con.conkey)
+            WHERE con.contype = 'f' AND con.conrelid = fk.tbl::regclass AND att.attname = fk.col
+        ) THEN
+            fk_name := format('fk_%s_%s', fk.tbl, fk.col);
+            EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (%I) REFERENCES %I (id) NOT VALID',
+                fk.tbl, fk_name, fk.col, fk.ref);
+            BEGIN
+                EXECUTE format('ALTER TABLE %I VALIDATE CONSTRAINT %I', fk.tbl, fk_name);
+            EXCEPTION WHEN foreign_key_violation THEN
+                RAISE NOTICE 'constraint % left NOT VALID: dangling references in %.%', fk_name, fk.tbl, fk.col;
+            END;
+        END IF;
+    END LOOP;
+END $$;
@@ -0,0 +1,8 @@
+// Package migrations embeds the numbered SQL migrations (NNNN_name.up.sql /
+// NNNN_name.down.sql), которые database применяет по таблице schema_migrations.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
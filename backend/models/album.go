@@ -6,6 +6,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// AlbumStatus represents moderation status of an album
+type AlbumStatus string
+
+const (
+	AlbumStatusPending  AlbumStatus = "pending"
+	AlbumStatusApproved AlbumStatus = "approved"
+	AlbumStatusRejected AlbumStatus = "rejected"
+)
+
 // Album represents a music album
 type Album struct {
 	ID                          uint           `json:"id" gorm:"primaryKey"`
@@ -16,12 +25,22 @@ type Album struct {
 	ReleaseDate                 *time.Time     `json:"release_date"`
 	Description                 string         `json:"description" gorm:"type:text"`
 	AverageRating               float64        `json:"average_rating" gorm:"default:0"`
+	Status                      AlbumStatus    `json:"status" gorm:"type:varchar(20);not null;default:'approved';index"` // Альбомы от обычных пользователей проходят модерацию
+	SubmittedBy                 *uint          `json:"submitted_by"`
+	ModeratedBy                 *uint          `json:"moderated_by"`
+	ModeratedAt                 *time.Time     `json:"moderated_at"`
 	AverageRatingRhymes         float64        `json:"average_rating_rhymes,omitempty" gorm:"-"`
 	AverageRatingStructure      float64        `json:"average_rating_structure,omitempty" gorm:"-"`
 	AverageRatingImplementation float64        `json:"average_rating_implementation,omitempty" gorm:"-"`
 	AverageRatingIndividuality  float64        `json:"average_rating_individuality,omitempty" gorm:"-"`
 	AverageAtmosphereRating     float64        `json:"average_atmosphere_rating,omitempty" gorm:"-"`
 	ApprovedReviewsCount        int64          `json:"approved_reviews_count,omitempty" gorm:"-"`
+	LikedAt                     *time.Time     `json:"liked_at,omitempty" gorm:"-"`       // Заполняется в библиотеке лайков пользователя
+	TrackCount                  *int           `json:"track_count,omitempty" gorm:"-"`    // Заполняется в карточке альбома, см. SummarizeTracks
+	TotalDuration               *int           `json:"total_duration,omitempty" gorm:"-"` // Сумма длительностей треков в секундах
+	Rank                        *AlbumRank     `json:"rank,omitempty" gorm:"-"`           // Заполняется в карточке альбома
+	ReviewedByMe                *bool          `json:"reviewed_by_me,omitempty" gorm:"-"` // В карточке альбома для авторизованного пользователя
+	MyReviewID                  *uint          `json:"my_review_id,omitempty" gorm:"-"`   // ID его рецензии, если она есть
 	CreatedAt                   time.Time      `json:"created_at"`
 	UpdatedAt                   time.Time      `json:"updated_at"`
 	DeletedAt                   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -33,7 +52,30 @@ type Album struct {
 	Likes   []AlbumLike `json:"likes,omitempty" gorm:"foreignKey:AlbumID"`
 }
 
+// AlbumRank is an album's place by average_rating among approved rated albums:
+// «#3 из 40 в жанре». Альбомы с равной оценкой делят место.
+type AlbumRank struct {
+	Overall      int64 `json:"overall"`
+	OverallTotal int64 `json:"overall_total"`
+	InGenre      int64 `json:"in_genre"`
+	GenreTotal   int64 `json:"genre_total"`
+}
+
 // TableName specifies the table name for Album
 func (Album) TableName() string {
 	return "albums"
 }
+
+// SummarizeTracks fills TrackCount and TotalDuration from the preloaded Tracks.
+// Треки без длительности считаются, но в сумму не попадают.
+func (a *Album) SummarizeTracks() {
+	count := len(a.Tracks)
+	total := 0
+	for _, track := range a.Tracks {
+		if track.Duration != nil {
+			total += *track.Duration
+		}
+	}
+	a.TrackCount = &count
+	a.TotalDuration = &total
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+// Треки без длительности входят в track_count, но не в total_duration.
+func TestSummarizeTracksWithNilDurations(t *testing.T) {
+	duration := func(seconds int) *int { return &seconds }
+	album := Album{Tracks: []Track{
+		{Duration: duration(180)},
+		{Duration: nil},
+		{Duration: duration(245)},
+		{Duration: nil},
+	}}
+	album.SummarizeTracks()
+	if album.TrackCount == nil || *album.TrackCount != 4 {
+		t.Errorf("track_count = %v, want 4", album.TrackCount)
+	}
+	if album.TotalDuration == nil || *album.TotalDuration != 425 {
+		t.Errorf("total_duration = %v, want 425", album.TotalDuration)
+	}
+
+	empty := Album{}
+	empty.SummarizeTracks()
+	if *empty.TrackCount != 0 || *empty.TotalDuration != 0 {
+		t.Errorf("no tracks: %d tracks, %d seconds", *empty.TrackCount, *empty.TotalDuration)
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// AuditLog records an admin operation (no soft delete): кто, что и над каким
+// объектом сделал. Пишется в той же транзакции, что и сама операция, поэтому
+// запись есть ровно тогда, когда операция применилась.
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ActorID    *uint     `json:"actor_id" gorm:"index"`       // Администратор; nil, если пользователь удалён
+	Action     string    `json:"action" gorm:"not null"`      // Например, genre.merge
+	EntityType string    `json:"entity_type" gorm:"not null"` // Таблица объекта: genres, albums, ...
+	EntityID   uint      `json:"entity_id" gorm:"not null"`
+	Details    string    `json:"details" gorm:"type:jsonb;not null;default:'{}'"` // JSON с параметрами и итогом операции
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
@@ -14,6 +14,8 @@ type Genre struct {
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	AlbumCount  *int64         `json:"album_count,omitempty" gorm:"-"` // В списке жанров: неудалённые альбомы жанра
+	TrackCount  *int64         `json:"track_count,omitempty" gorm:"-"` // В списке жанров: неудалённые треки с этим жанром
 
 	// Relationships
 	Albums []Album `json:"albums,omitempty" gorm:"foreignKey:GenreID"`
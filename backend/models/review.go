@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,21 +18,26 @@ const (
 
 // Review represents a review of an album or track
 type Review struct {
-	ID                   uint           `json:"id" gorm:"primaryKey"`
+	ID                   uint           `json:"id" gorm:"primaryKey;index:idx_reviews_created_id,priority:2"`
 	UserID               uint           `json:"user_id" gorm:"not null"`
 	AlbumID              *uint          `json:"album_id" gorm:"default:null"` // Nullable - either album_id or track_id must be set
 	TrackID              *uint          `json:"track_id" gorm:"default:null"` // Nullable - either album_id or track_id must be set
 	Text                 string         `json:"text" gorm:"type:text"`
+	HasSpoilers          bool           `json:"has_spoilers" gorm:"not null;default:false"`                   // Фронт по умолчанию размывает такие рецензии
+	ContentWarning       string         `json:"content_warning" gorm:"type:varchar(200);not null;default:''"` // Необязательное пояснение: спойлеры, NSFW и т.п.
+	HasListened          bool           `json:"has_listened" gorm:"not null;default:false"`                   // Автор слушал трек (для альбома — хотя бы один трек) на сайте; ставит сервер
 	RatingRhymes         int            `json:"rating_rhymes" gorm:"not null;check:rating_rhymes >= 1 AND rating_rhymes <= 10"`
 	RatingStructure      int            `json:"rating_structure" gorm:"not null;check:rating_structure >= 1 AND rating_structure <= 10"`
 	RatingImplementation int            `json:"rating_implementation" gorm:"not null;check:rating_implementation >= 1 AND rating_implementation <= 10"`
 	RatingIndividuality  int            `json:"rating_individuality" gorm:"not null;check:rating_individuality >= 1 AND rating_individuality <= 10"`
-	AtmosphereMultiplier float64        `json:"atmosphere_multiplier" gorm:"not null;check:atmosphere_multiplier >= 1.0000 AND atmosphere_multiplier <= 1.6072"`
+	AtmosphereRating     int            `json:"atmosphere_rating" gorm:"not null;default:0"` // Исходная оценка 1–10: по ней множитель пересчитывается при смене формулы
+	AtmosphereMultiplier float64        `json:"atmosphere_multiplier" gorm:"not null;check:atmosphere_multiplier >= 1.0000"`
 	FinalScore           float64        `json:"final_score" gorm:"not null"`
 	Status               ReviewStatus   `json:"status" gorm:"default:'pending'"`
 	ModeratedBy          *uint          `json:"moderated_by"`
 	ModeratedAt          *time.Time     `json:"moderated_at"`
-	CreatedAt            time.Time      `json:"created_at"`
+	RejectionReason      string         `json:"rejection_reason,omitempty" gorm:"type:text;not null;default:''"` // Причина отклонения, видна автору в /me/reviews
+	CreatedAt            time.Time      `json:"created_at" gorm:"index:idx_reviews_created_id,priority:1"`
 	UpdatedAt            time.Time      `json:"updated_at"`
 	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
 
@@ -39,11 +45,13 @@ type Review struct {
 	User      User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Album     *Album       `json:"album,omitempty" gorm:"foreignKey:AlbumID"`
 	Track     *Track       `json:"track,omitempty" gorm:"foreignKey:TrackID"`
-	Moderator *User        `json:"moderator,omitempty" gorm:"foreignKey:ModeratedBy"`
+	Moderator *User        `json:"-" gorm:"foreignKey:ModeratedBy"` // В JSON — только moderator_username, см. MarshalJSON
 	Likes     []ReviewLike `json:"likes,omitempty" gorm:"foreignKey:ReviewID"`
 
 	HasArtistMark       bool     `json:"has_artist_mark" gorm:"-"`
 	ArtistMarkUsernames []string `json:"artist_mark_usernames,omitempty" gorm:"-"`
+	// Заполняется в ответах на создание и получение рецензии (FillScoreBreakdown)
+	ScoreBreakdown *ScoreBreakdown `json:"score_breakdown,omitempty" gorm:"-"`
 }
 
 // TableName specifies the table name for Review
@@ -51,11 +59,37 @@ func (Review) TableName() string {
 	return "reviews"
 }
 
+// MarshalJSON adds moderator_username when Moderator is preloaded: фронту для
+// «одобрил X» нужен только логин, а не весь профиль модератора.
+func (r Review) MarshalJSON() ([]byte, error) {
+	type reviewAlias Review
+	var moderatorUsername string
+	if r.Moderator != nil {
+		moderatorUsername = r.Moderator.Username
+	}
+	return json.Marshal(struct {
+		reviewAlias
+		ModeratorUsername string `json:"moderator_username,omitempty"`
+	}{
+		reviewAlias:       reviewAlias(r),
+		ModeratorUsername: moderatorUsername,
+	})
+}
+
 // CalculateFinalScore calculates the final score based on the rating formula
-// Formula: (Рифмы+Структура+Реализация+Индивидуальность) × 1.4 × Атмосфера/Вайб
+// Formula: (Рифмы+Структура+Реализация+Индивидуальность) × вес × Атмосфера/Вайб,
+// вес и диапазон множителя берутся из scoring (по умолчанию 1.4 и 1.0–1.6072).
 // Result is rounded to the nearest integer
-func (r *Review) CalculateFinalScore() {
-	baseScore := float64(r.RatingRhymes + r.RatingStructure + r.RatingImplementation + r.RatingIndividuality)
-	score := baseScore * 1.4 * r.AtmosphereMultiplier
-	r.FinalScore = float64(int(score + 0.5)) // Round to nearest integer
+func (r *Review) CalculateFinalScore(scoring ScoringConfig) {
+	r.FinalScore = scoring.FinalScore(r.baseSum(), r.AtmosphereMultiplier)
+}
+
+// FillScoreBreakdown sets ScoreBreakdown by the same formula as CalculateFinalScore.
+func (r *Review) FillScoreBreakdown(scoring ScoringConfig) {
+	breakdown := scoring.Breakdown(r.baseSum(), r.AtmosphereRating, r.AtmosphereMultiplier)
+	r.ScoreBreakdown = &breakdown
+}
+
+func (r *Review) baseSum() int {
+	return r.RatingRhymes + r.RatingStructure + r.RatingImplementation + r.RatingIndividuality
 }
@@ -0,0 +1,78 @@
+package models
+
+import "math"
+
+// Значения формулы по умолчанию: при всех десятках итог ровно 90.
+const (
+	DefaultScoreBaseWeight    = 1.4
+	DefaultScoreAtmosphereMax = 1.6072
+)
+
+// ScoringConfig holds tunable parameters of the review score formula:
+// (Рифмы+Структура+Реализация+Индивидуальность) × BaseWeight × Атмосфера,
+// где атмосфера 1–10 линейно переводится в множитель 1.0–AtmosphereMax.
+type ScoringConfig struct {
+	BaseWeight    float64 `json:"base_weight"`
+	AtmosphereMax float64 `json:"atmosphere_max"`
+}
+
+// DefaultScoring returns the formula used when SCORE_BASE_WEIGHT and
+// SCORE_ATMOSPHERE_MAX are not set. Рабочие значения собирает config.Load и
+// передаёт контроллерам и сидам; после смены формулы старые рецензии
+// пересчитывает admin-эндпоинт.
+func DefaultScoring() ScoringConfig {
+	return ScoringConfig{BaseWeight: DefaultScoreBaseWeight, AtmosphereMax: DefaultScoreAtmosphereMax}
+}
+
+func (s ScoringConfig) atmosphereStep() float64 {
+	return (s.AtmosphereMax - 1.0) / 9.0
+}
+
+// AtmosphereMultiplier converts atmosphere rating (1-10) to multiplier (1.0-AtmosphereMax).
+// Единственное место перевода: результат зажат в допустимый диапазон, а для
+// 10 возвращается ровно AtmosphereMax — иначе погрешность float может дать
+// значение чуть выше границы, и ValidateAtmosphereMultiplier его отклонит.
+func (s ScoringConfig) AtmosphereMultiplier(rating int) float64 {
+	if rating >= 10 {
+		return s.AtmosphereMax
+	}
+	if rating <= 1 {
+		return 1.0
+	}
+	multiplier := 1.0 + float64(rating-1)*s.atmosphereStep()
+	return math.Min(math.Max(multiplier, 1.0), s.AtmosphereMax)
+}
+
+// AtmosphereRating converts a multiplier (or an average of multipliers) back to the 1-10 scale.
+func (s ScoringConfig) AtmosphereRating(multiplier float64) float64 {
+	return 1 + (multiplier-1.0)/s.atmosphereStep()
+}
+
+// FinalScore applies the formula and rounds to the nearest integer.
+func (s ScoringConfig) FinalScore(baseSum int, atmosphereMultiplier float64) float64 {
+	score := float64(baseSum) * s.BaseWeight * atmosphereMultiplier
+	return float64(int(score + 0.5))
+}
+
+// ScoreBreakdown explains how a review's final score was obtained; отдаётся
+// в ответах рецензий, чтобы фронтенду не приходилось знать формулу.
+type ScoreBreakdown struct {
+	BaseSum              int     `json:"base_sum"`     // Рифмы + Структура + Реализация + Индивидуальность
+	BaseWeight           float64 `json:"base_weight"`  // Вес суммы из текущей формулы
+	WeightedSum          float64 `json:"weighted_sum"` // BaseSum × BaseWeight
+	AtmosphereRating     int     `json:"atmosphere_rating"`
+	AtmosphereMultiplier float64 `json:"atmosphere_multiplier"`
+	FinalScore           float64 `json:"final_score"` // WeightedSum × множитель, округлено
+}
+
+// Breakdown computes the score breakdown with this config.
+func (s ScoringConfig) Breakdown(baseSum, atmosphereRating int, atmosphereMultiplier float64) ScoreBreakdown {
+	return ScoreBreakdown{
+		BaseSum:              baseSum,
+		BaseWeight:           s.BaseWeight,
+		WeightedSum:          float64(baseSum) * s.BaseWeight,
+		AtmosphereRating:     atmosphereRating,
+		AtmosphereMultiplier: atmosphereMultiplier,
+		FinalScore:           s.FinalScore(baseSum, atmosphereMultiplier),
+	}
+}
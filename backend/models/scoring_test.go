@@ -0,0 +1,25 @@
+package models
+
+import "testing"
+
+// Вес суммы оценок берётся из ScoringConfig: другой вес — другая итоговая оценка.
+func TestCalculateFinalScoreUsesBaseWeight(t *testing.T) {
+	review := Review{RatingRhymes: 8, RatingStructure: 7, RatingImplementation: 9, RatingIndividuality: 6}
+	review.AtmosphereMultiplier = DefaultScoring().AtmosphereMultiplier(5)
+
+	review.CalculateFinalScore(DefaultScoring())
+	defaultScore := review.FinalScore
+	// 30 × 1.4 × (1 + 4 × 0.6072/9) ≈ 53.33
+	if defaultScore != 53 {
+		t.Errorf("default formula: final score %v, want 53", defaultScore)
+	}
+
+	review.CalculateFinalScore(ScoringConfig{BaseWeight: 2, AtmosphereMax: DefaultScoreAtmosphereMax})
+	// 30 × 2 × 1.26987 ≈ 76.19
+	if review.FinalScore != 76 {
+		t.Errorf("base weight 2: final score %v, want 76", review.FinalScore)
+	}
+	if review.FinalScore == defaultScore {
+		t.Error("changed base weight did not change the final score")
+	}
+}
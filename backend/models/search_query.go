@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// SearchQuery is a logged search (no soft delete). Запрос хранится
+// нормализованным (lowercase, без лишних пробелов), чтобы группировка по нему
+// давала популярные запросы и запросы без результатов.
+type SearchQuery struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Query        string    `json:"query" gorm:"not null;index"`
+	ResultsCount int       `json:"results_count" gorm:"not null;default:0"`
+	UserID       *uint     `json:"user_id" gorm:"default:null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+func (SearchQuery) TableName() string {
+	return "search_queries"
+}
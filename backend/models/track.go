@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,6 +17,7 @@ type Track struct {
 	Duration                    *int           `json:"duration"` // Duration in seconds
 	TrackNumber                 *int           `json:"track_number"`
 	CoverImagePath              string         `json:"cover_image_path"`
+	Lyrics                      string         `json:"-" gorm:"type:text;not null;default:''"` // Отдаётся отдельно через /tracks/:id/lyrics
 	AverageRating               float64        `json:"average_rating" gorm:"default:0"`
 	AverageRatingRhymes         float64        `json:"average_rating_rhymes,omitempty" gorm:"-"`
 	AverageRatingStructure      float64        `json:"average_rating_structure,omitempty" gorm:"-"`
@@ -21,6 +25,15 @@ type Track struct {
 	AverageRatingIndividuality  float64        `json:"average_rating_individuality,omitempty" gorm:"-"`
 	AverageAtmosphereRating     float64        `json:"average_atmosphere_rating,omitempty" gorm:"-"`
 	ApprovedReviewsCount        int64          `json:"approved_reviews_count,omitempty" gorm:"-"`
+	Listens7d                   int64          `json:"listens_7d" gorm:"-"`               // Прослушивания за последние 7 дней, агрегат по track_listens
+	LikedAt                     *time.Time     `json:"liked_at,omitempty" gorm:"-"`       // Заполняется в библиотеке лайков пользователя
+	ReviewCount                 *int64         `json:"review_count,omitempty" gorm:"-"`   // С include=reviews в GetTrack и в топе жанра
+	RecentLikes                 *int64         `json:"recent_likes,omitempty" gorm:"-"`   // Лайки за 7 дней в обзоре жанра
+	LatestReviews               []Review       `json:"latest_reviews,omitempty" gorm:"-"` // Только с include=reviews в GetTrack
+	PrevTrack                   *TrackStub     `json:"prev_track,omitempty" gorm:"-"`
+	NextTrack                   *TrackStub     `json:"next_track,omitempty" gorm:"-"`
+	ReviewedByMe                *bool          `json:"reviewed_by_me,omitempty" gorm:"-"` // В карточке трека для авторизованного пользователя
+	MyReviewID                  *uint          `json:"my_review_id,omitempty" gorm:"-"`   // ID его рецензии, если она есть
 	CreatedAt                   time.Time      `json:"created_at"`
 	UpdatedAt                   time.Time      `json:"updated_at"`
 	DeletedAt                   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -32,7 +45,41 @@ type Track struct {
 	Reviews []Review    `json:"reviews,omitempty" gorm:"foreignKey:TrackID"`
 }
 
+// TrackStub is a short reference to a sibling track used for prev/next navigation
+type TrackStub struct {
+	ID          uint   `json:"id"`
+	Title       string `json:"title"`
+	TrackNumber *int   `json:"track_number"`
+}
+
 // TableName specifies the table name for Track
 func (Track) TableName() string {
 	return "tracks"
 }
+
+// MarshalJSON adds computed fields to track JSON: duration_formatted ("4:27")
+// and has_lyrics (сам текст песни в списки не отдаётся).
+func (t Track) MarshalJSON() ([]byte, error) {
+	type trackAlias Track
+	return json.Marshal(struct {
+		trackAlias
+		DurationFormatted string `json:"duration_formatted,omitempty"`
+		HasLyrics         bool   `json:"has_lyrics"`
+	}{
+		trackAlias:        trackAlias(t),
+		DurationFormatted: FormatDuration(t.Duration),
+		HasLyrics:         strings.TrimSpace(t.Lyrics) != "",
+	})
+}
+
+// FormatDuration renders seconds as "m:ss" (or "h:mm:ss" for an hour and longer).
+func FormatDuration(seconds *int) string {
+	if seconds == nil || *seconds <= 0 {
+		return ""
+	}
+	h, m, s := *seconds/3600, *seconds%3600/60, *seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
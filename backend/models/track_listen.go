@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TrackListen is a daily listen counter: one row per track per day.
+// Счётчик увеличивается upsert'ом, поэтому строк не больше, чем дней × треков.
+type TrackListen struct {
+	TrackID uint      `json:"track_id" gorm:"primaryKey;autoIncrement:false"`
+	Day     time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Count   int64     `json:"count" gorm:"not null;default:0"`
+}
+
+func (TrackListen) TableName() string {
+	return "track_listens"
+}
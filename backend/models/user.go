@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,8 +11,8 @@ import (
 type User struct {
 	ID                uint           `json:"id" gorm:"primaryKey"`
 	Username          string         `json:"username" gorm:"uniqueIndex;not null"`
-	Email             string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password          string         `json:"-" gorm:"not null"` // Password hash, not exposed in JSON
+	Email             string         `json:"email,omitempty" gorm:"uniqueIndex;not null"` // В JSON только при EmailPublic или ShowEmail, см. MarshalJSON
+	Password          string         `json:"-" gorm:"not null"`                           // Password hash, not exposed in JSON
 	AvatarPath        string         `json:"avatar_path" gorm:"type:text"`
 	Bio               string         `json:"bio" gorm:"type:text"`
 	SocialLinks       string         `json:"social_links" gorm:"type:jsonb;default:'{}'"` // JSON: {"vk": "", "telegram": "", "instagram": ""}
@@ -22,6 +23,12 @@ type User struct {
 	PreferencesManual bool           `json:"preferences_manual" gorm:"default:false"`
 	IsVerifiedArtist  bool           `json:"is_verified_artist" gorm:"default:false"`
 	ArtistName        string         `json:"artist_name,omitempty" gorm:"type:text;index"`
+	LikesPrivate      bool           `json:"likes_private" gorm:"not null;default:false"`
+	EmailPublic       bool           `json:"email_public" gorm:"not null;default:false"`
+	ShowEmail         bool           `json:"-" gorm:"-"`                                                   // Ответ владельцу или админу: email отдаётся независимо от EmailPublic
+	PendingEmail      string         `json:"pending_email,omitempty" gorm:"type:text;not null;default:''"` // Новый email до подтверждения по ссылке
+	EmailTokenHash    string         `json:"-" gorm:"type:varchar(64);not null;default:'';index"`
+	EmailTokenExpires *time.Time     `json:"-"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
@@ -34,3 +41,18 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+// MarshalJSON hides the email unless the user made it public or the response
+// is for the owner/admin (ShowEmail). Пользователь вкладывается в рецензии и
+// лайки, поэтому без этого email утекал бы в любые публичные списки.
+func (u User) MarshalJSON() ([]byte, error) {
+	type userAlias User
+	alias := userAlias(u)
+	if !u.EmailPublic && !u.ShowEmail {
+		alias.Email = ""
+	}
+	if !u.ShowEmail {
+		alias.PendingEmail = ""
+	}
+	return json.Marshal(alias)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserTrackListen marks that a user played a track at least once (one row per
+// pair, без счётчика — общий счётчик прослушиваний живёт в TrackListen). По нему
+// рецензия получает отметку has_listened.
+type UserTrackListen struct {
+	UserID          uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	TrackID         uint      `json:"track_id" gorm:"primaryKey;autoIncrement:false;index"`
+	FirstListenedAt time.Time `json:"first_listened_at" gorm:"not null"`
+}
+
+func (UserTrackListen) TableName() string {
+	return "user_track_listens"
+}
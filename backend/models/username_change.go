@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// UsernameChange records a rename (no soft delete). По истории считаются
+// кулдаун смены имени и резерв старых имён от захвата другими пользователями.
+type UsernameChange struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	OldUsername string    `json:"old_username" gorm:"not null"`
+	NewUsername string    `json:"new_username" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (UsernameChange) TableName() string {
+	return "username_history"
+}
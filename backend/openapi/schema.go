@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the subset of the OpenAPI 3.0 Schema Object used by this API.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// Простые схемы для ответов, которые хендлеры собирают через gin.H.
+func String() *Schema  { return &Schema{Type: "string"} }
+func Integer() *Schema { return &Schema{Type: "integer"} }
+func Number() *Schema  { return &Schema{Type: "number"} }
+func Boolean() *Schema { return &Schema{Type: "boolean"} }
+
+// Object describes a JSON object; значения — *Schema или образец Go-типа
+// (models.Album{}, []models.Track{}), схема которого строится рефлексией.
+type Object map[string]interface{}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	schemaPtrType  = reflect.TypeOf(&Schema{})
+	objectType     = reflect.TypeOf(Object{})
+)
+
+// generator строит схемы по Go-типам. Именованные структуры попадают в
+// components.schemas и подставляются ссылкой: так модели со взаимными связями
+// (Review → User → Reviews) не зацикливаются.
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	computed   map[reflect.Type]map[string]*Schema
+}
+
+func newGenerator() *generator {
+	return &generator{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+		computed:   map[reflect.Type]map[string]*Schema{},
+	}
+}
+
+// schemaFor returns the schema of a sample value: *Schema и Object — как есть,
+// остальное — по типу значения.
+func (g *generator) schemaFor(sample interface{}) *Schema {
+	switch v := sample.(type) {
+	case nil:
+		return nil
+	case *Schema:
+		return v
+	case Object:
+		return g.object(v)
+	}
+	return g.typeSchema(reflect.TypeOf(sample))
+}
+
+func (g *generator) object(fields Object) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for name, sample := range fields {
+		schema.Properties[name] = g.schemaFor(sample)
+	}
+	return schema
+}
+
+func (g *generator) typeSchema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	case schemaPtrType, objectType:
+		return &Schema{Type: "object"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := g.typeSchema(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Integer()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return Number()
+	case reflect.String:
+		return String()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.register(t)}
+	}
+	// interface{} и прочее — любое значение
+	return &Schema{}
+}
+
+// register adds a named struct to components and returns its component name.
+func (g *generator) register(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.components[name]; taken {
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+	}
+	g.names[t] = name
+	g.components[name] = &Schema{} // заглушка на время рекурсии
+	g.components[name] = g.structSchema(t)
+	return name
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, t)
+	for name, field := range g.computed[t] {
+		schema.Properties[name] = field
+	}
+	return schema
+}
+
+func (g *generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // неэкспортируемое поле
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.typeSchema(field.Type)
+		rules := strings.Split(field.Tag.Get("binding"), ",")
+		for _, rule := range rules {
+			if rule == "required" {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		if property.Ref == "" {
+			applyBindingLimits(property, rules)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyBindingLimits переносит min/max из тега binding: для чисел это границы
+// значения, для строк — длины.
+func applyBindingLimits(schema *Schema, rules []string) {
+	for _, rule := range rules {
+		key, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			if key == "email" && schema.Type == "string" {
+				schema.Format = "email"
+			}
+			continue
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case schema.Type == "string" && key == "min":
+			length := int(n)
+			schema.MinLength = &length
+		case schema.Type == "string" && key == "max":
+			length := int(n)
+			schema.MaxLength = &length
+		case (schema.Type == "integer" || schema.Type == "number") && key == "min":
+			schema.Minimum = &n
+		case (schema.Type == "integer" || schema.Type == "number") && key == "max":
+			schema.Maximum = &n
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Package openapi собирает OpenAPI 3.0 спецификацию API из кода: пути берутся
+// из зарегистрированных в gin маршрутов, описания операций — из таблицы в
+// routes, схемы тел — рефлексией по моделям и структурам запросов. Поэтому
+// новый маршрут попадает в спецификацию сразу, даже если его забыли описать.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Auth is the authentication an operation requires.
+type Auth int
+
+const (
+	Public   Auth = iota // без авторизации
+	Optional             // работает и без токена, с токеном добавляет персональные поля
+	User                 // нужен токен сессии
+	Admin                // нужен токен администратора
+)
+
+// Param is a query parameter.
+type Param struct {
+	Name        string
+	Type        string // string, integer, boolean
+	Description string
+}
+
+// Operation describes one route. Body и Response — образцы Go-типов
+// (models.Review{}, []models.Genre{}), *Schema или Object.
+type Operation struct {
+	Summary     string
+	Auth        Auth
+	Query       []Param
+	Body        interface{}
+	Response    interface{}
+	Status      int    // код успешного ответа, по умолчанию 200
+	ContentType string // для ответов не в JSON (CSV, RSS)
+	File        string // поле multipart/form-data с загружаемым файлом, вместо Body
+}
+
+// Computed adds fields that a type's MarshalJSON writes beyond its struct fields.
+type Computed struct {
+	Sample interface{}
+	Fields map[string]*Schema
+}
+
+// Spec is everything Build needs besides the registered routes.
+type Spec struct {
+	Title      string
+	Version    string
+	Prefix     string               // например /api/v1; пути в Operations — без него
+	Operations map[string]Operation // по ключу "METHOD /path", путь как в gin
+	Computed   []Computed
+	Error      interface{} // образец конверта ошибки, utils.ErrorResponse{}
+}
+
+// pathParamRegex находит параметры пути gin: :id, *filepath.
+var pathParamRegex = regexp.MustCompile(`[:*](\w+)`)
+
+// Build assembles the document for the routes under s.Prefix. Маршруты без
+// описания всё равно попадают в спецификацию и возвращаются в undocumented.
+func (s Spec) Build(routes gin.RoutesInfo) (map[string]interface{}, []string) {
+	prefix := s.Prefix
+	g := newGenerator()
+	for _, c := range s.Computed {
+		g.computed[reflect.TypeOf(c.Sample)] = c.Fields
+	}
+	errorRef := map[string]interface{}{"$ref": "#/components/responses/Error"}
+
+	var undocumented []string
+	paths := map[string]map[string]interface{}{}
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, prefix+"/") {
+			continue
+		}
+		path := strings.TrimPrefix(route.Path, prefix)
+		key := route.Method + " " + path
+		op, ok := s.Operations[key]
+		if !ok {
+			undocumented = append(undocumented, key)
+			op = Operation{Summary: "Не описан в таблице операций"}
+		}
+
+		operation := map[string]interface{}{
+			"summary":     op.Summary,
+			"operationId": operationID(route.Method, path),
+			"tags":        []string{strings.Split(strings.TrimPrefix(path, "/"), "/")[0]},
+		}
+
+		var params []map[string]interface{}
+		for _, match := range pathParamRegex.FindAllStringSubmatch(path, -1) {
+			params = append(params, map[string]interface{}{
+				"name": match[1], "in": "path", "required": true, "schema": String(),
+			})
+		}
+		for _, p := range op.Query {
+			params = append(params, map[string]interface{}{
+				"name": p.Name, "in": "query", "description": p.Description, "schema": &Schema{Type: p.Type},
+			})
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+
+		if op.Body != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": g.schemaFor(op.Body)},
+				},
+			}
+		}
+		if op.File != "" {
+			form := &Schema{Type: "object", Required: []string{op.File}, Properties: map[string]*Schema{
+				op.File: {Type: "string", Format: "binary"},
+			}}
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"multipart/form-data": map[string]interface{}{"schema": form},
+				},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		if op.Response != nil {
+			contentType := op.ContentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			success["content"] = map[string]interface{}{
+				contentType: map[string]interface{}{"schema": g.schemaFor(op.Response)},
+			}
+		}
+		responses := map[string]interface{}{
+			strconv.Itoa(status): success,
+			"500":                errorRef,
+		}
+		if op.Body != nil || op.File != "" || len(op.Query) > 0 || len(params) > 0 {
+			responses["400"] = errorRef
+		}
+		if strings.Contains(path, ":") {
+			responses["404"] = errorRef
+		}
+
+		switch op.Auth {
+		case Public:
+			operation["security"] = []interface{}{}
+		case Optional:
+			operation["security"] = []interface{}{map[string]interface{}{}, map[string]interface{}{"bearerAuth": []string{}}}
+		case User, Admin:
+			operation["security"] = []interface{}{map[string]interface{}{"bearerAuth": []string{}}}
+			responses["401"] = errorRef
+		}
+		if op.Auth == Admin {
+			operation["x-admin-only"] = true
+			responses["403"] = errorRef
+		}
+		operation["responses"] = responses
+
+		openAPIPath := pathParamRegex.ReplaceAllString(path, "{$1}")
+		if paths[openAPIPath] == nil {
+			paths[openAPIPath] = map[string]interface{}{}
+		}
+		paths[openAPIPath][strings.ToLower(route.Method)] = operation
+	}
+	sort.Strings(undocumented)
+
+	// Конверт ошибки один на все ответы 4xx/5xx.
+	errorSchema := g.schemaFor(s.Error)
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": s.Title, "version": s.Version},
+		"servers": []map[string]interface{}{{"url": prefix}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": g.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "description": "Токен сессии из /auth/login или /auth/register"},
+			},
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "Ошибка в стандартном формате",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": errorSchema},
+					},
+				},
+			},
+		},
+	}, undocumented
+}
+
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '.' || r == ':' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"context"
+	"log/slog"
+	"music-review-site/backend/database"
+	"music-review-site/backend/middleware"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readyPingTimeout ограничивает проверку БД: под нагрузкой лучше быстро ответить
+// 503, чем держать запрос оркестратора.
+const readyPingTimeout = 2 * time.Second
+
+// healthCountTables — таблицы, размер которых отдаёт ?verbose=true (только админу).
+var healthCountTables = []string{"users", "albums", "tracks", "reviews", "genres"}
+
+// dependencyStatus is the state of one dependency in the readiness response.
+type dependencyStatus struct {
+	Status    string  `json:"status"` // up | down
+	LatencyMs float64 `json:"latency_ms"`
+	Error     error   `json:"-"` // Только в лог: текст драйвера выдаёт адрес и пользователя БД
+}
+
+// liveHandler отвечает 200, пока процесс обслуживает запросы; зависимости не проверяет.
+func liveHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyHandler отвечает 200, только если БД доступна; иначе 503 в конверте ошибки
+// с состоянием зависимостей, чтобы балансировщик снимал инстанс без БД. Причина
+// недоступности пишется в лог, наружу уходит только общее сообщение.
+// ?verbose=true добавляет версию миграций, число строк в основных таблицах и
+// счётчик паник — только администратору, остальным параметр ничего не меняет.
+func readyHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dbStatus := pingDatabase(c.Request.Context(), db)
+		checks := gin.H{"database": dbStatus}
+
+		if dbStatus.Status != "up" {
+			middleware.Logger(c).Error("health: database is unreachable", "error", dbStatus.Error)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":   http.StatusText(http.StatusServiceUnavailable),
+				"message": "database is unreachable",
+				"code":    http.StatusServiceUnavailable,
+				"checks":  checks,
+			})
+			return
+		}
+
+		response := gin.H{"status": "ok", "checks": checks}
+		if user, ok := middleware.GetUserFromContext(c); ok && user.IsAdmin && c.Query("verbose") == "true" {
+			response["details"] = healthDetails(c.Request.Context(), db)
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func pingDatabase(parent context.Context, db *gorm.DB) dependencyStatus {
+	start := time.Now()
+	sqlDB, err := db.DB()
+	if err == nil {
+		ctx, cancel := context.WithTimeout(parent, readyPingTimeout)
+		defer cancel()
+		err = sqlDB.PingContext(ctx)
+	}
+	status := dependencyStatus{Status: "up", LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		status.Status = "down"
+		status.Error = err
+	}
+	return status
+}
+
+// healthDetails собирает данные для отладки; ошибки отдельных запросов пишутся
+// в лог, а поле остаётся пустым — на готовность инстанса они не влияют.
+func healthDetails(parent context.Context, db *gorm.DB) gin.H {
+	ctx, cancel := context.WithTimeout(parent, readyPingTimeout)
+	defer cancel()
+	tx := db.WithContext(ctx)
+
+	details := gin.H{"panics_total": middleware.PanicsTotal.Value()}
+	if version, err := database.CurrentMigrationVersion(tx); err != nil {
+		slog.Warn("health: failed to read migration version", "error", err)
+	} else {
+		details["migration_version"] = version
+	}
+
+	counts := gin.H{}
+	for _, table := range healthCountTables {
+		var count int64
+		if err := tx.Table(table).Count(&count).Error; err != nil {
+			slog.Warn("health: failed to count rows", "table", table, "error", err)
+			continue
+		}
+		counts[table] = count
+	}
+	details["table_counts"] = counts
+	return details
+}
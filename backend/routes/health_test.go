@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// unreachableDB — соединение с портом, где никого нет: ping падает сразу.
+func unreachableDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1 user=health password=secret dbname=health sslmode=disable connect_timeout=1"),
+		&gorm.Config{DisableAutomaticPing: true, Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return db
+}
+
+func TestReadyHidesDriverError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/health/ready", readyHandler(unreachableDB(t)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready?verbose=true", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("want 503, got %d %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	for _, leak := range []string{"127.0.0.1", "health", "dial", "refused"} {
+		if strings.Contains(body, leak) {
+			t.Errorf("503 body leaks %q: %s", leak, body)
+		}
+	}
+	var resp struct {
+		Message string      `json:"message"`
+		Details interface{} `json:"details"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "database is unreachable" || resp.Details != nil {
+		t.Errorf("unexpected body: %s", body)
+	}
+}
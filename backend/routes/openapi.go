@@ -0,0 +1,316 @@
+package routes
+
+import (
+	"encoding/json"
+	"log/slog"
+	"music-review-site/backend/controllers"
+	"music-review-site/backend/models"
+	"music-review-site/backend/openapi"
+	"music-review-site/backend/utils"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Параметры запросов, повторяющиеся в нескольких операциях.
+var (
+	pageParams = []openapi.Param{
+		{Name: "page", Type: "integer", Description: "номер страницы, с 1"},
+		{Name: "page_size", Type: "integer", Description: "размер страницы, по умолчанию 20, не больше PAGE_SIZE_MAX"},
+	}
+	sortParams = []openapi.Param{
+		{Name: "sort_by", Type: "string", Description: "поле сортировки из белого списка"},
+		{Name: "sort_order", Type: "string", Description: "asc или desc"},
+	}
+	limitParam = openapi.Param{Name: "limit", Type: "integer", Description: "сколько записей вернуть"}
+	idsParam   = openapi.Param{Name: "ids", Type: "string", Description: "ID через запятую"}
+	searchTerm = openapi.Param{Name: "q", Type: "string", Description: "поисковый запрос"}
+)
+
+func params(groups ...[]openapi.Param) []openapi.Param {
+	var all []openapi.Param
+	for _, group := range groups {
+		all = append(all, group...)
+	}
+	return all
+}
+
+// page описывает страницу списка (utils.Paginated): элементы под ключом key плюс
+// total, page, page_size, total_pages, has_next, has_prev; extra — поля сверх конверта.
+func page(key string, items interface{}, extra ...openapi.Object) openapi.Object {
+	obj := openapi.Object{
+		key:           items,
+		"total":       openapi.Integer(),
+		"page":        openapi.Integer(),
+		"page_size":   openapi.Integer(),
+		"total_pages": openapi.Integer(),
+		"has_next":    openapi.Boolean(),
+		"has_prev":    openapi.Boolean(),
+	}
+	for _, fields := range extra {
+		for name, schema := range fields {
+			obj[name] = schema
+		}
+	}
+	return obj
+}
+
+// message — ответ вида {"message": "..."} у удалений.
+var message = openapi.Object{"message": openapi.String()}
+
+// like — ответ на постановку и снятие лайка (альбом, рецензия, трек): оба
+// возвращают актуальный likes_count.
+var like = openapi.Object{"message": openapi.String(), "liked": openapi.Boolean(), "likes_count": openapi.Integer()}
+
+// apiOperations describes every route registered by registerAPI. Ключ — метод и
+// путь без /api/v1, как в SetupRoutes; Auth должен совпадать с middleware маршрута.
+// Маршрут без записи здесь попадёт в спецификацию без описания и в лог при старте.
+var apiOperations = map[string]openapi.Operation{
+	// Auth
+	"POST /auth/register": {Summary: "Регистрация", Body: controllers.RegisterRequest{}, Status: http.StatusCreated,
+		Response: openapi.Object{"message": openapi.String(), "user": models.User{}, "user_id": openapi.Integer(), "session_token": openapi.String()}},
+	"POST /auth/login": {Summary: "Вход", Body: controllers.LoginRequest{},
+		Response: openapi.Object{"message": openapi.String(), "user": models.User{}, "user_id": openapi.Integer(), "session_token": openapi.String()}},
+	"GET /auth/me": {Summary: "Текущий пользователь", Auth: openapi.User, Response: models.User{}},
+
+	// Genres
+	"GET /genres": {Summary: "Список жанров", Response: page("genres", []models.Genre{}),
+		Query: []openapi.Param{{Name: "with_counts", Type: "boolean", Description: "добавить число альбомов и треков"}}},
+	"GET /genres/:id":        {Summary: "Жанр по ID", Response: models.Genre{}},
+	"GET /genres/:id/albums": {Summary: "Альбомы жанра", Auth: openapi.Optional, Query: params(pageParams, sortParams), Response: page("albums", []models.Album{})},
+	"GET /genres/:id/top": {Summary: "Топ альбомов или треков жанра", Query: params(pageParams, []openapi.Param{
+		{Name: "type", Type: "string", Description: "albums или tracks"},
+		{Name: "min_reviews", Type: "integer", Description: "минимум одобренных рецензий"},
+	}), Response: page("items", &openapi.Schema{Type: "array"},
+		openapi.Object{"genre": models.Genre{}, "type": openapi.String(), "min_reviews": openapi.Integer()})},
+	"GET /genres/:id/overview": {Summary: "Обзор жанра: топ альбомов и треков", Query: []openapi.Param{limitParam},
+		Response: openapi.Object{"genre": models.Genre{}, "top_albums": []models.Album{}, "top_tracks": []models.Track{},
+			"album_count": openapi.Integer(), "track_count": openapi.Integer(), "limit": openapi.Integer()}},
+	"POST /genres":    {Summary: "Создать жанр", Auth: openapi.Admin, Body: controllers.CreateGenreRequest{}, Status: http.StatusCreated, Response: models.Genre{}},
+	"PUT /genres/:id": {Summary: "Изменить жанр", Auth: openapi.Admin, Body: controllers.UpdateGenreRequest{}, Response: models.Genre{}},
+	"DELETE /genres/:id": {Summary: "Удалить жанр", Auth: openapi.Admin, Response: openapi.Object{
+		"message": openapi.String(), "reassigned_to": openapi.Integer(), "albums_reassigned": openapi.Integer(),
+		"track_links_reassigned": openapi.Integer(), "track_links_merged": openapi.Integer(),
+	},
+		Query: []openapi.Param{{Name: "reassign_to", Type: "integer", Description: "жанр, в который перенести альбомы и треки"}}},
+
+	// Albums
+	"GET /albums": {Summary: "Список альбомов", Auth: openapi.Optional, Response: page("albums", []models.Album{}), Query: params(pageParams, sortParams, []openapi.Param{
+		{Name: "genre_id", Type: "integer"},
+		{Name: "search", Type: "string", Description: "по названию и артисту"},
+		{Name: "status", Type: "string", Description: "статус модерации, для admin"},
+	})},
+	"GET /albums/artist/:name": {Summary: "Альбомы артиста", Response: openapi.Object{
+		"albums": []models.Album{}, "artist": openapi.String(), "total": openapi.Integer(), "total_tracks": openapi.Integer(),
+		"approved_reviews_count": openapi.Integer(), "average_rating": openapi.Number(), "verified_account": &openapi.Schema{Type: "object", Nullable: true},
+	}},
+	"GET /albums/batch":      {Summary: "Альбомы по списку ID", Query: []openapi.Param{idsParam}, Response: openapi.Object{"albums": []models.Album{}, "missing": []uint{}}},
+	"GET /albums/:id/tracks": {Summary: "Треки альбома по порядку", Response: page("tracks", []models.Track{})},
+	"GET /albums/:id/review-stats": {Summary: "Гистограмма оценок альбома", Response: openapi.Object{
+		"album_id": openapi.Integer(), "reviews_count": openapi.Integer(), "average_score": openapi.Number(), "buckets": []controllers.ReviewScoreBucket{},
+	}},
+	"GET /albums/:id/track-reviews": {Summary: "Рецензии на треки альбома", Auth: openapi.Optional,
+		Response: openapi.Object{"album_id": openapi.Integer(), "tracks": &openapi.Schema{Type: "array"}}},
+	"GET /albums/:id/reviews.csv": {Summary: "Рецензии альбома в CSV", Auth: openapi.Optional, Response: openapi.String(), ContentType: "text/csv"},
+	"GET /albums/:id":             {Summary: "Альбом с треками", Auth: openapi.Optional, Response: models.Album{}},
+	"POST /albums/cover": {Summary: "Загрузить обложку", Auth: openapi.Admin, File: "cover", Status: http.StatusCreated,
+		Response: openapi.Object{"cover_image_path": openapi.String()}},
+	"POST /albums":             {Summary: "Создать или предложить альбом", Auth: openapi.User, Body: controllers.CreateAlbumRequest{}, Status: http.StatusCreated, Response: models.Album{}},
+	"POST /albums/:id/approve": {Summary: "Одобрить альбом", Auth: openapi.Admin, Response: models.Album{}},
+	"POST /albums/:id/reject":  {Summary: "Отклонить альбом", Auth: openapi.Admin, Response: models.Album{}},
+	"PUT /albums/:id":          {Summary: "Изменить альбом", Auth: openapi.Admin, Body: controllers.UpdateAlbumRequest{}, Response: models.Album{}},
+	"POST /albums/:id/tracks/reorder": {Summary: "Переупорядочить треки альбома", Auth: openapi.Admin, Body: controllers.ReorderTracksRequest{},
+		Response: openapi.Object{"tracks": []models.Track{}}},
+	"DELETE /albums/:id":      {Summary: "Удалить альбом", Auth: openapi.Admin, Response: message},
+	"POST /albums/:id/like":   {Summary: "Лайкнуть альбом", Auth: openapi.User, Status: http.StatusCreated, Response: like},
+	"DELETE /albums/:id/like": {Summary: "Снять лайк с альбома", Auth: openapi.User, Response: like},
+
+	// Reviews
+	"GET /reviews": {Summary: "Список рецензий", Auth: openapi.Optional, Response: page("reviews", []models.Review{}), Query: params(pageParams, sortParams, []openapi.Param{
+		{Name: "album_id", Type: "integer"},
+		{Name: "track_id", Type: "integer"},
+		{Name: "user_id", Type: "integer"},
+		{Name: "target", Type: "string", Description: "album или track"},
+		{Name: "status", Type: "string", Description: "по умолчанию approved; другие статусы — только администратору и автору с user_id=<свой id>"},
+		{Name: "following", Type: "boolean", Description: "только авторы из подписок, нужен токен"},
+		{Name: "artist_mark", Type: "boolean", Description: "только с отметкой артиста"},
+		{Name: "cursor", Type: "string", Description: "курсорная пагинация вместо page"},
+	})},
+	"GET /reviews/popular":      {Summary: "Популярные рецензии", Query: []openapi.Param{limitParam}, Response: page("reviews", []models.Review{})},
+	"GET /reviews/recent":       {Summary: "Недавно оценённые релизы", Query: []openapi.Param{limitParam}, Response: page("reviews", []models.Review{})},
+	"GET /reviews/:id":          {Summary: "Рецензия по ID", Response: models.Review{}},
+	"POST /reviews":             {Summary: "Создать рецензию", Auth: openapi.User, Body: controllers.CreateReviewRequest{}, Status: http.StatusCreated, Response: models.Review{}},
+	"PUT /reviews/:id":          {Summary: "Изменить свою рецензию", Auth: openapi.User, Body: controllers.UpdateReviewRequest{}, Response: models.Review{}},
+	"DELETE /reviews/:id":       {Summary: "Удалить свою рецензию", Auth: openapi.User, Response: message},
+	"POST /reviews/:id/like":    {Summary: "Лайкнуть рецензию", Auth: openapi.User, Status: http.StatusCreated, Response: like},
+	"DELETE /reviews/:id/like":  {Summary: "Снять лайк с рецензии", Auth: openapi.User, Response: like},
+	"POST /reviews/:id/approve": {Summary: "Одобрить рецензию", Auth: openapi.Admin, Response: models.Review{}},
+	"POST /reviews/:id/reject":  {Summary: "Отклонить рецензию", Auth: openapi.Admin, Body: controllers.RejectReviewRequest{}, Response: models.Review{}},
+	"GET /me/reviews": {Summary: "Свои рецензии в любом статусе", Auth: openapi.User, Response: page("reviews", []models.Review{}),
+		Query: params(pageParams, sortParams, []openapi.Param{{Name: "status", Type: "string"}})},
+
+	// Tracks
+	"GET /tracks": {Summary: "Список треков", Response: page("tracks", []models.Track{}), Query: params(pageParams, sortParams, []openapi.Param{
+		{Name: "album_id", Type: "integer"},
+		{Name: "search", Type: "string"},
+		{Name: "min_rating", Type: "number"},
+	})},
+	"GET /tracks/popular": {Summary: "Популярные треки", Response: page("tracks", []models.Track{}), Query: []openapi.Param{
+		limitParam, {Name: "window", Type: "string", Description: "окно популярности, например 7d"},
+	}},
+	"GET /tracks/batch": {Summary: "Треки по списку ID", Query: []openapi.Param{idsParam}, Response: openapi.Object{"tracks": []models.Track{}, "missing": []uint{}}},
+	"GET /tracks/:id/lyrics": {Summary: "Текст песни", Response: openapi.Object{
+		"track_id": openapi.Integer(), "title": openapi.String(), "lyrics": openapi.String(), "has_lyrics": openapi.Boolean(),
+	}},
+	"GET /tracks/:id": {Summary: "Трек по ID", Auth: openapi.Optional, Response: models.Track{},
+		Query: []openapi.Param{{Name: "include", Type: "string", Description: "дополнительные блоки через запятую"}}},
+	"POST /tracks/:id/listen": {Summary: "Засчитать прослушивание", Auth: openapi.Optional, Response: openapi.Object{"counted": openapi.Boolean()}},
+	"POST /tracks":            {Summary: "Создать трек", Auth: openapi.Admin, Body: controllers.CreateTrackRequest{}, Status: http.StatusCreated, Response: models.Track{}},
+	"PUT /tracks/:id":         {Summary: "Изменить трек", Auth: openapi.Admin, Body: controllers.UpdateTrackRequest{}, Response: models.Track{}},
+	"DELETE /tracks/:id":      {Summary: "Удалить трек", Auth: openapi.Admin, Response: message},
+	"POST /tracks/:id/like":   {Summary: "Лайкнуть трек", Auth: openapi.User, Status: http.StatusCreated, Response: like},
+	"DELETE /tracks/:id/like": {Summary: "Снять лайк с трека", Auth: openapi.User, Response: like},
+
+	// Search
+	"GET /search": {Summary: "Поиск по каталогу, пользователям и рецензиям", Auth: openapi.Optional, Response: controllers.SearchResponse{}, Query: []openapi.Param{
+		searchTerm, limitParam,
+		{Name: "types", Type: "string", Description: "artists, albums, tracks, users, reviews через запятую"},
+		{Name: "search_lyrics", Type: "boolean", Description: "искать и по текстам песен"},
+	}},
+	"GET /search/artists": {Summary: "Поиск артистов постранично", Query: params([]openapi.Param{searchTerm}, pageParams, sortParams),
+		Response: page("artists", []controllers.ArtistSearchResult{})},
+	"GET /search/albums": {Summary: "Поиск альбомов постранично", Query: params([]openapi.Param{searchTerm}, pageParams, sortParams),
+		Response: page("albums", []models.Album{})},
+	"GET /search/tracks": {Summary: "Поиск треков постранично", Query: params([]openapi.Param{searchTerm, {Name: "search_lyrics", Type: "boolean"}}, pageParams, sortParams),
+		Response: page("tracks", []controllers.TrackSearchResult{})},
+	"GET /search/suggest":  {Summary: "Подсказки для строки поиска", Query: []openapi.Param{searchTerm}, Response: openapi.Object{"query": openapi.String(), "suggestions": &openapi.Schema{Type: "array"}}},
+	"GET /search/trending": {Summary: "Частые поисковые запросы", Response: openapi.Object{"queries": []controllers.SearchQueryStat{}}},
+
+	// Feed
+	"GET /feed/reviews.rss": {Summary: "RSS свежих одобренных рецензий", Response: openapi.String(), ContentType: "application/rss+xml",
+		Query: []openapi.Param{{Name: "album_id", Type: "integer"}}},
+
+	// Users
+	"GET /users/confirm-email": {Summary: "Подтвердить новый email по ссылке", Query: []openapi.Param{{Name: "token", Type: "string"}},
+		Response: openapi.Object{"message": openapi.String(), "email": openapi.String()}},
+	"GET /users/leaderboard": {Summary: "Рейтинг рецензентов", Auth: openapi.Optional, Query: params(pageParams, []openapi.Param{{Name: "window", Type: "string"}}),
+		Response: page("users", &openapi.Schema{Type: "array"}, openapi.Object{"me": &openapi.Schema{Type: "object", Nullable: true}})},
+	"POST /users/:id/follow":   {Summary: "Подписаться", Auth: openapi.User, Response: openapi.Object{"following": openapi.Boolean()}},
+	"DELETE /users/:id/follow": {Summary: "Отписаться", Auth: openapi.User, Response: openapi.Object{"following": openapi.Boolean()}},
+	"GET /users/:id":           {Summary: "Профиль пользователя со статистикой и званиями", Auth: openapi.Optional, Response: &openapi.Schema{Type: "object"}},
+	"GET /users/:id/reviews": {Summary: "Рецензии пользователя", Auth: openapi.Optional, Response: page("reviews", []models.Review{}),
+		Query: params(pageParams, sortParams, []openapi.Param{{Name: "status", Type: "string", Description: "не approved — только владельцу и admin"}})},
+	"GET /users/:id/stats": {Summary: "Статистика профиля", Auth: openapi.Optional, Response: openapi.Object{
+		"user_id": openapi.Integer(), "approved_reviews_count": openapi.Integer(), "average_score_given": openapi.Number(), "top_genre": openapi.String(),
+		"reviews_per_month": &openapi.Schema{Type: "array"}, "likes_received": openapi.Integer(), "liked_albums_count": openapi.Integer(), "liked_tracks_count": openapi.Integer(),
+	}},
+	"GET /users/:id/liked-reviews": {Summary: "Рецензии, которые лайкнул пользователь", Auth: openapi.Optional, Query: pageParams, Response: page("reviews", []models.Review{})},
+	"GET /users/:id/likes/tracks":  {Summary: "Лайкнутые треки", Auth: openapi.Optional, Query: pageParams, Response: page("tracks", []models.Track{})},
+	"GET /users/:id/likes/albums":  {Summary: "Лайкнутые альбомы", Auth: openapi.Optional, Query: pageParams, Response: page("albums", []models.Album{})},
+	"GET /users/:id/export":        {Summary: "Выгрузка своих данных", Auth: openapi.User, Response: &openapi.Schema{Type: "object"}},
+	"PUT /users/:id": {Summary: "Изменить свой профиль", Auth: openapi.User, Response: &openapi.Schema{Type: "object"}, Body: struct {
+		Username     string            `json:"username"`
+		Email        string            `json:"email"`
+		Bio          string            `json:"bio"`
+		SocialLinks  map[string]string `json:"social_links"`
+		Password     string            `json:"password"`
+		LikesPrivate *bool             `json:"likes_private"`
+		EmailPublic  *bool             `json:"email_public"`
+	}{}},
+	"POST /users/:id/avatar":   {Summary: "Загрузить аватар", Auth: openapi.User, File: "avatar", Response: models.User{}},
+	"DELETE /users/:id/avatar": {Summary: "Удалить аватар", Auth: openapi.User, Response: models.User{}},
+	"PUT /users/:id/favorites": {Summary: "Избранные альбомы, артисты и треки (до трёх)", Auth: openapi.User, Body: struct {
+		AlbumIDs    []uint   `json:"album_ids"`
+		ArtistNames []string `json:"artist_names"`
+		TrackIDs    []uint   `json:"track_ids"`
+	}{}, Response: openapi.Object{"favorite_albums": []models.Album{}, "favorite_artists": []string{}, "favorite_tracks": []models.Track{}, "preferences_manual": openapi.Boolean()}},
+	"DELETE /users/:id": {Summary: "Удалить аккаунт", Auth: openapi.User, Response: openapi.Object{"message": openapi.String(), "strategy": openapi.String()},
+		Query: []openapi.Param{{Name: "strategy", Type: "string", Description: "что сделать с рецензиями"}}},
+
+	// Admin
+	"GET /admin/users": {Summary: "Пользователи для админки", Auth: openapi.Admin, Query: params(pageParams, sortParams, []openapi.Param{
+		{Name: "search", Type: "string"}, {Name: "is_admin", Type: "boolean"}, {Name: "verified", Type: "boolean"},
+	}), Response: page("users", &openapi.Schema{Type: "array"})},
+	"POST /admin/users/recompute-ranks": {Summary: "Пересчитать ранги профилей", Auth: openapi.Admin,
+		Response: openapi.Object{"message": openapi.String(), "users": openapi.Integer()}},
+	"GET /admin/reviews/pending-count": {Summary: "Число рецензий на модерации", Auth: openapi.Admin, Response: openapi.Object{"count": openapi.Integer()}},
+	"POST /admin/reviews/recompute-scores": {Summary: "Пересчитать оценки по текущей формуле", Auth: openapi.Admin, Response: openapi.Object{
+		"scoring": &openapi.Schema{Type: "object"}, "reviews_total": openapi.Integer(), "reviews_updated": openapi.Integer(),
+		"albums_updated": openapi.Integer(), "tracks_updated": openapi.Integer(),
+	}},
+	"POST /admin/recompute-ratings": {Summary: "Пересчитать средние рейтинги", Auth: openapi.Admin, Response: openapi.Object{
+		"albums_total": openapi.Integer(), "albums_updated": openapi.Integer(), "tracks_total": openapi.Integer(), "tracks_updated": openapi.Integer(),
+	}},
+	"POST /admin/genres/:id/merge-into/:target": {Summary: "Слить жанр-дубль в другой", Auth: openapi.Admin, Response: openapi.Object{
+		"message": openapi.String(), "source_id": openapi.Integer(), "target_id": openapi.Integer(),
+		"albums_moved": openapi.Integer(), "track_links_moved": openapi.Integer(), "track_links_merged": openapi.Integer(),
+	}},
+	"POST /admin/albums/merge": {Summary: "Слить альбом-дубль в другой", Auth: openapi.Admin, Body: controllers.MergeAlbumsRequest{}, Response: openapi.Object{
+		"message": openapi.String(), "source_id": openapi.Integer(), "target_id": openapi.Integer(), "average_rating": openapi.Number(),
+		"tracks_moved": openapi.Integer(), "reviews_moved": openapi.Integer(), "reviews_merged": openapi.Integer(),
+		"likes_moved": openapi.Integer(), "likes_merged": openapi.Integer(),
+	}},
+	"GET /admin/search/zero-results": {Summary: "Запросы без результатов", Auth: openapi.Admin, Query: []openapi.Param{limitParam},
+		Response: openapi.Object{"queries": []controllers.SearchQueryStat{}}},
+}
+
+// openAPISpec — всё, кроме самих маршрутов: их registerOpenAPI берёт из gin.
+var openAPISpec = openapi.Spec{
+	Title:      "Music Review Site API",
+	Version:    "v1",
+	Prefix:     "/api/v1",
+	Operations: apiOperations,
+	Error:      utils.ErrorResponse{},
+	Computed: []openapi.Computed{
+		{Sample: models.Track{}, Fields: map[string]*openapi.Schema{"duration_formatted": openapi.String(), "has_lyrics": openapi.Boolean()}},
+		{Sample: models.Review{}, Fields: map[string]*openapi.Schema{"moderator_username": openapi.String()}},
+	},
+}
+
+// registerOpenAPI serves the spec at /api/openapi.json and Swagger UI at /api/docs.
+// Спецификация собирается при первом запросе, когда все маршруты уже зарегистрированы.
+func registerOpenAPI(r *gin.Engine) {
+	var (
+		once sync.Once
+		spec []byte
+	)
+	r.GET("/api/openapi.json", func(c *gin.Context) {
+		once.Do(func() {
+			document, undocumented := openAPISpec.Build(r.Routes())
+			if len(undocumented) > 0 {
+				slog.Warn("openapi: routes without description in apiOperations", "routes", undocumented)
+			}
+			var err error
+			if spec, err = json.Marshal(document); err != nil {
+				slog.Error("openapi: failed to encode spec", "error", err)
+			}
+		})
+		if spec == nil {
+			utils.RespondError(c, http.StatusInternalServerError, "Failed to build OpenAPI spec")
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	})
+	r.GET("/api/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	})
+}
+
+// swaggerUIPage — Swagger UI с CDN поверх /api/openapi.json; своих статических файлов не нужно.
+const swaggerUIPage = `<!doctype html>
+<html lang="ru">
+<head>
+  <meta charset="utf-8">
+  <title>Music Review Site API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
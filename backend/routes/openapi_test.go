@@ -0,0 +1,248 @@
+package routes
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"music-review-site/backend/config"
+	"music-review-site/backend/models"
+	"music-review-site/backend/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testServer собирает тот же роутер, что и main, поверх недоступной БД:
+// маршруты и middleware настоящие, а запросы к базе сразу падают.
+func testServer(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		AppEnv:         "dev",
+		CORSOrigins:    []string{"http://localhost:3000"},
+		RequestTimeout: 10 * time.Second,
+		UploadsDir:     t.TempDir(),
+		SessionSecret:  "test-secret",
+		SessionTTL:     time.Hour,
+		OpenAPIEnabled: true,
+		MaxPageSize:    100,
+		PublicSiteURL:  "http://localhost:3000",
+		Scoring:        models.DefaultScoring(),
+		RateLimits:     config.RateLimits{LoginMaxAttempts: 5, LoginLockout: time.Minute},
+	}
+	return NewServer(unreachableDB(t), cfg)
+}
+
+// apiRoutes — маршруты /api/v1 в виде ключей apiOperations ("GET /albums/:id").
+func apiRoutes(r *gin.Engine) map[string]gin.RouteInfo {
+	routes := map[string]gin.RouteInfo{}
+	for _, route := range r.Routes() {
+		if path, ok := strings.CutPrefix(route.Path, openAPISpec.Prefix); ok {
+			routes[route.Method+" "+path] = route
+		}
+	}
+	return routes
+}
+
+func TestAPIOperationsMatchRoutes(t *testing.T) {
+	routes := apiRoutes(testServer(t))
+	for key := range routes {
+		if _, ok := apiOperations[key]; !ok {
+			t.Errorf("route %s is not documented in apiOperations", key)
+		}
+	}
+	for key := range apiOperations {
+		if _, ok := routes[key]; !ok {
+			t.Errorf("apiOperations[%q] has no route", key)
+		}
+	}
+}
+
+// TestAPIOperationsAuth сверяет Auth с middleware: без токена защищённые
+// маршруты отвечают 401, а публичные до проверки авторизации не доходят.
+func TestAPIOperationsAuth(t *testing.T) {
+	r := testServer(t)
+	pathParam := regexp.MustCompile(`:[a-z_]+`)
+	for key := range apiRoutes(r) {
+		op, ok := apiOperations[key]
+		if !ok {
+			continue
+		}
+		method, path, _ := strings.Cut(key, " ")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, openAPISpec.Prefix+pathParam.ReplaceAllString(path, "1"), nil))
+
+		protected := op.Auth == openapi.User || op.Auth == openapi.Admin
+		if protected && w.Code != http.StatusUnauthorized {
+			t.Errorf("%s documented as auth %d, but answers %d without a token", key, op.Auth, w.Code)
+		}
+		if !protected && w.Code == http.StatusUnauthorized {
+			t.Errorf("%s documented as public, but answers 401 without a token", key)
+		}
+	}
+}
+
+// handlerDecls разбирает исходники контроллеров: "AlbumController.LikeAlbum" → тело метода.
+func handlerDecls(t *testing.T) map[string]*ast.FuncDecl {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join("..", "controllers", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls := map[string]*ast.FuncDecl{}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+				continue
+			}
+			if star, ok := fn.Recv.List[0].Type.(*ast.StarExpr); ok {
+				if recv, ok := star.X.(*ast.Ident); ok {
+					decls[recv.Name+"."+fn.Name.Name] = fn
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// handlerShape — что хендлер читает и отдаёт: имя поля c.FormFile и ключи
+// успешных (200 и 201) c.JSON — литералов gin.H и конвертов utils.NewPaginated
+// и utils.NewList.
+type handlerShape struct {
+	formFiles []string
+	keys      map[string]bool
+}
+
+func shapeOf(fn *ast.FuncDecl) handlerShape {
+	shape := handlerShape{keys: map[string]bool{}}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		switch sel.Sel.Name {
+		case "FormFile":
+			if len(call.Args) == 1 {
+				if lit, ok := call.Args[0].(*ast.BasicLit); ok {
+					name, _ := strconv.Unquote(lit.Value)
+					shape.formFiles = append(shape.formFiles, name)
+				}
+			}
+		case "JSON":
+			if len(call.Args) != 2 || !isSuccessStatus(call.Args[0]) {
+				return true
+			}
+			if key, ok := envelopeKey(call.Args[1]); ok {
+				for _, k := range []string{key, "total", "page", "page_size", "total_pages", "has_next", "has_prev"} {
+					shape.keys[k] = true
+				}
+				return true
+			}
+			body, ok := call.Args[1].(*ast.CompositeLit)
+			if !ok || !isGinH(body.Type) {
+				return true
+			}
+			for _, elt := range body.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if lit, ok := kv.Key.(*ast.BasicLit); ok {
+						key, _ := strconv.Unquote(lit.Value)
+						shape.keys[key] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+	return shape
+}
+
+// envelopeKey возвращает ключ элементов, если expr — utils.NewPaginated(key, ...)
+// или utils.NewList(key, ...).
+func envelopeKey(expr ast.Expr) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "NewPaginated" && sel.Sel.Name != "NewList") {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok {
+		return "", false
+	}
+	key, err := strconv.Unquote(lit.Value)
+	return key, err == nil
+}
+
+func isSuccessStatus(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	return ok && (sel.Sel.Name == "StatusOK" || sel.Sel.Name == "StatusCreated")
+}
+
+func isGinH(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "gin" && sel.Sel.Name == "H"
+}
+
+// TestAPIOperationsMatchHandlers сверяет описание с исходником хендлера: поле
+// загружаемого файла и ключи ответов-объектов gin.H должны быть в apiOperations.
+func TestAPIOperationsMatchHandlers(t *testing.T) {
+	decls := handlerDecls(t)
+	handlerName := regexp.MustCompile(`\(\*(\w+)\)\.(\w+)-fm$`)
+	for key, route := range apiRoutes(testServer(t)) {
+		op, ok := apiOperations[key]
+		m := handlerName.FindStringSubmatch(route.Handler)
+		if !ok || m == nil {
+			continue
+		}
+		fn, ok := decls[m[1]+"."+m[2]]
+		if !ok {
+			continue
+		}
+		shape := shapeOf(fn)
+
+		var file string
+		if len(shape.formFiles) > 0 {
+			file = shape.formFiles[0]
+		}
+		if file != op.File {
+			t.Errorf("%s: handler reads file field %q, documented %q", key, file, op.File)
+		}
+		obj, ok := op.Response.(openapi.Object)
+		if !ok && len(shape.keys) > 0 {
+			t.Errorf("%s: handler answers with an object, documented %T", key, op.Response)
+			continue
+		}
+		for k := range shape.keys {
+			if _, ok := obj[k]; !ok {
+				t.Errorf("%s: response key %q is not documented", key, k)
+			}
+		}
+	}
+}
@@ -1,118 +1,275 @@
 package routes
 
 import (
+	"music-review-site/backend/config"
 	"music-review-site/backend/controllers"
 	"music-review-site/backend/middleware"
+	"music-review-site/backend/utils"
+	"net/http"
+	"time"
 
+	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// NewServer builds the complete HTTP handler: middleware, CORS and all routes.
+// Порт не занимает — main оборачивает движок в http.Server, а интеграционные
+// тесты могут вызывать его через httptest с тестовой БД и своим config.Config.
+func NewServer(db *gorm.DB, cfg *config.Config) *gin.Engine {
+	// RequestLogger идёт первым: X-Request-ID и логгер запроса нужны всем
+	// следующим middleware. Паника в хендлере отдаёт стандартный ErrorResponse,
+	// а не пустой ответ 500 из gin.Recovery.
+	r := gin.New()
+	// X-Forwarded-For учитывается только от прокси из TRUSTED_PROXIES (nginx
+	// фронтенда); без них c.ClientIP() — адрес соединения. Значения уже
+	// проверены config.Load.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
+	r.Use(middleware.RequestLogger(), middleware.Recovery())
+
+	// CORS configuration
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = cfg.CORSOrigins
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "X-User-ID", "If-None-Match", middleware.RequestIDHeader}
+	corsConfig.ExposeHeaders = []string{middleware.RequestIDHeader, "ETag"}
+	corsConfig.AllowCredentials = true
+	r.Use(cors.New(corsConfig))
+
+	// Таймаут запроса: контекст с дедлайном доходит до запросов к БД
+	r.Use(middleware.RequestTimeout(cfg.RequestTimeout))
+
+	SetupRoutes(r, db, cfg)
+	return r
+}
+
+// maintenanceTimeout — дедлайн админских пересчётов вместо REQUEST_TIMEOUT.
+const maintenanceTimeout = 10 * time.Minute
+
 // SetupRoutes configures all routes
-func SetupRoutes(r *gin.Engine, db *gorm.DB) {
-	// Initialize controllers
-	authController := &controllers.AuthController{DB: db}
-	albumController := &controllers.AlbumController{DB: db}
-	reviewController := &controllers.ReviewController{DB: db}
-	genreController := &controllers.GenreController{DB: db}
-	userController := &controllers.UserController{DB: db}
-	trackController := &controllers.TrackController{DB: db}
-	searchController := &controllers.SearchController{DB: db}
-
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
+func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg *config.Config) {
+	// Неизвестные пути и методы отвечают тем же конвертом ошибки, что и хендлеры,
+	// а не текстовым "404 page not found" из gin.
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(func(c *gin.Context) {
+		utils.RespondError(c, http.StatusNotFound, "Route not found")
 	})
-	r.GET("/healthz", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
+	r.NoMethod(func(c *gin.Context) {
+		utils.RespondError(c, http.StatusMethodNotAllowed, "Method not allowed")
 	})
 
-	// API routes
-	api := r.Group("/api")
-	{
+	r.Use(utils.PageSizeLimit(cfg.MaxPageSize))
+
+	// Сессии и вход по X-User-ID для AuthMiddleware и OptionalAuthMiddleware
+	sessions := utils.NewSessions(cfg.SessionSecret, cfg.SessionTTL)
+	authOpts := middleware.AuthOptions{Sessions: sessions, AllowUserIDHeader: cfg.AllowUserIDHeader}
+
+	// Initialize controllers
+	limits := cfg.RateLimits
+	authController := &controllers.AuthController{
+		DB:                db,
+		LoginLimiter:      utils.NewLoginLimiter(limits.LoginMaxAttempts, limits.LoginLockout),
+		Sessions:          sessions,
+		HideEmailConflict: cfg.HideEmailConflict,
+	}
+	albumController := &controllers.AlbumController{DB: db, UploadsDir: cfg.UploadsDir, Scoring: cfg.Scoring}
+	reviewController := &controllers.ReviewController{
+		DB:             db,
+		ReviewsPerHour: limits.ReviewsPerHour,
+		Scoring:        cfg.Scoring,
+		WebhookURL:     cfg.ReviewWebhookURL,
+		PublicSiteURL:  cfg.PublicSiteURL,
+	}
+	genreController := &controllers.GenreController{DB: db, Scoring: cfg.Scoring}
+	userController := &controllers.UserController{
+		DB:                     db,
+		Mailer:                 utils.NewMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From),
+		UploadsDir:             cfg.UploadsDir,
+		ExposeConfirmToken:     cfg.ExposeEmailConfirmToken,
+		PublicSiteURL:          cfg.PublicSiteURL,
+		UsernameChangeCooldown: cfg.UsernameChangeCooldown,
+		Ranks:                  controllers.NewProfileRanks(controllers.ProfileRanksTTL),
+	}
+	// Повторное прослушивание трека тем же пользователем/IP засчитывается не чаще раза в 30 минут
+	trackController := &controllers.TrackController{DB: db, ListenLimiter: utils.NewListenLimiter(30 * time.Minute), Scoring: cfg.Scoring}
+	searchController := &controllers.SearchController{DB: db, Log: controllers.NewSearchLog(db)}
+	feedController := &controllers.FeedController{DB: db, PublicSiteURL: cfg.PublicSiteURL}
+
+	// Health check: live — процесс жив, ready — БД отвечает. /health и /healthz
+	// (его использует healthcheck контейнера) оставлены как синонимы ready.
+	r.GET("/health/live", liveHandler)
+	// Подробности (?verbose=true) видит только админ, поэтому токен разбирается
+	// необязательно: healthcheck контейнера ходит без него.
+	r.GET("/health/ready", middleware.OptionalAuthMiddleware(db, authOpts), readyHandler(db))
+	r.GET("/health", middleware.OptionalAuthMiddleware(db, authOpts), readyHandler(db))
+	r.GET("/healthz", middleware.OptionalAuthMiddleware(db, authOpts), readyHandler(db))
+
+	// Загруженные файлы (аватары, обложки) из UPLOADS_DIR. Имена файлов уникальны,
+	// поэтому их можно кешировать надолго; листинг каталогов gin не отдаёт.
+	uploads := r.Group("/uploads", uploadsCacheHeaders)
+	uploads.Static("/", cfg.UploadsDir)
+
+	// API routes: текущая версия — /api/v1, /api остаётся её синонимом на
+	// переходный период. Несовместимые изменения пойдут в /api/v2.
+	registerAPI := func(api *gin.RouterGroup) {
 		// Auth routes
 		auth := api.Group("/auth")
 		{
 			auth.POST("/register", authController.Register)
 			auth.POST("/login", authController.Login)
-			auth.GET("/me", middleware.AuthMiddleware(db), authController.GetMe)
+			auth.GET("/me", middleware.AuthMiddleware(db, authOpts), authController.GetMe)
 		}
 
+		// Часто читаемые и редко меняющиеся ответы отдаются с ETag (If-None-Match → 304)
+		// и коротким max-age; популярное вдобавок кешируется в памяти процесса.
 		// Genre routes
 		genres := api.Group("/genres")
 		{
-			genres.GET("", genreController.GetGenres)
+			genres.GET("", middleware.ETag(time.Minute), genreController.GetGenres)
 			genres.GET("/:id", genreController.GetGenre)
-			genres.POST("", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), genreController.CreateGenre)
-			genres.PUT("/:id", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), genreController.UpdateGenre)
-			genres.DELETE("/:id", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), genreController.DeleteGenre)
+			genres.GET("/:id/albums", middleware.OptionalAuthMiddleware(db, authOpts), genreController.GetGenreAlbums)
+			genres.GET("/:id/top", genreController.GetGenreTop)
+			genres.GET("/:id/overview", genreController.GetGenreOverview)
+			genres.POST("", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), genreController.CreateGenre)
+			genres.PUT("/:id", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), genreController.UpdateGenre)
+			genres.DELETE("/:id", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), genreController.DeleteGenre)
 		}
 
 		// Album routes
 		albums := api.Group("/albums")
 		{
-			albums.GET("", albumController.GetAlbums)
+			albums.GET("", middleware.ETag(30*time.Second), middleware.OptionalAuthMiddleware(db, authOpts), albumController.GetAlbums)
 			// More specific routes must come before /:id
 			albums.GET("/artist/:name", albumController.GetAlbumsByArtist)
-			albums.GET("/:id/tracks", trackController.GetTracks)
-			albums.GET("/:id", albumController.GetAlbum)
-			albums.POST("/cover", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), albumController.UploadCover)
-			albums.POST("", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), albumController.CreateAlbum)
-			albums.PUT("/:id", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), albumController.UpdateAlbum)
-			albums.DELETE("/:id", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), albumController.DeleteAlbum)
+			albums.GET("/batch", albumController.GetAlbumsBatch)
+			albums.GET("/:id/tracks", middleware.OptionalAuthMiddleware(db, authOpts), trackController.GetTracks)
+			albums.GET("/:id/review-stats", albumController.GetAlbumReviewStats)
+			albums.GET("/:id/track-reviews", middleware.OptionalAuthMiddleware(db, authOpts), albumController.GetAlbumTrackReviews)
+			albums.GET("/:id/reviews.csv", middleware.OptionalAuthMiddleware(db, authOpts), albumController.GetAlbumReviewsCSV)
+			albums.GET("/:id", middleware.ETag(30*time.Second), middleware.OptionalAuthMiddleware(db, authOpts), albumController.GetAlbum)
+			albums.POST("/cover", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), albumController.UploadCover)
+			// Любой авторизованный пользователь может предложить альбом; без админа он ждёт модерации
+			albums.POST("", middleware.AuthMiddleware(db, authOpts), albumController.CreateAlbum)
+			albums.POST("/:id/approve", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), albumController.ApproveAlbum)
+			albums.POST("/:id/reject", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), albumController.RejectAlbum)
+			albums.PUT("/:id", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), albumController.UpdateAlbum)
+			albums.POST("/:id/tracks/reorder", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), trackController.ReorderTracks)
+			albums.DELETE("/:id", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), albumController.DeleteAlbum)
 			// Like routes
-			albums.POST("/:id/like", middleware.AuthMiddleware(db), albumController.LikeAlbum)
-			albums.DELETE("/:id/like", middleware.AuthMiddleware(db), albumController.UnlikeAlbum)
+			albums.POST("/:id/like", middleware.AuthMiddleware(db, authOpts), albumController.LikeAlbum)
+			albums.DELETE("/:id/like", middleware.AuthMiddleware(db, authOpts), albumController.UnlikeAlbum)
 		}
 
 		// Review routes
 		reviews := api.Group("/reviews")
 		{
-			reviews.GET("", middleware.OptionalAuthMiddleware(db), reviewController.GetReviews)
-			reviews.GET("/popular", reviewController.GetPopularReviews)
+			reviews.GET("", middleware.OptionalAuthMiddleware(db, authOpts), reviewController.GetReviews)
+			reviews.GET("/popular", middleware.ETag(30*time.Second), middleware.SharedCache(45*time.Second), reviewController.GetPopularReviews)
+			reviews.GET("/recent", reviewController.GetRecentlyReviewed)
 			reviews.GET("/:id", reviewController.GetReview)
-			reviews.POST("", middleware.AuthMiddleware(db), reviewController.CreateReview)
-			reviews.PUT("/:id", middleware.AuthMiddleware(db), reviewController.UpdateReview)
-			reviews.DELETE("/:id", middleware.AuthMiddleware(db), reviewController.DeleteReview)
+			reviews.POST("", middleware.AuthMiddleware(db, authOpts), reviewController.CreateReview)
+			reviews.PUT("/:id", middleware.AuthMiddleware(db, authOpts), reviewController.UpdateReview)
+			reviews.DELETE("/:id", middleware.AuthMiddleware(db, authOpts), reviewController.DeleteReview)
 
 			// Like routes
-			reviews.POST("/:id/like", middleware.AuthMiddleware(db), reviewController.LikeReview)
-			reviews.DELETE("/:id/like", middleware.AuthMiddleware(db), reviewController.UnlikeReview)
+			reviews.POST("/:id/like", middleware.AuthMiddleware(db, authOpts), reviewController.LikeReview)
+			reviews.DELETE("/:id/like", middleware.AuthMiddleware(db, authOpts), reviewController.UnlikeReview)
 
 			// Moderation routes (admin only)
-			reviews.POST("/:id/approve", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), reviewController.ApproveReview)
-			reviews.POST("/:id/reject", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), reviewController.RejectReview)
+			reviews.POST("/:id/approve", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), reviewController.ApproveReview)
+			reviews.POST("/:id/reject", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), reviewController.RejectReview)
+		}
+
+		// Личный кабинет текущего пользователя
+		me := api.Group("/me", middleware.AuthMiddleware(db, authOpts))
+		{
+			me.GET("/reviews", reviewController.GetMyReviews)
 		}
 
 		// Track routes
 		tracks := api.Group("/tracks")
 		{
-			tracks.GET("", trackController.GetAllTracks) // Must come before /:id
-			tracks.GET("/popular", trackController.GetPopularTracks)
-			tracks.GET("/:id", trackController.GetTrack)
-			tracks.POST("", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), trackController.CreateTrack)
-			tracks.PUT("/:id", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), trackController.UpdateTrack)
-			tracks.DELETE("/:id", middleware.AuthMiddleware(db), middleware.AdminMiddleware(), trackController.DeleteTrack)
+			tracks.GET("", middleware.OptionalAuthMiddleware(db, authOpts), trackController.GetAllTracks) // Must come before /:id
+			tracks.GET("/popular", middleware.ETag(30*time.Second), middleware.SharedCache(45*time.Second), trackController.GetPopularTracks)
+			tracks.GET("/batch", middleware.OptionalAuthMiddleware(db, authOpts), trackController.GetTracksBatch)
+			tracks.GET("/:id/lyrics", middleware.OptionalAuthMiddleware(db, authOpts), trackController.GetTrackLyrics)
+			tracks.GET("/:id", middleware.OptionalAuthMiddleware(db, authOpts), trackController.GetTrack)
+			tracks.POST("/:id/listen", middleware.OptionalAuthMiddleware(db, authOpts), trackController.RecordListen)
+			tracks.POST("", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), trackController.CreateTrack)
+			tracks.PUT("/:id", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), trackController.UpdateTrack)
+			tracks.DELETE("/:id", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware(), trackController.DeleteTrack)
 			// Like routes
-			tracks.POST("/:id/like", middleware.AuthMiddleware(db), trackController.LikeTrack)
-			tracks.DELETE("/:id/like", middleware.AuthMiddleware(db), trackController.UnlikeTrack)
+			tracks.POST("/:id/like", middleware.AuthMiddleware(db, authOpts), trackController.LikeTrack)
+			tracks.DELETE("/:id/like", middleware.AuthMiddleware(db, authOpts), trackController.UnlikeTrack)
 		}
 
 		// Search routes
-		api.GET("/search", searchController.Search)
+		api.GET("/search", middleware.OptionalAuthMiddleware(db, authOpts), searchController.Search)
+		api.GET("/search/artists", searchController.SearchArtistsPage)
+		api.GET("/search/albums", searchController.SearchAlbumsPage)
+		api.GET("/search/tracks", searchController.SearchTracksPage)
+		api.GET("/search/suggest", searchController.SearchSuggest)
+		api.GET("/search/trending", searchController.GetTrendingSearches)
+
+		// RSS feed routes
+		api.GET("/feed/reviews.rss", feedController.GetReviewsRSS)
 
 		// User routes
 		users := api.Group("/users")
 		{
-			users.POST("/:id/follow", middleware.AuthMiddleware(db), userController.FollowUser)
-			users.DELETE("/:id/follow", middleware.AuthMiddleware(db), userController.UnfollowUser)
-			users.GET("/:id", middleware.OptionalAuthMiddleware(db), userController.GetUser)
-			users.GET("/:id/reviews", middleware.OptionalAuthMiddleware(db), userController.GetUserReviews)
-			users.GET("/:id/liked-reviews", userController.GetUserLikedReviews)
-			users.PUT("/:id", middleware.AuthMiddleware(db), userController.UpdateUser)
-			users.POST("/:id/avatar", middleware.AuthMiddleware(db), userController.UploadAvatar)
-			users.PUT("/:id/favorites", middleware.AuthMiddleware(db), userController.SetFavoriteAlbums)
-			users.DELETE("/:id", middleware.AuthMiddleware(db), userController.DeleteUser)
+			users.GET("/confirm-email", userController.ConfirmEmail)
+			users.GET("/leaderboard", middleware.OptionalAuthMiddleware(db, authOpts), userController.GetLeaderboard)
+			users.POST("/:id/follow", middleware.AuthMiddleware(db, authOpts), userController.FollowUser)
+			users.DELETE("/:id/follow", middleware.AuthMiddleware(db, authOpts), userController.UnfollowUser)
+			users.GET("/:id", middleware.OptionalAuthMiddleware(db, authOpts), userController.GetUser)
+			users.GET("/:id/reviews", middleware.OptionalAuthMiddleware(db, authOpts), userController.GetUserReviews)
+			users.GET("/:id/stats", middleware.OptionalAuthMiddleware(db, authOpts), userController.GetUserStats)
+			users.GET("/:id/liked-reviews", middleware.OptionalAuthMiddleware(db, authOpts), userController.GetUserLikedReviews)
+			users.GET("/:id/likes/tracks", middleware.OptionalAuthMiddleware(db, authOpts), userController.GetUserLikedTracks)
+			users.GET("/:id/likes/albums", middleware.OptionalAuthMiddleware(db, authOpts), userController.GetUserLikedAlbums)
+			users.GET("/:id/export", middleware.AuthMiddleware(db, authOpts), userController.ExportUser)
+			users.PUT("/:id", middleware.AuthMiddleware(db, authOpts), userController.UpdateUser)
+			users.POST("/:id/avatar", middleware.AuthMiddleware(db, authOpts), userController.UploadAvatar)
+			users.DELETE("/:id/avatar", middleware.AuthMiddleware(db, authOpts), userController.DeleteAvatar)
+			users.PUT("/:id/favorites", middleware.AuthMiddleware(db, authOpts), userController.SetFavoriteAlbums)
+			users.DELETE("/:id", middleware.AuthMiddleware(db, authOpts), userController.DeleteUser)
 		}
+
+		// Admin routes: middleware на всю группу
+		admin := api.Group("/admin", middleware.AuthMiddleware(db, authOpts), middleware.AdminMiddleware())
+		{
+			admin.GET("/users", userController.AdminListUsers)
+			admin.POST("/users/recompute-ranks", userController.RecomputeProfileRanks)
+			admin.GET("/reviews/pending-count", reviewController.GetPendingReviewCount)
+			// Пересчёты проходят по всем рецензиям и не укладываются в REQUEST_TIMEOUT
+			admin.POST("/reviews/recompute-scores", middleware.LongRunning(maintenanceTimeout), reviewController.RecomputeScores)
+			admin.POST("/recompute-ratings", middleware.LongRunning(maintenanceTimeout), reviewController.RecomputeRatings)
+			admin.POST("/genres/:id/merge-into/:target", genreController.MergeGenre)
+			admin.POST("/albums/merge", albumController.MergeAlbums)
+			admin.GET("/search/zero-results", searchController.AdminZeroResultSearches)
+		}
+	}
+	registerAPI(r.Group("/api/v1"))
+	registerAPI(r.Group("/api", deprecatedAPIAlias))
+
+	if cfg.OpenAPIEnabled {
+		registerOpenAPI(r)
 	}
 }
+
+// deprecatedAPIAlias помечает ответы неверсионированного /api заголовками
+// Deprecation и Link на /api/v1, чтобы клиенты успели перейти.
+func deprecatedAPIAlias(c *gin.Context) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", `</api/v1>; rel="successor-version"`)
+	c.Next()
+}
+
+// uploadsCacheHeaders выставляет заголовки кеширования для статики загрузок.
+func uploadsCacheHeaders(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=604800")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Next()
+}
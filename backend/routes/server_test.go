@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"music-review-site/backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Паника проходит всю цепочку NewServer (RequestLogger, Recovery, CORS,
+// RequestTimeout) и отдаёт стандартный ErrorResponse 500, а не 499.
+func TestPanicThroughServerChain(t *testing.T) {
+	r := testServer(t)
+	r.GET("/api/v1/test/panic", func(c *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/test/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("want 500, got %d %s", w.Code, w.Body.String())
+	}
+	var resp utils.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+	want := utils.ErrorResponse{Error: "Internal Server Error", Message: "Internal server error", Code: http.StatusInternalServerError}
+	if resp.Error != want.Error || resp.Message != want.Message || resp.Code != want.Code {
+		t.Errorf("body = %+v, want %+v", resp, want)
+	}
+}
+
+// Без TRUSTED_PROXIES X-Forwarded-For не подменяет IP: смена заголовка не
+// обходит блокировку входа по адресу соединения.
+func TestLoginLockoutIgnoresForwardedFor(t *testing.T) {
+	r := testServer(t)
+	login := func(i int) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"email": "probe%d@example.test", "password": "wrong"}`, i)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	for i := 1; i <= 5; i++ {
+		if w := login(i); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: want 401, got %d %s", i, w.Code, w.Body.String())
+		}
+	}
+	if w := login(6); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("6th attempt from the same connection: want 429 with Retry-After, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// Один и тот же обработчик отвечает под /api/v1 и под /api; у старого префикса
+// ответ помечен заголовками Deprecation и Link.
+func TestAPIPrefixAlias(t *testing.T) {
+	r := testServer(t)
+	register := func(prefix string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, prefix+"/auth/register", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	v1, alias := register("/api/v1"), register("/api")
+	if v1.Code != http.StatusBadRequest || alias.Code != v1.Code {
+		t.Fatalf("want 400 on both prefixes, got /api/v1 %d, /api %d", v1.Code, alias.Code)
+	}
+	if v1.Body.String() != alias.Body.String() {
+		t.Errorf("bodies differ:\n/api/v1: %s\n/api:    %s", v1.Body.String(), alias.Body.String())
+	}
+	if v1.Header().Get("Deprecation") != "" {
+		t.Errorf("/api/v1 marked deprecated")
+	}
+	if alias.Header().Get("Deprecation") != "true" || !strings.Contains(alias.Header().Get("Link"), "</api/v1>") {
+		t.Errorf("/api alias headers: Deprecation %q, Link %q", alias.Header().Get("Deprecation"), alias.Header().Get("Link"))
+	}
+}
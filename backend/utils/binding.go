@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	validator "github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Ошибки валидации называют поле так же, как его видит клиент: по json-тегу.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// BindingFieldErrors converts a ShouldBindJSON error into per-field errors,
+// чтобы форма могла подсветить конкретные поля. Для ошибок, не относящихся к
+// полям (битый JSON), возвращает nil.
+func BindingFieldErrors(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fields = append(fields, FieldError{Field: fe.Field(), Rule: fe.Tag(), Message: validationMessage(fe)})
+		}
+		return fields
+	}
+	var fieldErr FieldError
+	if errors.As(err, &fieldErr) {
+		return []FieldError{fieldErr}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{Field: typeErr.Field, Rule: "type", Message: typeMessage(typeErr.Type.Kind())}}
+	}
+	return nil
+}
+
+// validationMessage формулирует нарушенное правило по-русски; единица
+// (символы, элементы) зависит от типа поля.
+func validationMessage(fe validator.FieldError) string {
+	least, most, exactly := "не меньше %s", "не больше %s", "ровно %s"
+	switch fe.Kind() {
+	case reflect.String:
+		least, most, exactly = "не короче %s символов", "не длиннее %s символов", "ровно %s символов"
+	case reflect.Slice, reflect.Map:
+		least, most, exactly = "не меньше %s элементов", "не больше %s элементов", "ровно %s элементов"
+	}
+	switch fe.Tag() {
+	case "required":
+		return "Обязательное поле"
+	case "min", "gte":
+		return "Должно быть " + fmt.Sprintf(least, fe.Param())
+	case "max", "lte":
+		return "Должно быть " + fmt.Sprintf(most, fe.Param())
+	case "len":
+		return "Должно быть " + fmt.Sprintf(exactly, fe.Param())
+	case "email":
+		return "Некорректный адрес email"
+	case "oneof":
+		return "Допустимые значения: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "url":
+		return "Некорректный URL"
+	default:
+		return "Некорректное значение"
+	}
+}
+
+// typeMessage — сообщение о значении не того JSON-типа.
+func typeMessage(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "Должно быть строкой"
+	case reflect.Bool:
+		return "Должно быть true или false"
+	case reflect.Slice, reflect.Array:
+		return "Должно быть массивом"
+	case reflect.Map, reflect.Struct:
+		return "Должно быть объектом"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Должно быть целым числом"
+	case reflect.Float32, reflect.Float64:
+		return "Должно быть числом"
+	default:
+		return "Некорректный тип значения"
+	}
+}
+
+// RespondBindingError отвечает 400 на ошибку ShouldBindJSON: со списком Fields,
+// если ошибка относится к полям, иначе — с коротким описанием, что не так с телом.
+// Внутренние строки validator ("Key: 'Request.Field' Error:...") клиенту не уходят.
+func RespondBindingError(c *gin.Context, err error) {
+	if fields := BindingFieldErrors(err); len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation Error",
+			Message: "Проверьте заполнение полей",
+			Code:    http.StatusBadRequest,
+			Fields:  fields,
+		})
+		return
+	}
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   "Bad Request",
+		Message: bindingMessage(err),
+		Code:    http.StatusBadRequest,
+	})
+}
+
+// bindingMessage описывает ошибку разбора тела, не относящуюся к полям.
+func bindingMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "Тело запроса пустое"
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &syntaxErr):
+		return "Тело запроса не является корректным JSON"
+	default:
+		return "Не удалось разобрать тело запроса"
+	}
+}
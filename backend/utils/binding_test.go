@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type bindingTestRequest struct {
+	Username string `json:"username" binding:"required,min=3"`
+	Email    string `json:"email" binding:"required,email"`
+	Rating   int    `json:"rating" binding:"max=10"`
+}
+
+func bindAndRespond(body string) (int, ErrorResponse) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/", func(c *gin.Context) {
+		var req bindingTestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondBindingError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var resp ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+func TestRespondBindingErrorListsFields(t *testing.T) {
+	code, resp := bindAndRespond(`{"username": "ab", "rating": 11}`)
+	if code != http.StatusBadRequest || resp.Error != "Validation Error" {
+		t.Fatalf("want 400 Validation Error, got %d %+v", code, resp)
+	}
+	want := []FieldError{
+		{Field: "username", Rule: "min", Message: "Должно быть не короче 3 символов"},
+		{Field: "email", Rule: "required", Message: "Обязательное поле"},
+		{Field: "rating", Rule: "max", Message: "Должно быть не больше 10"},
+	}
+	if len(resp.Fields) != len(want) {
+		t.Fatalf("fields = %+v, want %+v", resp.Fields, want)
+	}
+	for i := range want {
+		if resp.Fields[i] != want[i] {
+			t.Errorf("fields[%d] = %+v, want %+v", i, resp.Fields[i], want[i])
+		}
+	}
+}
+
+func TestRespondBindingErrorTypeAndSyntax(t *testing.T) {
+	_, resp := bindAndRespond(`{"username": "abc", "email": "a@b.test", "rating": "ten"}`)
+	if len(resp.Fields) != 1 || resp.Fields[0].Field != "rating" || resp.Fields[0].Rule != "type" {
+		t.Errorf("type error: fields = %+v", resp.Fields)
+	}
+
+	code, resp := bindAndRespond(`{"username":`)
+	if code != http.StatusBadRequest || resp.Error != "Bad Request" || len(resp.Fields) != 0 {
+		t.Errorf("broken JSON: got %d %+v", code, resp)
+	}
+	if strings.Contains(resp.Message, "EOF") {
+		t.Errorf("message leaks decoder error: %q", resp.Message)
+	}
+}
+
+// Отсутствующее обязательное поле называется по JSON-имени, а не по имени в Go.
+func TestBindingFieldErrorsNamesMissingRequiredField(t *testing.T) {
+	var req bindingTestRequest
+	err := binding.JSON.BindBody([]byte(`{"username": "abc"}`), &req)
+	fields := BindingFieldErrors(err)
+	if len(fields) != 1 || fields[0].Field != "email" || fields[0].Rule != "required" {
+		t.Errorf("fields = %+v, want only email/required", fields)
+	}
+}
+
+// Ошибка поля из проверки в обработчике уходит тем же списком Fields.
+func TestBindingFieldErrorsPassesFieldError(t *testing.T) {
+	for rating, rule := range map[int]string{0: "min", 11: "max"} {
+		fields := BindingFieldErrors(AtmosphereRatingFieldError(rating))
+		if len(fields) != 1 || fields[0].Field != "atmosphere_rating" || fields[0].Rule != rule {
+			t.Errorf("rating %d: fields = %+v, want atmosphere_rating/%s", rating, fields, rule)
+		}
+	}
+	if err := AtmosphereRatingFieldError(10); err != nil {
+		t.Errorf("rating 10: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	cases := []struct {
+		createdAt time.Time
+		id        uint
+	}{
+		{time.Date(2026, 3, 14, 15, 9, 26, 535897000, moscow), 42},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2025, 12, 31, 23, 59, 59, 999999999, time.UTC), 4294967295},
+	}
+	for _, tc := range cases {
+		cursor := EncodeCursor(tc.createdAt, tc.id)
+		createdAt, id, err := DecodeCursor(cursor)
+		if err != nil {
+			t.Fatalf("DecodeCursor(%q): %v", cursor, err)
+		}
+		if !createdAt.Equal(tc.createdAt) || id != tc.id {
+			t.Errorf("round trip of (%v, %d): got (%v, %d)", tc.createdAt, tc.id, createdAt, id)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	for _, cursor := range []string{
+		"",
+		"not base64!",
+		encode("2026-03-14T12:00:00Z"),
+		encode("yesterday|5"),
+		encode("2026-03-14T12:00:00Z|-1"),
+		encode("2026-03-14T12:00:00Z|99999999999"),
+	} {
+		if _, _, err := DecodeCursor(cursor); err == nil {
+			t.Errorf("DecodeCursor(%q): want error", cursor)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // APIError represents an API error
@@ -33,11 +38,45 @@ var (
 	ErrValidation    = NewError("Validation error", http.StatusBadRequest)
 )
 
+// IsUniqueViolation reports whether err is a unique constraint violation
+// (Postgres SQLSTATE 23505), e.g. when two concurrent signups pass the pre-check.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "SQLSTATE 23505")
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
-	Code    int    `json:"code"`
+	Error   string       `json:"error"`
+	Message string       `json:"message,omitempty"`
+	Code    int          `json:"code"`
+	Fields  []FieldError `json:"fields,omitempty"` // Ошибки валидации по отдельным полям
+}
+
+// FieldError — ошибка одного поля запроса: имя поля как в JSON, нарушенное
+// правило (тег validator: required, min, email, ...) и сообщение для формы.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error позволяет вернуть ошибку одного поля из проверки в обработчике и
+// отдать её через RespondBindingError тем же списком Fields, что и ошибки тегов.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// RespondError aborts the request with the standard ErrorResponse envelope;
+// Error берётся из стандартного текста HTTP-статуса.
+func RespondError(c *gin.Context, code int, message string) {
+	c.AbortWithStatusJSON(code, ErrorResponse{
+		Error:   http.StatusText(code),
+		Message: message,
+		Code:    code,
+	})
 }
 
 // HandleError handles errors and returns appropriate HTTP response
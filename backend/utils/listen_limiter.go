@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// ListenLimiter пропускает не больше одного события на ключ за interval.
+// Как и LoginLimiter, хранит состояние in-memory в пределах одного инстанса.
+type ListenLimiter struct {
+	mu          sync.Mutex
+	seen        map[string]time.Time
+	interval    time.Duration
+	lastCleanup time.Time
+}
+
+// NewListenLimiter creates a limiter that accepts one event per key per interval.
+func NewListenLimiter(interval time.Duration) *ListenLimiter {
+	return &ListenLimiter{
+		seen:     make(map[string]time.Time),
+		interval: interval,
+	}
+}
+
+// Allow reports whether an event for key should be counted and remembers it if so.
+func (l *ListenLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastCleanup) > l.interval {
+		for k, at := range l.seen {
+			if now.Sub(at) >= l.interval {
+				delete(l.seen, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	if at, ok := l.seen[key]; ok && now.Sub(at) < l.interval {
+		return false
+	}
+	l.seen[key] = now
+	return true
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"log/slog"
+	"os"
+)
+
+// NewLogger builds the process logger: format json|text и level
+// debug|info|warn|error из config (LOG_FORMAT, LOG_LEVEL). После slog.SetDefault
+// сюда же попадают и оставшиеся вызовы пакета log.
+func NewLogger(format, level string) *slog.Logger {
+	logLevel := slog.LevelInfo
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	}
+	options := &slog.HandlerOptions{Level: logLevel}
+
+	if format == "text" {
+		return slog.New(slog.NewTextHandler(os.Stdout, options))
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, options))
+}
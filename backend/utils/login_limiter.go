@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// loginCleanupInterval — как часто Fail вычищает устаревшие счётчики: полный
+// проход по карте на каждой неудаче при переборе был бы O(n) на попытку.
+const loginCleanupInterval = time.Minute
+
+// LoginLimiter считает неудачные попытки входа по ключам (email, IP) и после
+// порога блокирует ключ на время lockout. Хранилище in-memory: для одного
+// инстанса backend этого достаточно, после рестарта счётчики обнуляются.
+type LoginLimiter struct {
+	mu          sync.Mutex
+	attempts    map[string]*loginAttempt
+	maxAttempts int
+	lockout     time.Duration
+	lastCleanup time.Time
+}
+
+type loginAttempt struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// NewLoginLimiter creates a limiter that locks a key for lockout after maxAttempts failures.
+func NewLoginLimiter(maxAttempts int, lockout time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		attempts:    make(map[string]*loginAttempt),
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+		lastCleanup: time.Now(),
+	}
+}
+
+// RetryAfter returns how long the longest-locked key stays locked, or 0 if none is locked.
+func (l *LoginLimiter) RetryAfter(keys ...string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range keys {
+		attempt, ok := l.attempts[key]
+		if !ok {
+			continue
+		}
+		if wait := attempt.lockedUntil.Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	return retryAfter
+}
+
+// Fail registers a failed attempt for every key and locks keys that reached the threshold.
+func (l *LoginLimiter) Fail(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		attempt, ok := l.attempts[key]
+		// Старые неудачи (старше окна блокировки) не копятся бесконечно.
+		if !ok || now.Sub(attempt.lastFailure) > l.lockout {
+			attempt = &loginAttempt{}
+			l.attempts[key] = attempt
+		}
+		attempt.failures++
+		attempt.lastFailure = now
+		if attempt.failures >= l.maxAttempts {
+			attempt.lockedUntil = now.Add(l.lockout)
+			attempt.failures = 0
+		}
+	}
+	if now.Sub(l.lastCleanup) >= loginCleanupInterval {
+		l.cleanup(now)
+	}
+}
+
+// Reset clears counters for the given keys (after a successful login).
+func (l *LoginLimiter) Reset(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		delete(l.attempts, key)
+	}
+}
+
+// cleanup drops stale entries so the map does not grow with every probed email.
+func (l *LoginLimiter) cleanup(now time.Time) {
+	l.lastCleanup = now
+	for key, attempt := range l.attempts {
+		if now.After(attempt.lockedUntil) && now.Sub(attempt.lastFailure) > l.lockout {
+			delete(l.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLimiterLocksAfterMaxAttempts(t *testing.T) {
+	l := NewLoginLimiter(3, time.Minute)
+	for i := 0; i < 2; i++ {
+		l.Fail("email:a@example.test", "ip:10.0.0.1")
+	}
+	if wait := l.RetryAfter("email:a@example.test", "ip:10.0.0.1"); wait != 0 {
+		t.Fatalf("locked before threshold: %v", wait)
+	}
+
+	l.Fail("email:b@example.test", "ip:10.0.0.1")
+	if wait := l.RetryAfter("email:c@example.test", "ip:10.0.0.1"); wait <= 0 || wait > time.Minute {
+		t.Errorf("ip should be locked for up to a minute, got %v", wait)
+	}
+	if wait := l.RetryAfter("email:a@example.test"); wait != 0 {
+		t.Errorf("email with two failures should not be locked, got %v", wait)
+	}
+
+	l.Reset("email:b@example.test", "ip:10.0.0.1")
+	if wait := l.RetryAfter("email:b@example.test", "ip:10.0.0.1"); wait != 0 {
+		t.Errorf("reset keys still locked: %v", wait)
+	}
+}
+
+func TestLoginLimiterForgetsOldFailures(t *testing.T) {
+	l := NewLoginLimiter(2, 20*time.Millisecond)
+	l.Fail("ip:10.0.0.1")
+	time.Sleep(40 * time.Millisecond)
+	l.Fail("ip:10.0.0.1")
+	if wait := l.RetryAfter("ip:10.0.0.1"); wait != 0 {
+		t.Errorf("failure older than lockout window counted: locked for %v", wait)
+	}
+}
+
+// Устаревшие счётчики вычищаются не на каждой неудаче, а раз в loginCleanupInterval.
+func TestLoginLimiterCleanupIsAmortized(t *testing.T) {
+	l := NewLoginLimiter(5, time.Millisecond)
+	l.Fail("email:stale@example.test")
+	time.Sleep(5 * time.Millisecond)
+
+	l.Fail("email:fresh@example.test")
+	if _, ok := l.attempts["email:stale@example.test"]; !ok {
+		t.Fatal("cleanup ran before loginCleanupInterval elapsed")
+	}
+
+	l.lastCleanup = time.Now().Add(-loginCleanupInterval)
+	l.Fail("email:fresh@example.test")
+	if _, ok := l.attempts["email:stale@example.test"]; ok {
+		t.Error("stale entry kept after cleanup interval")
+	}
+	if _, ok := l.attempts["email:fresh@example.test"]; !ok {
+		t.Error("fresh entry dropped by cleanup")
+	}
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// Mailer отправляет письма пользователям: SMTPMailer в окружениях с почтой,
+// LogMailer в dev, где токен подтверждения отдаётся в ответе.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer returns an SMTPMailer for host:port, or nil when host is empty —
+// почта не настроена, и письма отправить нельзя.
+func NewMailer(host string, port int, username, password, from string) Mailer {
+	if host == "" {
+		return nil
+	}
+	return SMTPMailer{
+		Addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+// SMTPMailer sends plain-text UTF-8 mail through an SMTP server. STARTTLS
+// net/smtp включает сам, если сервер его предлагает.
+type SMTPMailer struct {
+	Addr     string // host:port
+	Username string // пусто — без авторизации
+	Password string
+	From     string
+}
+
+// Send delivers one message to a single recipient.
+func (m SMTPMailer) Send(to, subject, body string) error {
+	// Перевод строки в адресе дописал бы в письмо чужие заголовки.
+	if strings.ContainsAny(to+m.From, "\r\n") {
+		return errors.New("mail address contains a line break")
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, _ := net.SplitHostPort(m.Addr)
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + m.From + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		msg.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	msg.WriteString(encoded + "\r\n")
+
+	return smtp.SendMail(m.Addr, auth, m.From, []string{to}, []byte(msg.String()))
+}
+
+// LogMailer пишет в лог факт отправки вместо самого письма (dev и окружения
+// без почты). Тело не логируется: в нём одноразовые ссылки с токенами.
+type LogMailer struct{}
+
+// Send logs the recipient and subject instead of delivering the message.
+func (LogMailer) Send(to, subject, body string) error {
+	slog.Info("mail (not delivered)", "to", to, "subject", subject, "body_bytes", len(body))
+	return nil
+}
+
+// NewConfirmToken returns a random URL-safe token and its SHA-256 hash.
+// В БД хранится только хеш: утечка таблицы не даёт подтвердить чужой адрес.
+func NewConfirmToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(buf)
+	return token, HashConfirmToken(token), nil
+}
+
+// HashConfirmToken hashes a confirmation token for storage and lookup.
+func HashConfirmToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// fakeSMTP принимает одно письмо без TLS и авторизации и отдаёт его DATA в канал.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	data := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.Fields(line + " ")[0]); command {
+			case "EHLO", "HELO":
+				text.PrintfLine("250 fake")
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				body, _ := text.ReadDotBytes()
+				data <- string(body)
+				text.PrintfLine("250 queued")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				return
+			default:
+				text.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return listener.Addr().String(), data
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	addr, data := fakeSMTP(t)
+	mailer := SMTPMailer{Addr: addr, From: "noreply@example.test"}
+	body := "Ссылка: https://example.test/api/users/confirm-email?token=abc"
+	if err := mailer.Send("user@example.test", "Подтверждение email", body); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	message := <-data
+	headers, encoded, found := strings.Cut(message, "\n\n")
+	if !found {
+		t.Fatalf("no header/body separator in %q", message)
+	}
+	for _, want := range []string{"From: noreply@example.test", "To: user@example.test", "Subject: =?utf-8?b?", "charset=utf-8"} {
+		if !strings.Contains(headers, want) {
+			t.Errorf("headers miss %q:\n%s", want, headers)
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(encoded, "\n", ""))
+	if err != nil || string(decoded) != body {
+		t.Errorf("body = %q (%v), want %q", decoded, err, body)
+	}
+}
+
+func TestSMTPMailerRejectsHeaderInjection(t *testing.T) {
+	mailer := SMTPMailer{Addr: "127.0.0.1:1", From: "noreply@example.test"}
+	if err := mailer.Send("user@example.test\r\nBcc: victim@example.test", "s", "b"); err == nil {
+		t.Error("address with a line break accepted")
+	}
+}
+
+func TestNewMailerWithoutHost(t *testing.T) {
+	if mailer := NewMailer("", 587, "", "", "noreply@example.test"); mailer != nil {
+		t.Errorf("NewMailer without host = %#v, want nil", mailer)
+	}
+}
+
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultPageSize — page_size, если клиент его не передал или передал мусор.
+const DefaultPageSize = 20
+
+// DefaultMaxPageSize ограничивает page_size, если движок не подключил
+// PageSizeLimit. Без ограничения page_size=100000 выгружал бы таблицу целиком.
+const DefaultMaxPageSize = 100
+
+// maxPageSizeKey — ключ контекста, под которым PageSizeLimit кладёт предел.
+const maxPageSizeKey = "max_page_size"
+
+// PageSizeLimit sets the page_size ceiling for ParsePage in this engine;
+// routes подключает его с PAGE_SIZE_MAX из конфигурации.
+func PageSizeLimit(max int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(maxPageSizeKey, max)
+		c.Next()
+	}
+}
+
+// Page is the requested page: ?page= (с 1) и ?page_size=.
+type Page struct {
+	Number int
+	Size   int
+}
+
+// ParsePage reads page and page_size. Нечисловой или меньше 1 page — первая
+// страница; такой же page_size — DefaultPageSize; page_size больше предела из
+// PageSizeLimit (DefaultMaxPageSize без него) урезается до предела.
+func ParsePage(c *gin.Context) Page {
+	number, err := strconv.Atoi(c.Query("page"))
+	if err != nil || number < 1 {
+		number = 1
+	}
+	size, err := strconv.Atoi(c.Query("page_size"))
+	if err != nil || size < 1 {
+		size = DefaultPageSize
+	}
+	maxSize := c.GetInt(maxPageSizeKey)
+	if maxSize <= 0 {
+		maxSize = DefaultMaxPageSize
+	}
+	if size > maxSize {
+		size = maxSize
+	}
+	return Page{Number: number, Size: size}
+}
+
+// Offset is the number of rows to skip.
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.Size
+}
+
+// Paginated is the common list envelope: элементы под ключом Key (reviews,
+// albums, ...) и total, page, page_size, total_pages, has_next, has_prev.
+type Paginated[T any] struct {
+	Key   string
+	Items []T
+	Total int64
+	Page  Page
+}
+
+// NewPaginated builds the envelope for one page of items.
+func NewPaginated[T any](key string, items []T, total int64, page Page) Paginated[T] {
+	return Paginated[T]{Key: key, Items: items, Total: total, Page: page}
+}
+
+// NewList builds the envelope for lists without page parameters (все жанры,
+// популярное с limit): все элементы на первой странице, page_size — limit.
+func NewList[T any](key string, items []T, limit int) Paginated[T] {
+	return NewPaginated(key, items, int64(len(items)), Page{Number: 1, Size: limit})
+}
+
+// Fields returns the envelope as gin.H — для ответов, где к странице добавляются
+// свои поля (жанр у топа жанра, позиция пользователя у лидерборда).
+func (p Paginated[T]) Fields() gin.H {
+	items := p.Items
+	if items == nil {
+		items = []T{}
+	}
+	totalPages := 0
+	if p.Page.Size > 0 {
+		totalPages = int((p.Total + int64(p.Page.Size) - 1) / int64(p.Page.Size))
+	}
+	return gin.H{
+		p.Key:         items,
+		"total":       p.Total,
+		"page":        p.Page.Number,
+		"page_size":   p.Page.Size,
+		"total_pages": totalPages,
+		"has_next":    p.Page.Number < totalPages,
+		"has_prev":    p.Page.Number > 1,
+	}
+}
+
+// MarshalJSON writes the envelope produced by Fields.
+func (p Paginated[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Fields())
+}
@@ -7,8 +7,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -18,26 +16,23 @@ type SessionClaims struct {
 	Exp    int64 `json:"exp"`
 }
 
-func sessionSecret() []byte {
-	secret := strings.TrimSpace(os.Getenv("SESSION_SECRET"))
-	if secret == "" {
-		secret = "dev-only-session-secret"
-	}
-	return []byte(secret)
+// Sessions подписывает и проверяет токены сессий: секрет и срок жизни
+// приходят из конфигурации (SESSION_SECRET, SESSION_TTL_HOURS).
+type Sessions struct {
+	secret []byte
+	ttl    time.Duration
 }
 
-func SessionTTL() time.Duration {
-	hours, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SESSION_TTL_HOURS")))
-	if err != nil || hours <= 0 {
-		hours = 168
-	}
-	return time.Duration(hours) * time.Hour
+// NewSessions returns a signer with the given secret and token lifetime.
+func NewSessions(secret string, ttl time.Duration) *Sessions {
+	return &Sessions{secret: []byte(secret), ttl: ttl}
 }
 
-func GenerateSessionToken(userID uint) (string, error) {
+// Generate issues a signed token for the user that expires after the TTL.
+func (s *Sessions) Generate(userID uint) (string, error) {
 	claims := SessionClaims{
 		UserID: userID,
-		Exp:    time.Now().Add(SessionTTL()).Unix(),
+		Exp:    time.Now().Add(s.ttl).Unix(),
 	}
 	payload, err := json.Marshal(claims)
 	if err != nil {
@@ -45,18 +40,19 @@ func GenerateSessionToken(userID uint) (string, error) {
 	}
 
 	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
-	signature := signPayload(encodedPayload)
+	signature := s.sign(encodedPayload)
 	return fmt.Sprintf("%s.%s", encodedPayload, signature), nil
 }
 
-func ValidateSessionToken(token string) (uint, error) {
+// Validate checks the signature and expiry and returns the token's user ID.
+func (s *Sessions) Validate(token string) (uint, error) {
 	token = strings.TrimSpace(token)
 	parts := strings.Split(token, ".")
 	if len(parts) != 2 {
 		return 0, errors.New("invalid session token format")
 	}
 
-	expectedSignature := signPayload(parts[0])
+	expectedSignature := s.sign(parts[0])
 	if !hmac.Equal([]byte(expectedSignature), []byte(parts[1])) {
 		return 0, errors.New("invalid session token signature")
 	}
@@ -80,8 +76,8 @@ func ValidateSessionToken(token string) (uint, error) {
 	return claims.UserID, nil
 }
 
-func signPayload(payload string) string {
-	mac := hmac.New(sha256.New, sessionSecret())
+func (s *Sessions) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
 	mac.Write([]byte(payload))
 	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// socialLinkMaxLength ограничивает длину одной ссылки в профиле.
+const socialLinkMaxLength = 200
+
+// socialLinkBases — разрешённые ключи social_links и куда разворачивается
+// голый ник (@username). У site ника нет: там ожидается адрес сайта.
+var socialLinkBases = map[string]string{
+	"vk":        "https://vk.com/",
+	"telegram":  "https://t.me/",
+	"instagram": "https://instagram.com/",
+	"youtube":   "https://youtube.com/@",
+	"max":       "https://max.ru/",
+	"site":      "",
+}
+
+var (
+	socialHandleRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,64}$`)
+	siteDomainRegex   = regexp.MustCompile(`^[A-Za-z0-9\-]+(\.[A-Za-z0-9\-]+)+(/\S*)?$`)
+)
+
+// NormalizeSocialLinks проверяет ссылки профиля и приводит их к полным
+// http(s)-URL. Пустые значения отбрасываются (так ссылка удаляется). Вторым
+// значением возвращаются ошибки по полям (social_links.<ключ>) в порядке ключей;
+// если он не пустой, links не годятся.
+func NormalizeSocialLinks(links map[string]string) (map[string]string, []FieldError) {
+	normalized := make(map[string]string, len(links))
+	var fieldErrors []FieldError
+	fail := func(key, rule, message string) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "social_links." + key, Rule: rule, Message: message})
+	}
+
+	for key, raw := range links {
+		base, allowed := socialLinkBases[key]
+		if !allowed {
+			fail(key, "oneof", "Такой соцсети нет в профиле")
+			continue
+		}
+		value := strings.TrimSpace(raw)
+		if value == "" {
+			continue
+		}
+		if len(value) > socialLinkMaxLength {
+			fail(key, "max", fmt.Sprintf("Должно быть не длиннее %d символов", socialLinkMaxLength))
+			continue
+		}
+
+		lower := strings.ToLower(value)
+		switch {
+		case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://"):
+			parsed, err := url.Parse(value)
+			if err != nil || parsed.Host == "" {
+				fail(key, "url", "Некорректная http(s)-ссылка")
+				continue
+			}
+			normalized[key] = parsed.String()
+		case strings.Contains(value, ":"):
+			// javascript:, data: и прочие схемы — только http(s).
+			fail(key, "url", "Допустимы только http(s)-ссылки")
+		case key == "site":
+			if !siteDomainRegex.MatchString(value) {
+				fail(key, "url", "Должно быть адресом сайта")
+				continue
+			}
+			normalized[key] = "https://" + value
+		default:
+			handle := strings.TrimPrefix(value, "@")
+			if !socialHandleRegex.MatchString(handle) {
+				fail(key, "url", "Должно быть http(s)-ссылкой или ником")
+				continue
+			}
+			normalized[key] = base + handle
+		}
+	}
+
+	sort.Slice(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+	return normalized, fieldErrors
+}
+
+// ParseSocialLinks разбирает jsonb-колонку social_links в объект для ответа API.
+func ParseSocialLinks(raw string) map[string]string {
+	links := map[string]string{}
+	if strings.TrimSpace(raw) == "" {
+		return links
+	}
+	if err := json.Unmarshal([]byte(raw), &links); err != nil {
+		return map[string]string{}
+	}
+	return links
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSocialLinksValid(t *testing.T) {
+	links, fieldErrors := NormalizeSocialLinks(map[string]string{
+		"vk":       "https://vk.com/durov",
+		"telegram": "@music_review",
+		"youtube":  " ",
+		"site":     "example.org/about",
+	})
+	if len(fieldErrors) != 0 {
+		t.Fatalf("unexpected errors: %+v", fieldErrors)
+	}
+	want := map[string]string{
+		"vk":       "https://vk.com/durov",
+		"telegram": "https://t.me/music_review",
+		"site":     "https://example.org/about",
+	}
+	if !reflect.DeepEqual(links, want) {
+		t.Errorf("links = %v, want %v", links, want)
+	}
+}
+
+func TestNormalizeSocialLinksRejects(t *testing.T) {
+	cases := map[string]struct {
+		links map[string]string
+		field string
+		rule  string
+	}{
+		"javascript scheme": {map[string]string{"vk": "javascript:alert(1)"}, "social_links.vk", "url"},
+		"url without host":  {map[string]string{"instagram": "https://"}, "social_links.instagram", "url"},
+		"bad handle":        {map[string]string{"telegram": "not a handle"}, "social_links.telegram", "url"},
+		"unknown key":       {map[string]string{"myspace": "https://myspace.com/x"}, "social_links.myspace", "oneof"},
+	}
+	for name, tc := range cases {
+		_, fieldErrors := NormalizeSocialLinks(tc.links)
+		if len(fieldErrors) != 1 || fieldErrors[0].Field != tc.field || fieldErrors[0].Rule != tc.rule {
+			t.Errorf("%s: want %s/%s, got %+v", name, tc.field, tc.rule, fieldErrors)
+		}
+	}
+}
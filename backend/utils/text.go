@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TruncateRunes обрезает строку до max символов (рун, а не байт — иначе
+// кириллица режется посреди символа) и добавляет многоточие, если текст длиннее.
+func TruncateRunes(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimRightFunc(string(runes[:max]), isSpace) + "…"
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\n' || r == '\t' || r == '\r'
+}
+
+// ExcerptAround cuts up to radius runes of context around the first
+// case-insensitive occurrence of query and returns it split into the text
+// before the match, the match itself and the text after it — клиент сам
+// решает, как подсветить совпадение, не разбирая HTML из пользовательского текста.
+// Если совпадения нет, весь фрагмент отдаётся в before, ok == false.
+func ExcerptAround(s, query string, radius int) (before, match, after string, ok bool) {
+	runes := []rune(s)
+	needle := []rune(query)
+	idx := indexRunesFold(runes, needle)
+	if idx < 0 || len(needle) == 0 {
+		return TruncateRunes(s, 2*radius), "", "", false
+	}
+
+	start := idx - radius
+	if start < 0 {
+		start = 0
+	}
+	end := idx + len(needle) + radius
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	before = string(runes[start:idx])
+	if start > 0 {
+		before = "…" + strings.TrimLeftFunc(before, isSpace)
+	}
+	after = string(runes[idx+len(needle) : end])
+	if end < len(runes) {
+		after = strings.TrimRightFunc(after, isSpace) + "…"
+	}
+	return before, string(runes[idx : idx+len(needle)]), after, true
+}
+
+// indexRunesFold finds needle in haystack ignoring case, comparing rune by rune
+// so the returned index is a rune offset into haystack.
+func indexRunesFold(haystack, needle []rune) int {
+	if len(needle) == 0 || len(needle) > len(haystack) {
+		return -1
+	}
+outer:
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		for j, r := range needle {
+			if unicode.ToLower(haystack[i+j]) != unicode.ToLower(r) {
+				continue outer
+			}
+		}
+		return i
+	}
+	return -1
+}
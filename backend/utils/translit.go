@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// cyrToLat — транслитерация кириллицы близко к ГОСТ 7.79-2000 (система Б)
+// в упрощённом виде, без диакритики: так пишут названия латиницей сами артисты.
+var cyrToLat = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// latToCyr — обратное направление; диграфы перечислены от длинных к коротким,
+// чтобы "shch" не разобралось как "sh" + "ch".
+var latToCyr = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yo", "ё"}, {"yu", "ю"}, {"ya", "я"}, {"ye", "е"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"},
+	{"g", "г"}, {"h", "х"}, {"i", "и"}, {"j", "й"}, {"k", "к"}, {"l", "л"},
+	{"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"},
+	{"s", "с"}, {"t", "т"}, {"u", "у"}, {"v", "в"}, {"w", "в"}, {"x", "кс"},
+	{"y", "ы"}, {"z", "з"},
+}
+
+// CyrillicToLatin transliterates Cyrillic letters to lowercase Latin; other
+// characters are kept as is (lowercased).
+func CyrillicToLatin(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if latin, ok := cyrToLat[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// LatinToCyrillic transliterates Latin letters to lowercase Cyrillic, matching
+// digraphs (zh, shch, …) first; other characters are kept as is (lowercased).
+func LatinToCyrillic(s string) string {
+	lower := strings.ToLower(s)
+	var b strings.Builder
+	for i := 0; i < len(lower); {
+		matched := false
+		for _, pair := range latToCyr {
+			if strings.HasPrefix(lower[i:], pair.latin) {
+				b.WriteString(pair.cyrillic)
+				i += len(pair.latin)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			r, size := utf8.DecodeRuneInString(lower[i:])
+			b.WriteRune(r)
+			i += size
+		}
+	}
+	return b.String()
+}
+
+// SearchVariants returns the query plus its transliteration into the other
+// alphabet ("basta" → "баста", "Зиверт" → "zivert"), without duplicates.
+// Смешанные строки не транслитерируются: у них нет однозначного «другого» вида.
+func SearchVariants(query string) []string {
+	variants := []string{query}
+	hasCyrillic, hasLatin := false, false
+	for _, r := range query {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			hasCyrillic = true
+		case r < 0x80 && unicode.IsLetter(r):
+			hasLatin = true
+		}
+	}
+	var translit string
+	switch {
+	case hasCyrillic && !hasLatin:
+		translit = CyrillicToLatin(query)
+	case hasLatin && !hasCyrillic:
+		translit = LatinToCyrillic(query)
+	}
+	if translit != "" && !strings.EqualFold(translit, query) {
+		variants = append(variants, translit)
+	}
+	return variants
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCyrillicToLatin(t *testing.T) {
+	cases := map[string]string{
+		"Баста":   "basta",
+		"Зиверт":  "zivert",
+		"Жуки":    "zhuki",
+		"Щедрин":  "shchedrin",
+		"Хаски":   "khaski",
+		"Цой":     "tsoy",
+		"Чайф":    "chayf",
+		"Ёлка":    "yolka",
+		"Юля и Я": "yulya i ya",
+		"Подъезд": "podezd",
+		"Би-2":    "bi-2",
+	}
+	for in, want := range cases {
+		if got := CyrillicToLatin(in); got != want {
+			t.Errorf("CyrillicToLatin(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLatinToCyrillic(t *testing.T) {
+	cases := map[string]string{
+		"basta":     "баста",
+		"Zivert":    "зиверт",
+		"zhuki":     "жуки",
+		"shchedrin": "щедрин",
+		"schedrin":  "щедрин",
+		"khaski":    "хаски",
+		"chaif":     "чаиф",
+		"yolka":     "ёлка",
+		"yulya":     "юля",
+		"Max Korzh": "макс корж",
+		"tsvet":     "цвет",
+	}
+	for in, want := range cases {
+		if got := LatinToCyrillic(in); got != want {
+			t.Errorf("LatinToCyrillic(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchVariants(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"basta", []string{"basta", "баста"}},
+		{"Зиверт", []string{"Зиверт", "zivert"}},
+		{"ЖУКИ", []string{"ЖУКИ", "zhuki"}},
+		{"shchedrin", []string{"shchedrin", "щедрин"}},
+		// Смешанный запрос и запрос без букв не транслитерируются.
+		{"Би-2 basta", []string{"Би-2 basta"}},
+		{"2024", []string{"2024"}},
+	}
+	for _, tc := range cases {
+		if got := SearchVariants(tc.query); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("SearchVariants(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// UploadsURLPrefix — публичный префикс, под которым backend раздаёт загрузки.
+const UploadsURLPrefix = "/uploads/"
+
+// Подкаталоги UPLOADS_DIR для разных типов загрузок.
+const (
+	UploadsAvatarsDir = "avatars"
+	UploadsCoversDir  = "covers"
+)
+
+// UploadPublicPath builds the stored/public path of an uploaded file, e.g. /uploads/avatars/x.jpg.
+func UploadPublicPath(subdir, filename string) string {
+	return UploadsURLPrefix + subdir + "/" + filename
+}
+
+// ResolveUploadPath maps a stored public path (/uploads/...) to a file inside
+// uploadsDir (UPLOADS_DIR). Пути из БД считаются недоверенными: всё, что после
+// очистки выходит за пределы каталога (../, абсолютные пути), отклоняется.
+func ResolveUploadPath(uploadsDir, publicPath string) (string, bool) {
+	if !strings.HasPrefix(publicPath, UploadsURLPrefix) {
+		return "", false
+	}
+	rel := strings.TrimPrefix(publicPath, UploadsURLPrefix)
+	if rel == "" {
+		return "", false
+	}
+
+	root, err := filepath.Abs(uploadsDir)
+	if err != nil {
+		return "", false
+	}
+	resolved, err := filepath.Abs(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", false
+	}
+	within, err := filepath.Rel(root, resolved)
+	if err != nil || within == "." || within == ".." || strings.HasPrefix(within, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return resolved, true
+}
+
+// ResolveUserAvatarPath is ResolveUploadPath restricted to the user's own
+// uploaded avatars (avatars/user_<id>_*): только такие файлы можно удалять
+// при замене аватара или удалении пользователя, а не обложку или чужой аватар,
+// на которые указывает avatar_path.
+func ResolveUserAvatarPath(uploadsDir, publicPath string, userID uint) (string, bool) {
+	prefix := UploadPublicPath(UploadsAvatarsDir, fmt.Sprintf("user_%d_", userID))
+	if !strings.HasPrefix(publicPath, prefix) || strings.Contains(strings.TrimPrefix(publicPath, prefix), "/") {
+		return "", false
+	}
+	return ResolveUploadPath(uploadsDir, publicPath)
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveUserAvatarPath(t *testing.T) {
+	dir := t.TempDir()
+	own := UploadPublicPath(UploadsAvatarsDir, "user_7_1700000000.png")
+	path, ok := ResolveUserAvatarPath(dir, own, 7)
+	if !ok || path != filepath.Join(dir, UploadsAvatarsDir, "user_7_1700000000.png") {
+		t.Errorf("own avatar: got %q, %v", path, ok)
+	}
+
+	for _, foreign := range []string{
+		UploadPublicPath(UploadsCoversDir, "album_1.jpg"),
+		UploadPublicPath(UploadsAvatarsDir, "user_8_1700000000.png"),
+		UploadPublicPath(UploadsAvatarsDir, "user_77_1700000000.png"),
+		UploadPublicPath(UploadsAvatarsDir, "user_7_/../../covers/album_1.jpg"),
+		"/uploads/avatars/../covers/album_1.jpg",
+		"/etc/passwd",
+		"",
+	} {
+		if path, ok := ResolveUserAvatarPath(dir, foreign, 7); ok {
+			t.Errorf("%q must not resolve for user 7, got %q", foreign, path)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"music-review-site/backend/models"
 	"regexp"
+	"strings"
 )
 
 // ValidateEmail validates email format
@@ -12,6 +13,12 @@ func ValidateEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// NormalizeEmail приводит email к виду, в котором он хранится и ищется: без
+// пробелов по краям и в нижнем регистре.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // ValidatePassword validates password strength
 func ValidatePassword(password string) error {
 	if len(password) < 6 {
@@ -51,17 +58,47 @@ func ValidateAtmosphereRating(rating int) error {
 	return nil
 }
 
-// ValidateAtmosphereMultiplier validates atmosphere multiplier (1.0000-1.6072)
+// AtmosphereRatingFieldError проверяет atmosphere_rating из запроса и
+// возвращает ошибку поля для RespondBindingError (nil — значение в шкале).
+func AtmosphereRatingFieldError(rating int) error {
+	if err := ValidateAtmosphereRating(rating); err == nil {
+		return nil
+	}
+	if rating < 1 {
+		return FieldError{Field: "atmosphere_rating", Rule: "min", Message: "Должно быть не меньше 1"}
+	}
+	return FieldError{Field: "atmosphere_rating", Rule: "max", Message: "Должно быть не больше 10"}
+}
+
+// ValidateAtmosphereMultiplier validates atmosphere multiplier against the
+// scoring config (1.0000-1.6072 by default)
 // This is kept for backward compatibility with stored data
-func ValidateAtmosphereMultiplier(multiplier float64) error {
-	if multiplier < 1.0000 || multiplier > 1.6072 {
-		return fmt.Errorf("atmosphere multiplier must be between 1.0000 and 1.6072")
+func ValidateAtmosphereMultiplier(multiplier float64, scoring models.ScoringConfig) error {
+	maxMultiplier := scoring.AtmosphereMax
+	if multiplier < 1.0000 || multiplier > maxMultiplier+1e-9 {
+		return fmt.Errorf("atmosphere multiplier must be between 1.0000 and %.4f", maxMultiplier)
+	}
+	return nil
+}
+
+// ValidateTrackDuration validates track duration in seconds (1-7200)
+func ValidateTrackDuration(duration int) error {
+	if duration < 1 || duration > 7200 {
+		return fmt.Errorf("duration must be between 1 and 7200 seconds")
+	}
+	return nil
+}
+
+// ValidateTrackNumber validates track position in an album (>= 1)
+func ValidateTrackNumber(trackNumber int) error {
+	if trackNumber < 1 {
+		return fmt.Errorf("track_number must be at least 1")
 	}
 	return nil
 }
 
 // ValidateReview validates review data
-func ValidateReview(review *models.Review) error {
+func ValidateReview(review *models.Review, scoring models.ScoringConfig) error {
 	// Either album_id or track_id must be set, but not both
 	if review.AlbumID == nil && review.TrackID == nil {
 		return fmt.Errorf("either album_id or track_id must be provided")
@@ -81,7 +118,7 @@ func ValidateReview(review *models.Review) error {
 	if err := ValidateRating(review.RatingIndividuality); err != nil {
 		return fmt.Errorf("rating_individuality: %w", err)
 	}
-	if err := ValidateAtmosphereMultiplier(review.AtmosphereMultiplier); err != nil {
+	if err := ValidateAtmosphereMultiplier(review.AtmosphereMultiplier, scoring); err != nil {
 		return fmt.Errorf("atmosphere_multiplier: %w", err)
 	}
 	return nil
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"music-review-site/backend/models"
+)
+
+func TestAtmosphereMultiplierPassesValidation(t *testing.T) {
+	// Формула по умолчанию (1.6072) и значения, при которых шаг (max-1)/9 не
+	// представим точно во float.
+	for _, atmosphereMax := range []float64{models.DefaultScoreAtmosphereMax, 1.5, 1.7, 2, 1.6073} {
+		scoring := models.ScoringConfig{BaseWeight: models.DefaultScoreBaseWeight, AtmosphereMax: atmosphereMax}
+		for rating := 1; rating <= 10; rating++ {
+			multiplier := scoring.AtmosphereMultiplier(rating)
+			if err := ValidateAtmosphereMultiplier(multiplier, scoring); err != nil {
+				t.Errorf("max %v, rating %d: multiplier %v rejected: %v", atmosphereMax, rating, multiplier, err)
+			}
+		}
+		if got := scoring.AtmosphereMultiplier(10); got != scoring.AtmosphereMax {
+			t.Errorf("max %v: AtmosphereMultiplier(10) = %v, want exactly %v", atmosphereMax, got, scoring.AtmosphereMax)
+		}
+	}
+}
+
+func TestValidateAtmosphereMultiplierBounds(t *testing.T) {
+	scoring := models.DefaultScoring()
+	for _, multiplier := range []float64{0.99, models.DefaultScoreAtmosphereMax + 0.001} {
+		if err := ValidateAtmosphereMultiplier(multiplier, scoring); err == nil {
+			t.Errorf("multiplier %v: want error", multiplier)
+		}
+	}
+}
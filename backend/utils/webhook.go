@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// webhookClient — короткий таймаут: внешний сервис не должен держать горутины.
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// PostWebhookAsync sends payload as a JSON POST to url in the background.
+// Ошибки только логируются: вызывающий запрос пользователя никогда не ждёт вебхук.
+func PostWebhookAsync(url string, payload interface{}) {
+	if url == "" {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("webhook: failed to encode payload", "error", err)
+		return
+	}
+
+	go func() {
+		resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			slog.Warn("webhook: request failed", "url", url, "error", err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			slog.Warn("webhook: unexpected status", "url", url, "status", resp.StatusCode)
+		}
+	}()
+}
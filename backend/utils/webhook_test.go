@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Медленный вебхук не задерживает вызывающего: POST уходит в фоне.
+func TestPostWebhookAsyncDoesNotBlock(t *testing.T) {
+	received := make(chan map[string]string, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	start := time.Now()
+	PostWebhookAsync(server.URL, map[string]string{"event": "review.approved"})
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("PostWebhookAsync blocked for %v", elapsed)
+	}
+
+	select {
+	case payload := <-received:
+		if payload["event"] != "review.approved" {
+			t.Errorf("payload = %v", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
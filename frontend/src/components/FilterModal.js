@@ -25,7 +25,7 @@ const FilterModal = ({ isOpen, onClose, onApplyFilters, currentFilters }) => {
     const fetchGenres = async () => {
       try {
         const response = await genresAPI.getAll();
-        setGenres(response.data);
+        setGenres(response.data.genres || []);
       } catch (error) {
         console.error('Error fetching genres:', error);
       } finally {
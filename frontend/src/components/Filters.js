@@ -10,7 +10,7 @@ const Filters = ({ onFilterChange, filters }) => {
     const fetchGenres = async () => {
       try {
         const response = await genresAPI.getAll();
-        setGenres(response.data);
+        setGenres(response.data.genres || []);
       } catch (error) {
         console.error('Error fetching genres:', error);
       } finally {
@@ -208,9 +208,11 @@ const SocialIcon = ({ type }) => {
 
 const getSocialHref = (type, value) => {
   if (!value) return '';
+  // Новые ссылки backend сохраняет уже полными URL; ники остались у старых профилей.
+  if (/^https?:\/\//i.test(value)) return value;
   if (type === 'telegram') return `https://t.me/${value.replace('@', '')}`;
-  if (type === 'max') return value.startsWith('http') ? value : `https://max.ru/${value.replace('@', '')}`;
-  return value;
+  if (type === 'max') return `https://max.ru/${value.replace('@', '')}`;
+  return '';
 };
 
 const toAlbumItem = (album) => ({
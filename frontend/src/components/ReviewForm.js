@@ -2,6 +2,7 @@ import React, { useState } from 'react';
 import { calculateFinalScore, formatScore, convertMultiplierToAtmosphere, convertAtmosphereToMultiplier } from '../utils/ratingCalculator';
 import { REVIEW_CRITERIA } from '../utils/ratingMeta';
 import './ReviewForm.css';
+import { apiErrorMessage } from '../utils/apiError';
 
 const criterionDescriptions = {
   rhymes:
@@ -164,11 +165,7 @@ const ReviewForm = ({ albumId, trackId, onSubmit, initialData, onCancel }) => {
       let errorMessage = 'Ошибка при сохранении рецензии';
       
       if (err.response?.data) {
-        if (err.response.data.message) {
-          errorMessage = err.response.data.message;
-        } else if (err.response.data.error) {
-          errorMessage = err.response.data.error;
-        }
+        errorMessage = apiErrorMessage(err, err.response.data.error || errorMessage);
       } else if (err.message) {
         errorMessage = err.message;
       }
@@ -1,5 +1,6 @@
 import React, { createContext, useState, useContext, useEffect } from 'react';
 import { authAPI } from '../services/api';
+import { apiErrorMessage } from '../utils/apiError';
 
 const AuthContext = createContext();
 
@@ -105,7 +106,7 @@ export const AuthProvider = ({ children }) => {
     } catch (error) {
       return {
         success: false,
-        error: error.response?.data?.message || 'Ошибка регистрации',
+        error: apiErrorMessage(error, 'Ошибка регистрации'),
       };
     }
   };
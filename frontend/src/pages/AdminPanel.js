@@ -113,7 +113,7 @@ const AdminPanel = () => {
   const fetchGenres = useCallback(async () => {
     try {
       const response = await genresAPI.getAll();
-      const nextGenres = response.data || [];
+      const nextGenres = response.data.genres || [];
       setGenres(nextGenres);
       setReleaseForm((form) => ({
         ...form,
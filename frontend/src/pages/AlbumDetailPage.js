@@ -41,7 +41,7 @@ const AlbumDetailPage = () => {
   const fetchTracks = useCallback(async () => {
     try {
       const response = await tracksAPI.getByAlbum(id);
-      setTracks(response.data || []);
+      setTracks(response.data.tracks ?? []);
     } catch (err) {
       console.error('Error fetching tracks:', err);
     }
@@ -79,7 +79,7 @@ const AlbumDetailPage = () => {
           setError('Ошибка загрузки альбома');
           console.error('Error fetching album:', albumRes.reason);
         }
-        setTracks(tracksRes.status === 'fulfilled' ? (tracksRes.value.data || []) : []);
+        setTracks(tracksRes.status === 'fulfilled' ? (tracksRes.value.data.tracks ?? []) : []);
         setReviews(reviewsRes.status === 'fulfilled' ? (reviewsRes.value.data.reviews ?? []) : []);
       } finally {
         if (!ignore) setLoading(false);
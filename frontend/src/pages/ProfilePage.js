@@ -6,6 +6,7 @@ import ReviewCard from '../components/ReviewCard';
 import ProfileEditForm from '../components/ProfileEditForm';
 import ProfileDashboard from '../components/ProfileDashboard';
 import './ProfilePage.css';
+import { apiErrorMessage } from '../utils/apiError';
 
 const ProfilePage = () => {
   const { user, isAuthenticated, updateUser } = useAuth();
@@ -76,7 +77,7 @@ const ProfilePage = () => {
       }
     } catch (err) {
       console.error('Error updating profile:', err);
-      throw new Error(err.response?.data?.message || 'Ошибка при обновлении профиля');
+      throw new Error(apiErrorMessage(err, 'Ошибка при обновлении профиля'));
     }
   };
 
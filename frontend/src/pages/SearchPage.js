@@ -39,7 +39,7 @@ const SearchPage = () => {
     const fetchGenres = async () => {
       try {
         const response = await genresAPI.getAll();
-        setGenres(response.data);
+        setGenres(response.data.genres || []);
       } catch (err) {
         console.error('Error fetching genres:', err);
       }
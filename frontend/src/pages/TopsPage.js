@@ -55,8 +55,8 @@ const TopsPage = () => {
       ];
       setHiddenGems(filledGems.slice(0, HIDDEN_GEMS));
 
-      setPopularTracks(Array.isArray(tracksRes.data) ? tracksRes.data : []);
-      setPopularReviews(Array.isArray(popularRevRes.data) ? popularRevRes.data : []);
+      setPopularTracks(Array.isArray(tracksRes.data?.tracks) ? tracksRes.data.tracks : []);
+      setPopularReviews(Array.isArray(popularRevRes.data?.reviews) ? popularRevRes.data.reviews : []);
 
       const reviews = Array.isArray(artistRes.data?.reviews) ? artistRes.data.reviews : [];
       setArtistPicks(reviews.slice(0, ARTIST_PICKS));
@@ -76,7 +76,7 @@ const TopsPage = () => {
     reviewsAPI
       .getPopular({ limit: POPULAR_REVIEWS })
       .then((res) => {
-        if (Array.isArray(res.data)) setPopularReviews(res.data);
+        if (Array.isArray(res.data?.reviews)) setPopularReviews(res.data.reviews);
       })
       .catch(() => {});
   }, []);
@@ -0,0 +1,11 @@
+/**
+ * Текст ошибки API для формы. Ошибки валидации приходят списком
+ * fields: [{field, rule, message}] — показываем их сообщения, иначе message.
+ */
+export function apiErrorMessage(error, fallback) {
+  const data = error?.response?.data;
+  if (Array.isArray(data?.fields) && data.fields.length > 0) {
+    return data.fields.map((item) => item.message).join('; ');
+  }
+  return data?.message || fallback;
+}
@@ -3,6 +3,11 @@
  * Формирует правильный URL для изображений из public папки
  */
 
+// Origin backend без /api; пустая строка — тот же origin (nginx проксирует /uploads/).
+const UPLOADS_ORIGIN = (process.env.REACT_APP_API_URL || '')
+  .replace(/\/$/, '')
+  .replace(/\/api$/, '');
+
 /**
  * Получает URL изображения обложки
  * @param {string} imagePath - Путь к изображению из базы данных (например, "/preview/1.jpg")
@@ -10,6 +15,12 @@
  */
 export const getImageUrl = (imagePath) => {
   if (!imagePath) return null;
+
+  // Загрузки пользователей (/uploads/...) раздаёт backend. В dev frontend и API
+  // на разных origin, поэтому берём origin из REACT_APP_API_URL.
+  if (imagePath.startsWith('/uploads/')) {
+    return `${UPLOADS_ORIGIN}${imagePath}`;
+  }
   
   // В Create React App файлы из public доступны по корневому пути
   // Путь должен начинаться с / и указывать на файл в public папке